@@ -0,0 +1,62 @@
+// personal access tokens for the JSON API
+//
+// the token is shown to the user once, only the hash of it is stored
+package accesstokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// tells access tokens apart from session keys, which are UUIDs
+const Prefix = "rmt_"
+
+type Scope string
+
+const (
+	ReadProfile   Scope = "read:profile"
+	ReadHouses    Scope = "read:houses"
+	WriteHouses   Scope = "write:houses"
+	ReadNotes     Scope = "read:notes"
+	WriteNotes    Scope = "write:notes"
+	ReadPayments  Scope = "read:payments"
+	WritePayments Scope = "write:payments"
+	ReadMessages  Scope = "read:messages"
+	WriteMessages Scope = "write:messages"
+)
+
+// all scopes in the order they are shown
+var Scopes = []Scope{
+	ReadProfile,
+	ReadHouses,
+	WriteHouses,
+	ReadNotes,
+	WriteNotes,
+	ReadPayments,
+	WritePayments,
+	ReadMessages,
+	WriteMessages,
+}
+
+func IsScope(s string) bool {
+	return slices.Contains(Scopes, Scope(s))
+}
+
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+// new token and the hash to store
+func New() (token, hash string) {
+	token = Prefix + rand.Text()
+	return token, Hash(token)
+}
+
+// tokens are random enough to not need a slow hash
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,136 @@
+// deletion of user accounts after the grace period
+package accounts
+
+import (
+	"context"
+	"roommates/db/dbqueries"
+	"roommates/events"
+	"roommates/images"
+	"roommates/logger"
+	"roommates/rdb"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var log = logger.AccountsLoggger
+
+// time the user has to change their mind
+const DeletionGracePeriod = 14 * 24 * time.Hour
+
+const (
+	deletionBatchSize = 100
+	deletionInterval  = time.Hour
+)
+
+// deletes accounts whose grace period has passed
+type DeletionRunner struct {
+	pool   *pgxpool.Pool
+	db     *dbqueries.Queries
+	rh     *rdb.RedisHandler
+	images *images.Store
+}
+
+func NewDeletionRunner(pool *pgxpool.Pool, rh *rdb.RedisHandler, store *images.Store) *DeletionRunner {
+	return &DeletionRunner{
+		pool:   pool,
+		db:     dbqueries.New(pool),
+		rh:     rh,
+		images: store,
+	}
+}
+
+// blocks until ctx is done
+func (r *DeletionRunner) Run(ctx context.Context) {
+	log.Info().Msg("account deletion started")
+	ticker := time.NewTicker(deletionInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := r.DeleteDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("error deleting accounts")
+		}
+		if deleted == deletionBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("account deletion stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deletes a single batch of accounts, returns the amount of deleted accounts
+func (r *DeletionRunner) DeleteDue(ctx context.Context) (int, error) {
+	users, err := r.db.SelectUsersDueForDeletion(ctx, deletionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, user := range users {
+		if err = r.Delete(ctx, user.ID); err != nil {
+			return i, err
+		}
+		log.Info().Str("user_id", user.ID.String()).Msg("account deleted")
+	}
+	return len(users), nil
+}
+
+// deletes the user, what the user made in houses is kept
+//
+// the foreign keys decide what happens to the rest: personal data (memberships, payment shares,
+// notifications, credentials) is deleted with the user, while notes, reminders, payments and messages
+// lose their author and are shown as made by a deleted user. the houses get an event explaining it,
+// houses the user made are handed over to another resident or deleted when nobody else lives there
+func (r *DeletionRunner) Delete(ctx context.Context, userID pgtype.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.db.WithTx(tx)
+
+	pictureKeys, err := qtx.DeleteUserSoleHouses(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	houses, err := qtx.UserHouses(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, house := range houses {
+		err = events.Record(ctx, qtx, events.Event{
+			Type:    events.MemberDeleted,
+			HouseID: house.ID,
+			ActorID: userID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = qtx.TransferUserHouses(ctx, userID); err != nil {
+		return err
+	}
+	avatarKey, err := qtx.DeleteUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	r.images.DeleteKey(avatarKey)
+	for _, key := range pictureKeys {
+		r.images.DeleteKey(key)
+	}
+
+	// sessions would expire on their own, but until then they would belong to nobody
+	return r.rh.DeleteUserSessions(ctx, userID)
+}
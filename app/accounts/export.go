@@ -0,0 +1,115 @@
+package accounts
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"roommates/db/dbqueries"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type exportProfile struct {
+	dbqueries.ExportUserProfileRow
+	// stored as JSONB, would be base64 otherwise
+	ContactInformation json.RawMessage `json:"contact_information,omitempty"`
+}
+
+type exportHouse struct {
+	ID      pgtype.UUID `json:"id"`
+	Name    string      `json:"name"`
+	IsMaker bool        `json:"is_maker"`
+}
+
+type exportPayment struct {
+	ID     pgtype.UUID `json:"id"`
+	Name   string      `json:"name"`
+	Amount string      `json:"amount"`
+	// null when the payment is in no house
+	HouseName   *string `json:"house_name"`
+	IsRequester bool    `json:"is_requester"`
+	// status of the share of the user, empty when the user is not a payer
+	Status string `json:"status,omitempty"`
+}
+
+// writes the personal data of the user as a ZIP of JSON files
+func Export(ctx context.Context, db *dbqueries.Queries, userID pgtype.UUID, w io.Writer) error {
+	profile, err := db.ExportUserProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	houses, err := db.UserHouses(ctx, userID)
+	if err != nil {
+		return err
+	}
+	notes, err := db.ExportUserNotes(ctx, userID)
+	if err != nil {
+		return err
+	}
+	messages, err := db.ExportUserMessages(ctx, userID)
+	if err != nil {
+		return err
+	}
+	payments, err := db.SelectUserPayments(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	files := map[string]any{
+		"profile.json":  exportProfile{ExportUserProfileRow: profile, ContactInformation: profile.ContactInformation},
+		"houses.json":   exportHouses(userID, houses),
+		"notes.json":    notes,
+		"messages.json": messages,
+		"payments.json": exportPayments(userID, payments),
+	}
+
+	archive := zip.NewWriter(w)
+	now := time.Now()
+	for _, name := range []string{"profile.json", "houses.json", "notes.json", "messages.json", "payments.json"} {
+		f, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(files[name]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func exportHouses(userID pgtype.UUID, houses []dbqueries.UserHousesRow) []exportHouse {
+	list := make([]exportHouse, len(houses))
+	for i, house := range houses {
+		list[i] = exportHouse{
+			ID:      house.ID,
+			Name:    house.Name,
+			IsMaker: house.MakerID == userID,
+		}
+	}
+	return list
+}
+
+func exportPayments(userID pgtype.UUID, payments []dbqueries.SelectUserPaymentsRow) []exportPayment {
+	list := make([]exportPayment, len(payments))
+	for i, payment := range payments {
+		list[i] = exportPayment{
+			ID:          payment.ID,
+			Name:        payment.PaymentName,
+			Amount:      payment.Amount,
+			HouseName:   payment.HouseName,
+			IsRequester: payment.RequesterID == userID,
+		}
+		if payment.PaymentStatus.Valid {
+			list[i].Status = string(payment.PaymentStatus.HousePaymentStatus)
+		}
+	}
+	return list
+}
@@ -0,0 +1,114 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type KafkaBroker struct {
+	addresses []string
+
+	mu sync.Mutex
+	// writers are bound to a single topic, created when first needed
+	writers map[string]*kafka.Writer
+}
+
+func NewKafka(addresses []string) *KafkaBroker {
+	return &KafkaBroker{
+		addresses: addresses,
+		writers:   map[string]*kafka.Writer{},
+	}
+}
+
+func (b *KafkaBroker) Publish(ctx context.Context, messages ...Message) error {
+	byTopic := map[string][]kafka.Message{}
+	for _, message := range messages {
+		byTopic[message.Topic] = append(byTopic[message.Topic], kafka.Message{
+			Key:   message.Key,
+			Value: message.Value,
+		})
+	}
+
+	for topic, kafkaMessages := range byTopic {
+		if err := b.writer(topic).WriteMessages(ctx, kafkaMessages...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *KafkaBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var errs []error
+	for _, writer := range b.writers {
+		errs = append(errs, writer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (b *KafkaBroker) writer(topic string) *kafka.Writer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if writer, ok := b.writers[topic]; ok {
+		return writer
+	}
+	writer := kafka.NewWriter(kafka.WriterConfig{
+		Brokers:  b.addresses,
+		Topic:    topic,
+		Balancer: &kafka.Hash{},
+		// wait for all in-sync replicas, outbox rows are deleted after this returns
+		RequiredAcks: -1,
+		ErrorLogger:  kafka.LoggerFunc(log.Printf),
+	})
+	b.writers[topic] = writer
+	return writer
+}
+
+func (b *KafkaBroker) Subscribe(topic, group string) Subscription {
+	return &kafkaSubscription{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: b.addresses,
+			GroupID: group,
+			Topic:   topic,
+			// new groups start from the beginning, nothing published should be skipped
+			StartOffset: kafka.FirstOffset,
+			ErrorLogger: kafka.LoggerFunc(log.Printf),
+		}),
+	}
+}
+
+type kafkaSubscription struct {
+	reader *kafka.Reader
+}
+
+func (s *kafkaSubscription) Fetch(ctx context.Context) (Message, error) {
+	message, err := s.reader.FetchMessage(ctx)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Topic:     message.Topic,
+		Key:       message.Key,
+		Value:     message.Value,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+	}, nil
+}
+
+func (s *kafkaSubscription) Commit(ctx context.Context, message Message) error {
+	return s.reader.CommitMessages(ctx, kafka.Message{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+	})
+}
+
+func (s *kafkaSubscription) Close() error {
+	return s.reader.Close()
+}
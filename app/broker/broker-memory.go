@@ -0,0 +1,211 @@
+package broker
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"sync"
+)
+
+// in-memory broker, meant for development and tests
+//
+// messages are dropped once every group subscribed to the topic has committed them,
+// topics nobody has subscribed to (dead letters) keep everything
+type MemoryBroker struct {
+	mu         sync.Mutex
+	partitions int
+	topics     map[string]*memoryTopic
+	// closed and replaced on every publish, wakes up waiting subscriptions
+	published chan struct{}
+}
+
+type memoryTopic struct {
+	// messages that are still kept per partition
+	partitions [][]Message
+	// offset of the first kept message per partition
+	first []int64
+	// group -> offsets
+	groups map[string]*memoryGroup
+}
+
+type memoryGroup struct {
+	// next offset to fetch per partition
+	next []int64
+	// next offset to fetch after a restart per partition
+	committed []int64
+}
+
+func NewMemory(partitions int) *MemoryBroker {
+	return &MemoryBroker{
+		partitions: max(partitions, 1),
+		topics:     map[string]*memoryTopic{},
+		published:  make(chan struct{}),
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, messages ...Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, message := range messages {
+		t := b.topic(message.Topic)
+		p := b.partition(message.Key)
+		message.Partition = p
+		message.Offset = t.first[p] + int64(len(t.partitions[p]))
+		t.partitions[p] = append(t.partitions[p], message)
+	}
+
+	close(b.published)
+	b.published = make(chan struct{})
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(topic, group string) Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topic(topic)
+	if _, ok := t.groups[group]; !ok {
+		// starts from the oldest kept message, like kafka.FirstOffset
+		t.groups[group] = &memoryGroup{
+			next:      slices.Clone(t.first),
+			committed: slices.Clone(t.first),
+		}
+	}
+	return &memorySubscription{broker: b, topic: t, group: t.groups[group]}
+}
+
+func (b *MemoryBroker) Close() error {
+	return nil
+}
+
+// messages of the topic which are still kept, ordered by partition
+func (b *MemoryBroker) Messages(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var messages []Message
+	if t, ok := b.topics[topic]; ok {
+		for _, partition := range t.partitions {
+			messages = append(messages, partition...)
+		}
+	}
+	return messages
+}
+
+// amount of messages in the topic the group has not committed yet
+func (b *MemoryBroker) Lag(topic, group string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var lag int64
+	t, ok := b.topics[topic]
+	if !ok {
+		return 0
+	}
+	g, ok := t.groups[group]
+	for p, partition := range t.partitions {
+		end := t.first[p] + int64(len(partition))
+		if ok {
+			lag += end - g.committed[p]
+		} else {
+			lag += end
+		}
+	}
+	return lag
+}
+
+// has to be called with the lock held
+func (b *MemoryBroker) topic(topic string) *memoryTopic {
+	t, ok := b.topics[topic]
+	if !ok {
+		t = &memoryTopic{
+			partitions: make([][]Message, b.partitions),
+			first:      make([]int64, b.partitions),
+			groups:     map[string]*memoryGroup{},
+		}
+		b.topics[topic] = t
+	}
+	return t
+}
+
+// drops the messages of the partition every group has committed,
+// has to be called with the lock held
+func (t *memoryTopic) trim(p int) {
+	committed := int64(-1)
+	for _, g := range t.groups {
+		if committed == -1 || g.committed[p] < committed {
+			committed = g.committed[p]
+		}
+	}
+	n := committed - t.first[p]
+	if n <= 0 {
+		return
+	}
+	// the dropped values can be garbage collected before the slice grows into a new array
+	clear(t.partitions[p][:n])
+	t.partitions[p] = t.partitions[p][n:]
+	t.first[p] = committed
+}
+
+// same key -> same partition, like kafka's hash balancer
+func (b *MemoryBroker) partition(key []byte) int {
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % uint32(b.partitions))
+}
+
+type memorySubscription struct {
+	broker *MemoryBroker
+	topic  *memoryTopic
+	group  *memoryGroup
+	// partition to look at first, so a busy partition won't starve the others
+	start int
+}
+
+func (s *memorySubscription) Fetch(ctx context.Context) (Message, error) {
+	b := s.broker
+	for {
+		b.mu.Lock()
+		partitions := s.topic.partitions
+		for i := range partitions {
+			p := (s.start + i) % len(partitions)
+			if at := s.group.next[p] - s.topic.first[p]; at < int64(len(partitions[p])) {
+				message := partitions[p][at]
+				s.group.next[p]++
+				s.start = p + 1
+				b.mu.Unlock()
+				return message, nil
+			}
+		}
+		published := b.published
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-published:
+		}
+	}
+}
+
+func (s *memorySubscription) Commit(ctx context.Context, message Message) error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.group.committed[message.Partition] = max(s.group.committed[message.Partition], message.Offset+1)
+	s.topic.trim(message.Partition)
+	return nil
+}
+
+// uncommitted messages will be fetched again, like after a rebalance in kafka
+func (s *memorySubscription) Close() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	copy(s.group.next, s.group.committed)
+	return nil
+}
@@ -0,0 +1,119 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func fetch(t *testing.T, sub Subscription) Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	message, err := sub.Fetch(ctx)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	return message
+}
+
+func TestMemorySameKeySamePartition(t *testing.T) {
+	b := NewMemory(4)
+	for range 3 {
+		b.Publish(context.Background(), Message{Topic: "t", Key: []byte("house-1")})
+	}
+
+	messages := b.Messages("t")
+	for i, message := range messages {
+		if message.Partition != messages[0].Partition {
+			t.Errorf("message %d went to partition %d, want %d", i, message.Partition, messages[0].Partition)
+		}
+		if message.Offset != int64(i) {
+			t.Errorf("message %d has offset %d", i, message.Offset)
+		}
+	}
+}
+
+func TestMemoryRedeliversUncommitted(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemory(1)
+	b.Publish(ctx, Message{Topic: "t", Value: []byte("1")}, Message{Topic: "t", Value: []byte("2")})
+
+	sub := b.Subscribe("t", "g")
+	first := fetch(t, sub)
+	sub.Commit(ctx, first)
+	fetch(t, sub)
+	if lag := b.Lag("t", "g"); lag != 1 {
+		t.Errorf("lag = %d, want 1", lag)
+	}
+	sub.Close()
+
+	again := fetch(t, b.Subscribe("t", "g"))
+	if string(again.Value) != "2" {
+		t.Errorf("got %q after restart, want the uncommitted message", again.Value)
+	}
+}
+
+func TestMemoryDropsMessagesEveryGroupCommitted(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemory(1)
+	audit := b.Subscribe("t", "audit")
+	notify := b.Subscribe("t", "notify")
+	b.Publish(ctx, Message{Topic: "t", Value: []byte("1")}, Message{Topic: "t", Value: []byte("2")})
+
+	audit.Commit(ctx, fetch(t, audit))
+	audit.Commit(ctx, fetch(t, audit))
+	if kept := len(b.Messages("t")); kept != 2 {
+		t.Fatalf("kept %d messages, want 2 while one group has not committed them", kept)
+	}
+	notify.Commit(ctx, fetch(t, notify))
+	kept := b.Messages("t")
+	if len(kept) != 1 || kept[0].Offset != 1 {
+		t.Fatalf("kept %v, want the second message", kept)
+	}
+	if lag := b.Lag("t", "notify"); lag != 1 {
+		t.Errorf("lag = %d, want 1", lag)
+	}
+
+	// offsets keep growing after messages were dropped
+	b.Publish(ctx, Message{Topic: "t", Value: []byte("3")})
+	if message := fetch(t, audit); message.Offset != 2 || string(message.Value) != "3" {
+		t.Errorf("got offset %d %q, want offset 2", message.Offset, message.Value)
+	}
+	notify.Close()
+	if message := fetch(t, b.Subscribe("t", "notify")); string(message.Value) != "2" {
+		t.Errorf("got %q after restart, want the uncommitted message", message.Value)
+	}
+	// a new group starts from the oldest kept message
+	if message := fetch(t, b.Subscribe("t", "webhooks")); string(message.Value) != "2" {
+		t.Errorf("new group got %q, want the oldest kept message", message.Value)
+	}
+
+	// nobody reads dead letters, they are kept
+	b.Publish(ctx, Message{Topic: "t.dead-letter", Value: []byte("1")})
+	if kept := len(b.Messages("t.dead-letter")); kept != 1 {
+		t.Errorf("kept %d dead letters, want 1", kept)
+	}
+}
+
+func TestMemoryFetchWaitsForPublish(t *testing.T) {
+	b := NewMemory(1)
+	sub := b.Subscribe("t", "g")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Publish(context.Background(), Message{Topic: "t", Value: []byte("late")})
+	}()
+
+	if message := fetch(t, sub); string(message.Value) != "late" {
+		t.Errorf("got %q, want late", message.Value)
+	}
+}
+
+func TestMemoryFetchCanceled(t *testing.T) {
+	sub := NewMemory(1).Subscribe("t", "g")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := sub.Fetch(ctx); err == nil {
+		t.Error("Fetch returned without an error on a canceled context")
+	}
+}
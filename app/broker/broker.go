@@ -0,0 +1,51 @@
+// message broker used for streaming domain events out of the app
+//
+// kafka is used when KAFKA_BROKERS is set, otherwise everything stays in memory
+package broker
+
+import (
+	"context"
+	"roommates/logger"
+	"roommates/utils"
+	"strings"
+)
+
+var log = logger.BrokerLoggger
+
+type Message struct {
+	Topic string
+	// messages with the same key end up in the same partition, keeping their order
+	Key   []byte
+	Value []byte
+	// only set for consumed messages
+	Partition int
+	Offset    int64
+}
+
+type Broker interface {
+	// publishes all of the messages, returns after the broker has acknowledged them
+	Publish(ctx context.Context, messages ...Message) error
+	// joins the consumer group of the topic,
+	// messages are split between subscriptions of the same group
+	Subscribe(topic, group string) Subscription
+	Close() error
+}
+
+type Subscription interface {
+	// blocks until a message is available or ctx is done
+	Fetch(ctx context.Context) (Message, error)
+	// marks the message and everything before it in the same partition as consumed
+	Commit(ctx context.Context, message Message) error
+	Close() error
+}
+
+// creates a kafka broker using KAFKA_BROKERS (comma separated addresses),
+// falls back to the in-memory broker when the variable is empty
+func New() Broker {
+	addresses := utils.GetEnv("KAFKA_BROKERS", "")
+	if addresses == "" {
+		log.Warn().Msg("KAFKA_BROKERS not set, using in-memory broker")
+		return NewMemory(1)
+	}
+	return NewKafka(strings.Split(addresses, ","))
+}
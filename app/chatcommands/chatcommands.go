@@ -0,0 +1,196 @@
+// slash-commands in house chat messages, "/owe 12.50 pizza @anna"
+//
+// the registry only parses messages and finds the command, what the commands do
+// is up to whoever registers them
+package chatcommands
+
+import (
+	"errors"
+	l "roommates/locales"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	Prefix        = "/"
+	MentionPrefix = "@"
+)
+
+var (
+	ErrNotCommand     = errors.New("message is not a command")
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrForbidden      = errors.New("not allowed to use the command")
+)
+
+// parsed command message
+type Invocation struct {
+	// lowercase name without the prefix, "owe"
+	Name string
+	// words which are not mentions, in the order they were given
+	Args []string
+	// usernames without the prefix, in the order they were given
+	Mentions []string
+}
+
+// words of the arguments from the index on joined with a space, "" when there are none
+func (i Invocation) Text(from int) string {
+	if from >= len(i.Args) {
+		return ""
+	}
+	return strings.Join(i.Args[from:], " ")
+}
+
+func IsCommand(content string) bool {
+	return strings.HasPrefix(strings.TrimSpace(content), Prefix)
+}
+
+// "/owe 12.50 pizza @anna" -> owe, [12.50 pizza], [anna]
+func Parse(content string) (Invocation, error) {
+	var inv Invocation
+	words := strings.Fields(content)
+	if len(words) == 0 {
+		return inv, ErrNotCommand
+	}
+	name, ok := strings.CutPrefix(words[0], Prefix)
+	if !ok || name == "" || strings.IndexFunc(name, func(r rune) bool { return !unicode.IsLetter(r) }) != -1 {
+		return inv, ErrNotCommand
+	}
+	inv.Name = strings.ToLower(name)
+
+	for _, word := range words[1:] {
+		if mention, ok := mentionOf(word); ok {
+			inv.Mentions = append(inv.Mentions, mention)
+			continue
+		}
+		inv.Args = append(inv.Args, word)
+	}
+	return inv, nil
+}
+
+// usernames mentioned anywhere in the message, "hi @anna, @mari!" -> [anna mari]
+func Mentions(content string) []string {
+	var mentions []string
+	for _, word := range strings.Fields(content) {
+		if mention, ok := mentionOf(word); ok {
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions
+}
+
+func mentionOf(word string) (string, bool) {
+	mention, ok := strings.CutPrefix(word, MentionPrefix)
+	// "@anna," in the middle of a sentence
+	mention = strings.TrimRight(mention, ",.;:!?")
+	return mention, ok && mention != ""
+}
+
+// command in a house conversation
+type Call struct {
+	Invocation
+	HouseID pgtype.UUID
+	// sender of the message
+	UserID pgtype.UUID
+}
+
+// answer to the sender, translated when shown
+type Reply struct {
+	Messages []l.LKMessage
+	// where the answer points to, "" when nowhere
+	Link string
+	// the command was not done, messages tell why
+	Failed bool
+}
+
+func Ok(key l.LK, args ...any) Reply {
+	return Reply{Messages: []l.LKMessage{{Key: key, Args: args}}}
+}
+
+func Fail(key l.LK, args ...any) Reply {
+	return Reply{Messages: []l.LKMessage{{Key: key, Args: args}}, Failed: true}
+}
+
+// failed because of validation messages of a model
+func FailWith(msgs []l.LKMessage) Reply {
+	return Reply{Messages: msgs, Failed: true}
+}
+
+// does the command, errors are only for things the sender can not fix
+type Handler func(ctx *gin.Context, call Call) (Reply, error)
+
+// tells if the sender is allowed to use the command
+type Permission func(ctx *gin.Context, call Call) (bool, error)
+
+type Command struct {
+	Name string
+	// how the command is used, shown by help and when the arguments are wrong
+	Usage l.LK
+	// nil allows everyone who can write into the conversation
+	Allowed Permission
+	Run     Handler
+}
+
+// commands by name
+type Registry struct {
+	commands map[string]Command
+	// names in the order of registration
+	names []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{commands: map[string]Command{}}
+}
+
+// registers the command, replaces the previous one with the same name
+func (r *Registry) Register(cmd Command) *Registry {
+	if _, ok := r.commands[cmd.Name]; !ok {
+		r.names = append(r.names, cmd.Name)
+	}
+	r.commands[cmd.Name] = cmd
+	return r
+}
+
+func (r *Registry) Lookup(name string) (Command, bool) {
+	cmd, ok := r.commands[name]
+	return cmd, ok
+}
+
+// in the order they were registered
+func (r *Registry) Commands() []Command {
+	commands := make([]Command, len(r.names))
+	for i, name := range r.names {
+		commands[i] = r.commands[name]
+	}
+	return commands
+}
+
+// parses the message and runs its command after checking the permission
+//
+// errors are ErrNotCommand, ErrUnknownCommand, ErrForbidden or whatever the command returned,
+// the invocation is returned with them so the caller can tell the sender what went wrong
+func (r *Registry) Run(ctx *gin.Context, content string, houseID, userID pgtype.UUID) (Invocation, Reply, error) {
+	inv, err := Parse(content)
+	if err != nil {
+		return inv, Reply{}, err
+	}
+	cmd, ok := r.Lookup(inv.Name)
+	if !ok {
+		return inv, Reply{}, ErrUnknownCommand
+	}
+
+	call := Call{Invocation: inv, HouseID: houseID, UserID: userID}
+	if cmd.Allowed != nil {
+		allowed, err := cmd.Allowed(ctx, call)
+		if err != nil {
+			return inv, Reply{}, err
+		}
+		if !allowed {
+			return inv, Reply{}, ErrForbidden
+		}
+	}
+	reply, err := cmd.Run(ctx, call)
+	return inv, reply, err
+}
@@ -0,0 +1,202 @@
+package chatcommands
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	g "roommates/globals"
+	l "roommates/locales"
+	"roommates/middleware"
+	"roommates/rdb"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		content  string
+		want     Invocation
+		wantErr  error
+		wantText string
+	}{
+		{content: "/owe 12.50 pizza @anna", want: Invocation{Name: "owe", Args: []string{"12.50", "pizza"}, Mentions: []string{"anna"}}, wantText: "pizza"},
+		{content: "  /OWE   12.50\tpizza  ", want: Invocation{Name: "owe", Args: []string{"12.50", "pizza"}}, wantText: "pizza"},
+		{content: "/owe 5 kebab @anna, @mari and @jaan!", want: Invocation{Name: "owe", Args: []string{"5", "kebab", "and"}, Mentions: []string{"anna", "mari", "jaan"}}, wantText: "kebab and"},
+		{content: "/note @mari. @jaan?", want: Invocation{Name: "note", Mentions: []string{"mari", "jaan"}}},
+		// only punctuation after the prefix is not a mention
+		{content: "/note wifi @ @!", want: Invocation{Name: "note", Args: []string{"wifi", "@", "@!"}}, wantText: "@ @!"},
+		{content: "/mälu", want: Invocation{Name: "mälu"}},
+		{content: "/help", want: Invocation{Name: "help"}},
+		{content: "", wantErr: ErrNotCommand},
+		{content: "   ", wantErr: ErrNotCommand},
+		{content: "hello /help", wantErr: ErrNotCommand},
+		{content: "/", wantErr: ErrNotCommand},
+		{content: "/ help", wantErr: ErrNotCommand},
+		{content: "/owe2 12", wantErr: ErrNotCommand},
+		{content: "/owe! 12", wantErr: ErrNotCommand},
+		{content: "//owe", wantErr: ErrNotCommand},
+		{content: "@anna /owe", wantErr: ErrNotCommand},
+	}
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			got, err := Parse(tt.content)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.content, err, tt.wantErr)
+			}
+			if got.Name != tt.want.Name || !slices.Equal(got.Args, tt.want.Args) || !slices.Equal(got.Mentions, tt.want.Mentions) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+			if text := got.Text(1); text != tt.wantText {
+				t.Errorf("Parse(%q).Text(1) = %q, want %q", tt.content, text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"/help", true},
+		{"  /owe 12 pizza", true},
+		{"/", true},
+		{"hello", false},
+		{"hello /help", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsCommand(tt.content); got != tt.want {
+			t.Errorf("IsCommand(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"hi @anna, @mari!", []string{"anna", "mari"}},
+		{"/owe 12 pizza @anna @anna", []string{"anna", "anna"}},
+		{"email me at anna@roommates.test", nil},
+		{"@ @! @?", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Mentions(tt.content); !slices.Equal(got, tt.want) {
+			t.Errorf("Mentions(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+// context of a user signed in with an access token which has the scopes
+func tokenContext(userID pgtype.UUID, scopes ...string) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	auth := &rdb.UserSessionValue{UserID: userID, AccessTokenID: 1, Scopes: scopes}
+	ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), g.GAuth, auth))
+	return ctx
+}
+
+func scopePermission(scope string) Permission {
+	return func(ctx *gin.Context, call Call) (bool, error) {
+		return middleware.GetAuthInfo(ctx).HasScope(scope), nil
+	}
+}
+
+func TestRegistryRun(t *testing.T) {
+	errBroken := errors.New("database is down")
+	var houseID, userID pgtype.UUID
+	houseID.Scan("0b1d2c4e-8a51-4f5e-a6f5-6c2f0e4b7a10")
+	userID.Scan("3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f")
+
+	var ran []Call
+	run := func(ctx *gin.Context, call Call) (Reply, error) {
+		ran = append(ran, call)
+		return Ok(l.LKChatCommandsNoteFound, call.Text(0)), nil
+	}
+	r := NewRegistry().Register(Command{
+		Name: "note",
+		Run:  run,
+	}).Register(Command{
+		Name:    "owe",
+		Allowed: scopePermission("write:payments"),
+		Run:     run,
+	}).Register(Command{
+		Name: "chore",
+		Allowed: func(ctx *gin.Context, call Call) (bool, error) {
+			return false, errBroken
+		},
+		Run: run,
+	}).Register(Command{
+		Name: "fail",
+		Run: func(ctx *gin.Context, call Call) (Reply, error) {
+			return Reply{}, errBroken
+		},
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		scopes  []string
+		wantInv string
+		wantErr error
+		wantRun bool
+	}{
+		{name: "no permission needed", content: "/note wifi @mari", wantInv: "note", wantRun: true},
+		{name: "scope given", content: "/owe 12 pizza @mari", scopes: []string{"write:payments"}, wantInv: "owe", wantRun: true},
+		{name: "scope missing", content: "/owe 12 pizza @mari", scopes: []string{"read:payments"}, wantInv: "owe", wantErr: ErrForbidden},
+		{name: "case of the name", content: "/NOTE wifi", wantInv: "note", wantRun: true},
+		{name: "unknown command", content: "/shrug", wantInv: "shrug", wantErr: ErrUnknownCommand},
+		{name: "not a command", content: "hello", wantErr: ErrNotCommand},
+		{name: "permission error", content: "/chore done trash", wantInv: "chore", wantErr: errBroken},
+		{name: "command error", content: "/fail", wantInv: "fail", wantErr: errBroken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = nil
+			inv, reply, err := r.Run(tokenContext(userID, tt.scopes...), tt.content, houseID, userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Run(%q) error = %v, want %v", tt.content, err, tt.wantErr)
+			}
+			if inv.Name != tt.wantInv {
+				t.Errorf("Run(%q) invocation = %q, want %q", tt.content, inv.Name, tt.wantInv)
+			}
+			if (len(ran) == 1) != tt.wantRun || len(ran) > 1 {
+				t.Fatalf("Run(%q) ran the command %d times, want it to run: %v", tt.content, len(ran), tt.wantRun)
+			}
+			if !tt.wantRun {
+				return
+			}
+			if ran[0].HouseID != houseID || ran[0].UserID != userID || ran[0].Name != tt.wantInv {
+				t.Errorf("Run(%q) called the command with %+v", tt.content, ran[0])
+			}
+			if len(reply.Messages) != 1 || reply.Failed {
+				t.Errorf("Run(%q) reply = %+v, want the reply of the command", tt.content, reply)
+			}
+		})
+	}
+}
+
+func TestRegistryCommands(t *testing.T) {
+	r := NewRegistry().
+		Register(Command{Name: "help", Usage: l.LKChatCommandsUsageHelp}).
+		Register(Command{Name: "owe", Usage: l.LKChatCommandsUsageOwe}).
+		Register(Command{Name: "help", Usage: l.LKChatCommandsUsageNote})
+
+	var names []string
+	for _, cmd := range r.Commands() {
+		names = append(names, cmd.Name)
+	}
+	if !slices.Equal(names, []string{"help", "owe"}) {
+		t.Errorf("Commands() = %v, want registration order without duplicates", names)
+	}
+	if cmd, _ := r.Lookup("help"); cmd.Usage != l.LKChatCommandsUsageNote {
+		t.Errorf("Lookup(help) usage = %v, want the replacing command", cmd.Usage)
+	}
+}
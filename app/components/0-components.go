@@ -2,8 +2,13 @@
 package components
 
 import (
+	"context"
 	"encoding/json"
+	"roommates/globals"
+	"roommates/images"
+	"roommates/middleware"
 	"roommates/rdb"
+	"roommates/utils"
 
 	"github.com/a-h/templ"
 )
@@ -75,6 +80,96 @@ const (
 	HnId = "house-note"
 )
 
+// id for house payment form element
+const (
+	HpId = "house-payment"
+)
+
+// id for house reminder form element
+const (
+	HrId = "house-reminder"
+)
+
+// id for the email verification banner and modal
+const (
+	VeId = "verify-email"
+)
+
+// id for image sections
+const (
+	AvId   = "avatar"
+	HpicId = "house-picture"
+	CiId   = "conversation-image"
+)
+
+// id for the profile form on the profile page
+const (
+	PfId = "profile-form"
+)
+
+// id for the change password form on the profile page
+const (
+	CpId = "change-password"
+)
+
+// id for the two-factor section on the profile page
+const (
+	TfId = "two-factor"
+)
+
+// id for the list of sessions on the profile page
+const (
+	SlId = "sessions"
+)
+
+// id for the personal access tokens on the profile page
+const (
+	AtId = "access-tokens"
+)
+
+// ids for the shopping list on the house page
+const (
+	ShfId = "shopping-form"
+	ShlId = "shopping-list"
+	ShpId = "shopping-purchase"
+)
+
+// id for the calendar feeds on the profile page
+const (
+	CfId = "calendar-feeds"
+)
+
+// id for the webhooks on the house page
+const (
+	WhId = "house-webhooks"
+)
+
+// id for the connected accounts on the profile page
+const (
+	IdId = "identities"
+)
+
+// id for the data export and account deletion on the profile page
+const (
+	AcId = "account"
+)
+
+// id for the discoverability and blocked users on the profile page
+const (
+	PrId = "privacy"
+)
+
+// id for the block button on the user page
+const (
+	UbId = "user-block"
+)
+
+// id for notification elements
+const (
+	NlId = "notification-list"
+	NpId = "notification-preferences"
+)
+
 // hyperscript constants
 const (
 	// open modal after htmx load
@@ -108,6 +203,20 @@ func FormSwapOuterHxAttributes(id string) templ.Attributes {
 	}
 }
 
+// put on <body> so that every htmx request carries the csrf token
+func CSRFHxHeaders(ctx context.Context) templ.Attributes {
+	return templ.Attributes{
+		"hx-headers": HxValsData(map[string]string{
+			string(globals.HXCSRFToken): middleware.CSRFToken(ctx),
+		}),
+	}
+}
+
+// url of a stored image, see images.Store
+func ImageURL(key string, size images.Size) string {
+	return utils.ReplaceParam(utils.ReplaceParam(globals.RImage, "key", key), "size", string(size))
+}
+
 // json marshals the map and converts it into string
 func HxValsData(data map[string]string) string {
 	marshalled, _ := json.Marshal(data)
@@ -0,0 +1,31 @@
+// consumers of the events published through the outbox
+package consumers
+
+import (
+	"context"
+	"roommates/events"
+	"roommates/logger"
+)
+
+// writes every event into the audit log
+//
+// for now the log is only stdout, collecting it is not that important
+func AuditLog() *events.Consumer {
+	c := events.NewConsumer("audit-log")
+	for _, t := range events.AllTypes {
+		c.On(t, audit)
+	}
+	return c
+}
+
+func audit(ctx context.Context, message events.Message) error {
+	logger.AuditLoggger.Info().
+		Int64("event_id", message.ID).
+		Str("type", string(message.Type)).
+		Str("house_id", message.HouseID.String()).
+		Str("actor_id", message.ActorID.String()).
+		Any("payload", message.Payload).
+		Time("created_at", message.CreatedAt).
+		Msg("house event")
+	return nil
+}
@@ -0,0 +1,197 @@
+package consumers
+
+import (
+	"context"
+	"errors"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	"roommates/events"
+	"roommates/globals"
+	"roommates/locales"
+	"roommates/mailer"
+	"roommates/notifications"
+	"roommates/utils"
+	"slices"
+	"strconv"
+
+	"github.com/invopop/ctxi18n"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type notificationDispatcher struct {
+	db     *dbqueries.Queries
+	mailer *mailer.Mailer
+}
+
+// turns house events into notifications for the users they concern,
+// every stored notification is also emailed, a failed email fails the event so that it is retried
+func NotificationDispatch(db *dbqueries.Queries, m *mailer.Mailer) *events.Consumer {
+	d := notificationDispatcher{db: db, mailer: m}
+	return events.NewConsumer("notification-dispatch").
+		On(events.MemberAdded, d.houseInvite).
+		On(events.MessageMentioned, d.chatMention).
+		On(events.NoteCreated, d.noteChanged).
+		On(events.NoteEdited, d.noteChanged).
+		On(events.PaymentCreated, d.paymentAssigned).
+		On(events.ReminderDue, d.reminderDue)
+}
+
+// user that was added to the house
+func (d notificationDispatcher) houseInvite(ctx context.Context, message events.Message) error {
+	var userID pgtype.UUID
+	if err := userID.Scan(message.Payload.SubjectID); err != nil {
+		return err
+	}
+	return d.notify(ctx, message, notifications.HouseInvite, []pgtype.UUID{userID})
+}
+
+// resident that was mentioned in a house conversation
+func (d notificationDispatcher) chatMention(ctx context.Context, message events.Message) error {
+	var userID pgtype.UUID
+	if err := userID.Scan(message.Payload.SubjectID); err != nil {
+		return err
+	}
+	return d.notify(ctx, message, notifications.ChatMention, []pgtype.UUID{userID})
+}
+
+// everyone in the house
+func (d notificationDispatcher) noteChanged(ctx context.Context, message events.Message) error {
+	residents, err := d.residents(ctx, message.HouseID)
+	if err != nil {
+		return err
+	}
+	return d.notify(ctx, message, notifications.NoteChanged, residents)
+}
+
+// the resident the reminder is assigned to, everyone in the house
+// when it is for the whole house or the assignee has moved out
+func (d notificationDispatcher) reminderDue(ctx context.Context, message events.Message) error {
+	reminderID, err := strconv.ParseInt(message.Payload.SubjectID, 10, 32)
+	if err != nil {
+		return err
+	}
+	assignee, err := d.db.SelectReminderAssignee(ctx, int32(reminderID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// deleted since it became due
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	residents, err := d.residents(ctx, message.HouseID)
+	if err != nil {
+		return err
+	}
+	if assignee.Valid && slices.Contains(residents, assignee) {
+		residents = []pgtype.UUID{assignee}
+	}
+	return d.notify(ctx, message, notifications.ReminderDue, residents)
+}
+
+// everyone who has to pay
+func (d notificationDispatcher) paymentAssigned(ctx context.Context, message events.Message) error {
+	var paymentID pgtype.UUID
+	if err := paymentID.Scan(message.Payload.SubjectID); err != nil {
+		return err
+	}
+	payers, err := d.db.SelectPaymentPayerIDs(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+	return d.notify(ctx, message, notifications.PaymentAssigned, payers)
+}
+
+func (d notificationDispatcher) residents(ctx context.Context, houseID pgtype.UUID) ([]pgtype.UUID, error) {
+	residents, err := d.db.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]pgtype.UUID, len(residents))
+	for i, resident := range residents {
+		ids[i] = resident.ID
+	}
+	return ids, nil
+}
+
+// notifies the recipients, except whoever caused the event
+func (d notificationDispatcher) notify(ctx context.Context, message events.Message, kind notifications.Kind, recipients []pgtype.UUID) error {
+	link := utils.ReplaceParam(globals.RHouseID, "id", message.HouseID.String())
+	switch kind {
+	case notifications.PaymentAssigned:
+		link = globals.RPayments
+	case notifications.ChatMention:
+		link = globals.RMessaging
+	}
+
+	for _, recipient := range recipients {
+		if recipient == message.ActorID {
+			continue
+		}
+		_, err := notifications.Notify(ctx, d.db, notifications.Notification{
+			UserID:  recipient,
+			Kind:    kind,
+			Subject: message.Payload.Subject,
+			Link:    link,
+			HouseID: message.HouseID,
+			ActorID: message.ActorID,
+			EventID: message.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// stored now or on an earlier delivery whose email failed,
+		// none when the kind is turned off or the email was already sent
+		notificationID, err := d.db.SelectUnemailedNotification(ctx, dbqueries.SelectUnemailedNotificationParams{
+			EventID: &message.ID,
+			UserID:  recipient,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		// the event is retried, recipients already emailed are skipped then
+		if err = d.email(ctx, message, kind, recipient, link); err != nil {
+			return err
+		}
+		if err = d.db.MarkNotificationEmailed(ctx, notificationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d notificationDispatcher) email(ctx context.Context, message events.Message, kind notifications.Kind, recipient pgtype.UUID, link string) error {
+	user, err := d.db.SelectUserEmail(ctx, recipient)
+	if err != nil {
+		return err
+	}
+	// in the language the recipient last signed in with
+	lang := locales.Default
+	if user.Locale != nil && locales.Language(*user.Locale).Valid() {
+		lang = locales.Language(*user.Locale)
+	}
+	ctx, err = ctxi18n.WithLocale(ctx, string(lang))
+	if err != nil {
+		return err
+	}
+
+	actor := utils.T(ctx, locales.LKActivityUnknownUser, "Deleted user")
+	if message.ActorID.Valid {
+		if row, err := d.db.SelectUserEmail(ctx, message.ActorID); err == nil {
+			actor = row.Username
+		}
+	}
+
+	text := components.NotificationMessage(ctx, kind, actor, message.Payload.Subject)
+	return d.mailer.Send(ctx, mailer.Mail{
+		To:      user.Email,
+		UserID:  recipient,
+		Kind:    kind,
+		Subject: text,
+		Body:    components.EmailNotification(text, d.mailer.URL(link)),
+	})
+}
@@ -0,0 +1,103 @@
+package consumers
+
+import (
+	"context"
+	"errors"
+	"roommates/db/dbqueries"
+	"roommates/db/dbtest"
+	"roommates/events"
+	"roommates/locales"
+	"roommates/mailer"
+	"roommates/notifications"
+	"sync"
+	"testing"
+
+	"github.com/invopop/ctxi18n"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fails the first deliveries, then keeps what it was given
+type flakyBackend struct {
+	mu       sync.Mutex
+	failures int
+	sent     [][]string
+}
+
+func (b *flakyBackend) Deliver(ctx context.Context, from string, to []string, message []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures > 0 {
+		b.failures--
+		return errors.New("smtp is down")
+	}
+	b.sent = append(b.sent, to)
+	return nil
+}
+
+func (b *flakyBackend) sentCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.sent)
+}
+
+// a failed email fails the event, the redelivery sends it once
+func TestNotifyRetriesFailedEmail(t *testing.T) {
+	if err := ctxi18n.Load(locales.Content); err != nil {
+		t.Fatal(err)
+	}
+	pool := dbtest.Pool(t)
+	db := dbqueries.New(pool)
+	bg := context.Background()
+
+	actor, err := db.InsertUser(bg, dbqueries.InsertUserParams{Email: "actor@roommates.test", Username: "actor", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	recipient, err := db.InsertUser(bg, dbqueries.InsertUserParams{Email: "recipient@roommates.test", Username: "recipient", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	houseID, err := db.InsertHouse(bg, dbqueries.InsertHouseParams{Name: "house", MakerID: actor})
+	if err != nil {
+		t.Fatalf("could not insert house: %v", err)
+	}
+
+	backend := &flakyBackend{failures: 1}
+	d := notificationDispatcher{
+		db:     db,
+		mailer: mailer.NewWithBackend(backend, "Roommates <no-reply@roommates.test>", "https://roommates.test", []byte("secret")),
+	}
+	message := events.Message{
+		ID:      1,
+		Type:    events.NoteCreated,
+		HouseID: houseID,
+		ActorID: actor,
+		Payload: events.Payload{SubjectID: "1", Subject: "wifi"},
+	}
+	notify := func() error {
+		return d.notify(bg, message, notifications.NoteChanged, []pgtype.UUID{actor, recipient})
+	}
+
+	if err = notify(); err == nil {
+		t.Fatal("notify with a failing mailer = nil, want the error so that the event is retried")
+	}
+	if backend.sentCount() != 0 {
+		t.Fatalf("sent %d emails, want none", backend.sentCount())
+	}
+	if err = notify(); err != nil {
+		t.Fatalf("notify on redelivery = %v", err)
+	}
+	if err = notify(); err != nil {
+		t.Fatalf("notify on another redelivery = %v", err)
+	}
+	if backend.sentCount() != 1 {
+		t.Errorf("sent %d emails, want 1", backend.sentCount())
+	}
+	count, err := db.CountUnreadNotifications(bg, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("recipient has %d notifications, want 1", count)
+	}
+}
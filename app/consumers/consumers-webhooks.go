@@ -0,0 +1,19 @@
+package consumers
+
+import (
+	"roommates/db/dbqueries"
+	"roommates/events"
+	"roommates/webhooks"
+)
+
+// posts house events to the webhooks subscribed to them
+func WebhookDispatch(db *dbqueries.Queries) *events.Consumer {
+	d := webhooks.NewDispatcher(db)
+	c := events.NewConsumer("webhook-dispatch")
+	// retries of a slow receiver would hold back the other houses
+	c.Concurrency = 4
+	for _, t := range webhooks.EventTypes {
+		c.On(t, d.Deliver)
+	}
+	return c
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/accesstokens"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (c *Controller) renderAccessTokens(ctx *gin.Context, userID pgtype.UUID, created string, model models.AccessToken) {
+	tokens, err := c.DB.SelectUserAccessTokens(ctx, userID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get access tokens")
+		return
+	}
+	RenderTempl(ctx, components.AccessTokenSection(tokens, created, model))
+}
+
+// creates a personal access token, the token is shown only in this response
+func (c *Controller) PostHxAccessToken(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	var model models.AccessToken
+	ctx.ShouldBind(&model)
+
+	isValid, _ := model.IsValid()
+	if !isValid {
+		c.renderAccessTokens(ctx, authInfo.UserID, "", model)
+		return
+	}
+
+	token, hash := accesstokens.New()
+	_, err := c.DB.InsertAccessToken(ctx, dbqueries.InsertAccessTokenParams{
+		UserID:    authInfo.UserID,
+		Name:      strings.TrimSpace(model.Name),
+		TokenHash: hash,
+		Scopes:    model.Scopes,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not create access token")
+		return
+	}
+
+	c.renderAccessTokens(ctx, authInfo.UserID, token, models.AccessToken{ModelBase: models.ModelBase{Initial: true}})
+}
+
+func (c *Controller) DeleteHxAccessToken(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, g.ErrorInvalidID)
+		return
+	}
+
+	deleted, err := c.DB.DeleteAccessToken(ctx, dbqueries.DeleteAccessTokenParams{
+		ID:     id,
+		UserID: authInfo.UserID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not revoke access token")
+		return
+	}
+	if deleted == 0 {
+		utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorInvalidID)
+		return
+	}
+
+	c.renderAccessTokens(ctx, authInfo.UserID, "", models.AccessToken{ModelBase: models.ModelBase{Initial: true}})
+}
+
+//------------------------------------------------------------------------------
+
+type CurrentUserResponse struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	// scopes of the personal access token, empty when authenticated with a session
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// CurrentUser godoc
+//
+//	@Summary      Current user
+//	@Description  User the request is authenticated as, useful for checking a personal access token
+//	@Tags         users
+//
+//	@Produce  json
+//	@Success  200  {object}  CurrentUserResponse
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/users/me [get]
+func (c *Controller) CurrentUser(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	ctx.JSON(http.StatusOK, CurrentUserResponse{
+		ID:       authInfo.UserID.String(),
+		Username: authInfo.Username,
+		Scopes:   authInfo.Scopes,
+	})
+}
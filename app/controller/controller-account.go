@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"roommates/accounts"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	"roommates/gintemplrenderer"
+	g "roommates/globals"
+	"roommates/locales"
+	"roommates/mailer"
+	"roommates/middleware"
+	"roommates/rdb"
+	"roommates/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ZIP of the personal data of the user
+func (c *Controller) ExportAccountData(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+
+	// written into memory first, an error halfway through would leave a broken download
+	var buf bytes.Buffer
+	if err := accounts.Export(ctx, c.DB, authInfo.UserID, &buf); err != nil {
+		HandleServerError(ctx, err, "could not export user data")
+		return
+	}
+
+	filename := "roommates-data-" + time.Now().Format("2006-01-02") + ".zip"
+	ctx.Header(string(g.HContentDisposition), `attachment; filename="`+filename+`"`)
+	ctx.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// how often a deletion link can be sent to the same user
+const accountDeletionCooldown = 2 * time.Minute
+
+// stored with the account deletion token
+type accountDeletion struct {
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (c *Controller) scheduleAccountDeletion(ctx *gin.Context, userID pgtype.UUID) (time.Time, error) {
+	scheduledAt := time.Now().Add(accounts.DeletionGracePeriod)
+	err := c.DB.ScheduleUserDeletion(ctx, dbqueries.ScheduleUserDeletionParams{
+		ScheduledAt: pgtype.Timestamptz{Time: scheduledAt, Valid: true},
+		UserID:      userID,
+	})
+	return scheduledAt, err
+}
+
+// sends the link confirming the deletion to the email of the user
+//
+//	bool == false -- a link was sent recently, nothing was sent
+func (c *Controller) sendAccountDeletionEmail(ctx *gin.Context, userID pgtype.UUID) (bool, error) {
+	allowed, err := c.RH.Cooldown(ctx, "account-deletion:"+userID.String(), accountDeletionCooldown)
+	if err != nil || !allowed {
+		return false, err
+	}
+
+	user, err := c.DB.SelectUserEmail(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	token, err := c.RH.CreateToken(ctx, rdb.TPAccountDeletion, accountDeletion{UserID: userID}, rdb.EAccountDeletion)
+	if err != nil {
+		return false, err
+	}
+
+	url := c.Mailer.URL(utils.ReplaceParam(g.RAccountDeletion, "token", token))
+	return true, c.Mailer.Send(ctx.Request.Context(), mailer.Mail{
+		To:      user.Email,
+		UserID:  userID,
+		Subject: utils.T(ctx.Request.Context(), locales.LKEmailAccountDeletionSubject, "Confirm deleting your account"),
+		Body:    components.EmailAccountDeletion(url),
+	})
+}
+
+// schedules the account for deletion after accounts.DeletionGracePeriod, asks for the password
+//
+// accounts without a password get a confirmation link to their email instead
+func (c *Controller) PostHxAccountDeletion(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+
+	passwordSet, err := c.DB.IsUserPasswordSet(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get user password")
+		return
+	}
+	if !passwordSet {
+		sent, err := c.sendAccountDeletionEmail(ctx, authInfo.UserID)
+		if err != nil {
+			HandleServerError(ctx, err, "could not send account deletion email")
+			return
+		}
+		state := components.AccountState{LinkSent: true}
+		if !sent {
+			state = components.AccountState{Error: utils.T(ctx.Request.Context(), locales.LKVerifyEmailWait, "")}
+		}
+		RenderTempl(ctx, components.AccountSection(state))
+		return
+	}
+
+	current, err := c.DB.SelectUserPassword(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get user password")
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(current), []byte(ctx.PostForm("password"))) != nil {
+		RenderTempl(ctx, components.AccountSection(components.AccountState{
+			PasswordSet: true,
+			Error: utils.T(
+				ctx.Request.Context(),
+				locales.LKFormsPasswordErrorCurrentWrong,
+				g.ErrorWrongPassword.Error(),
+			),
+		}))
+		return
+	}
+
+	scheduledAt, err := c.scheduleAccountDeletion(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not schedule account deletion")
+		return
+	}
+	RenderTempl(ctx, components.AccountSection(components.AccountState{ScheduledAt: scheduledAt, PasswordSet: true}))
+}
+
+// the link from the account deletion email
+//
+// GET only asks for confirmation, the token is used up when the deletion is confirmed
+func (c *Controller) PageAccountDeletion(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	token := ctx.Param("token")
+	render := func(status int, valid bool) {
+		r := gintemplrenderer.New(ctx.Request.Context(), status, components.PageAccountDeletion(token, valid))
+		ctx.Render(r.Status, r)
+	}
+
+	var value accountDeletion
+	found, err := c.RH.PeekToken(ctx, rdb.TPAccountDeletion, token, &value)
+	if err != nil {
+		HandleServerError(ctx, err, "unable to check account deletion token")
+		return
+	}
+	// the link works only for the user it was sent to
+	if !found || value.UserID != authInfo.UserID {
+		render(http.StatusForbidden, false)
+		return
+	}
+
+	switch method := ctx.Request.Method; method {
+	case http.MethodGet:
+		render(http.StatusOK, true)
+	case http.MethodPost:
+		found, err = c.RH.ConsumeToken(ctx, rdb.TPAccountDeletion, token, &value)
+		if err != nil {
+			HandleServerError(ctx, err, "unable to use account deletion token")
+			return
+		}
+		if !found {
+			render(http.StatusForbidden, false)
+			return
+		}
+		if _, err = c.scheduleAccountDeletion(ctx, authInfo.UserID); err != nil {
+			HandleServerError(ctx, err, "could not schedule account deletion")
+			return
+		}
+		utils.Redirect(ctx, g.RProfile)
+	default:
+		ctx.String(http.StatusMethodNotAllowed, "method %s not allowed", method)
+	}
+}
+
+func (c *Controller) DeleteHxAccountDeletion(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	if err := c.DB.CancelUserDeletion(ctx, authInfo.UserID); err != nil {
+		HandleServerError(ctx, err, "could not cancel account deletion")
+		return
+	}
+	passwordSet, err := c.DB.IsUserPasswordSet(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get user password")
+		return
+	}
+	RenderTempl(ctx, components.AccountSection(components.AccountState{PasswordSet: passwordSet}))
+}
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	g "roommates/globals"
+	l "roommates/locales"
+	"roommates/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// responds with the translated validation messages of a model joined into one
+func validationErrorResponse(ctx *gin.Context, msgs []l.LKMessage) {
+	texts := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		texts = append(texts, utils.T(ctx.Request.Context(), msg.Key, "", msg.Args...))
+	}
+	utils.ErrorResponse(ctx, http.StatusBadRequest, errors.New(strings.Join(texts, "; ")))
+}
+
+// converts ids of users from a JSON request, responds with 400 when one of them is not valid
+func requireUserIDs(ctx *gin.Context, ids []string) ([]pgtype.UUID, bool) {
+	userIDs := make([]pgtype.UUID, 0, len(ids))
+	for _, id := range ids {
+		var userID pgtype.UUID
+		if err := userID.Scan(id); err != nil || !userID.Valid {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, g.ErrorInvalidID)
+			return nil, false
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, true
+}
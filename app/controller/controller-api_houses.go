@@ -0,0 +1,527 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/pagination"
+	"roommates/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type HouseRequest struct {
+	Name string `json:"name" example:"Kase 12"`
+	// users who are added into the house besides the maker, only used when making the house
+	RoommateIDs []string `json:"roommate_ids" example:"0b1d2c4e-8a51-4f5e-a6f5-6c2f0e4b7a10"`
+}
+
+type HouseMemberRequest struct {
+	UserID string `json:"user_id" binding:"required" example:"0b1d2c4e-8a51-4f5e-a6f5-6c2f0e4b7a10"`
+}
+
+type HouseResponse struct {
+	ID      string `json:"id" example:"5f0c3c1e-2b7d-4a53-9d0e-1c2b3a4d5e6f"`
+	Name    string `json:"name" example:"Kase 12"`
+	MakerID string `json:"maker_id" example:"0b1d2c4e-8a51-4f5e-a6f5-6c2f0e4b7a10"`
+}
+
+type HouseMemberResponse struct {
+	ID       string `json:"id" example:"0b1d2c4e-8a51-4f5e-a6f5-6c2f0e4b7a10"`
+	Username string `json:"username" example:"mari"`
+}
+
+type HouseDetailsResponse struct {
+	HouseResponse
+	Members []HouseMemberResponse `json:"members"`
+}
+
+func newHouseMemberResponses(roommates []dbqueries.SelectHouseRoommatesRow) []HouseMemberResponse {
+	members := make([]HouseMemberResponse, 0, len(roommates))
+	for _, roommate := range roommates {
+		members = append(members, HouseMemberResponse{
+			ID:       roommate.ID.String(),
+			Username: roommate.Username,
+		})
+	}
+	return members
+}
+
+// house with its members
+//
+//	string -- ETag of the response
+func (c *Controller) houseDetails(ctx *gin.Context, houseID pgtype.UUID) (HouseDetailsResponse, string, error) {
+	house, err := c.DB.SelectHouse(ctx, houseID)
+	if err != nil {
+		return HouseDetailsResponse{}, "", err
+	}
+	roommates, err := c.DB.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		return HouseDetailsResponse{}, "", err
+	}
+	return HouseDetailsResponse{
+		HouseResponse: HouseResponse{
+			ID:      house.ID.String(),
+			Name:    house.Name,
+			MakerID: house.MakerID.String(),
+		},
+		Members: newHouseMemberResponses(roommates),
+	}, houseETag(house, roommates), nil
+}
+
+// APIListHouses godoc
+//
+//	@Summary      Houses
+//	@Description  Houses the user lives in. The Link header points to the next page when there is one
+//	@Tags         houses
+//
+//	@Param  limit         query  int     false  "Houses on a page, at most 100"  default(20)
+//	@Param  cursor        query  string  false  "Cursor from the Link header of the previous page"
+//	@Param  sort          query  string  false  "Sort by name or created_at, '-' in front sorts descending"  default(name)
+//	@Param  filter[name]  query  string  false  "Part of the name"
+//
+//	@Produce  json
+//	@Success  200  {array}   HouseResponse
+//	@Header   200  {string}  Link  "Next page"
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses [get]
+func (c *Controller) APIListHouses(ctx *gin.Context) {
+	page, ok := requirePage(ctx, housesResource)
+	if !ok {
+		return
+	}
+	houses, next, err := c.housesPage(ctx, middleware.GetAuthInfo(ctx).UserID, page)
+	if err != nil {
+		handlePageError(ctx, err, "could not get houses")
+		return
+	}
+
+	res := make([]HouseResponse, 0, len(houses))
+	for _, house := range houses {
+		res = append(res, HouseResponse{
+			ID:      house.ID.String(),
+			Name:    house.Name,
+			MakerID: house.MakerID.String(),
+		})
+	}
+	pagination.SetLinkHeader(ctx.Writer.Header(), ctx.Request.URL, next)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// APICreateHouse godoc
+//
+//	@Summary      Make a house
+//	@Description  Makes a house with the user and the roommates in it, requires a verified email.
+//	@Description  Retries with the same Idempotency-Key header get the first response instead of making another house
+//	@Tags         houses
+//
+//	@Accept  json
+//	@Param    Idempotency-Key  header  string        false  "Unique key of the request, kept for 24 hours"
+//	@Param    House            body    HouseRequest  true   "Name and roommates of the house"
+//
+//	@Produce  json
+//	@Success  201  {object}  HouseDetailsResponse
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  404  {object}  utils.HTTPError
+//	@Failure  409  {object}  utils.HTTPError
+//	@Failure  422  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses [post]
+func (c *Controller) APICreateHouse(ctx *gin.Context) {
+	var req HouseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	model := models.House{Name: req.Name}
+	if isValid, msgs := model.IsValid(); !isValid {
+		validationErrorResponse(ctx, msgs)
+		return
+	}
+	roommateIDs, ok := requireUserIDs(ctx, req.RoommateIDs)
+	if !ok {
+		return
+	}
+
+	blocking, err := blockingRoommates(ctx, c.DB, middleware.GetAuthInfo(ctx).UserID, roommateIDs, nil)
+	if err != nil {
+		HandleServerError(ctx, err, "could not check blocked users")
+		return
+	}
+	if len(blocking) > 0 {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorUserBlocked)
+		return
+	}
+
+	houseID, err := c.createHouse(ctx, model.Name, roommateIDs)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorUserNotFound)
+			return
+		}
+		HandleServerError(ctx, err, "unable to create this house")
+		return
+	}
+
+	res, tag, err := c.houseDetails(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get house")
+		return
+	}
+	ctx.Header(string(g.HETag), tag)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// APIGetHouse godoc
+//
+//	@Summary      House
+//	@Description  House with its members, only for residents
+//	@Tags         houses
+//
+//	@Param  id             path    string  true   "ID of the house"
+//	@Param  If-None-Match  header  string  false  "ETag of the house the client already has"
+//
+//	@Produce  json
+//	@Success  200  {object}  HouseDetailsResponse
+//	@Header   200  {string}  ETag  "Version of the house with its members"
+//	@Success  304
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses/{id} [get]
+func (c *Controller) APIGetHouse(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+
+	res, tag, err := c.houseDetails(ctx, *houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get house")
+		return
+	}
+	if isNotModified(ctx, tag) {
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// APIUpdateHouse godoc
+//
+//	@Summary      Rename a house
+//	@Description  Changes the name of the house, only for the maker of the house. Members are changed with their own endpoints
+//	@Tags         houses
+//
+//	@Accept  json
+//	@Param    id        path    string        true   "ID of the house"
+//	@Param    If-Match  header  string        false  "ETag of the house, the house is not changed when it does not match"
+//	@Param    House     body    HouseRequest  true   "New name of the house, roommates are ignored"
+//
+//	@Produce  json
+//	@Success  200  {object}  HouseDetailsResponse
+//	@Header   200  {string}  ETag  "Version of the house with its members"
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  412  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses/{id} [put]
+func (c *Controller) APIUpdateHouse(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if isMaker := isHouseMaker(ctx, c.DB, *houseID); !isMaker {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+		return
+	}
+
+	var req HouseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+	model := models.House{Name: req.Name}
+	if isValid, msgs := model.IsValid(); !isValid {
+		validationErrorResponse(ctx, msgs)
+		return
+	}
+
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		HandleServerError(ctx, err, "could not update house")
+		return
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+	if err = checkHouseMatch(ctx, qtx, *houseID); err != nil {
+		handleConditionalWriteError(ctx, err, "could not update house")
+		return
+	}
+	err = qtx.UpdateHouse(ctx, dbqueries.UpdateHouseParams{
+		Name: model.Name,
+		ID:   *houseID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not update house")
+		return
+	}
+	if err = tx.Commit(ctx); err != nil {
+		HandleServerError(ctx, err, "could not update house")
+		return
+	}
+
+	res, tag, err := c.houseDetails(ctx, *houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get house")
+		return
+	}
+	ctx.Header(string(g.HETag), tag)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// APIDeleteHouse godoc
+//
+//	@Summary      Delete a house
+//	@Description  Deletes the house with its notes, payments and reminders, only for the maker of the house
+//	@Tags         houses
+//
+//	@Param  id        path    string  true   "ID of the house"
+//	@Param  If-Match  header  string  false  "ETag of the house, the house is not deleted when it does not match"
+//
+//	@Produce  json
+//	@Success  204
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  412  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses/{id} [delete]
+func (c *Controller) APIDeleteHouse(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if isMaker := isHouseMaker(ctx, c.DB, *houseID); !isMaker {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+		return
+	}
+
+	if err := c.deleteHouse(ctx, *houseID); err != nil {
+		handleConditionalWriteError(ctx, err, "could not delete house")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// APIListHouseMembers godoc
+//
+//	@Summary      House members
+//	@Description  Users living in the house, only for residents
+//	@Tags         houses
+//
+//	@Param  id  path  string  true  "ID of the house"
+//
+//	@Produce  json
+//	@Success  200  {array}   HouseMemberResponse
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses/{id}/members [get]
+func (c *Controller) APIListHouseMembers(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+
+	roommates, err := c.DB.SelectHouseRoommates(ctx, *houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+	ctx.JSON(http.StatusOK, newHouseMemberResponses(roommates))
+}
+
+// changes members of the house inside of a transaction and records the changes into the house activity
+//
+// responds with the members after the change
+func (c *Controller) changeHouseMembers(ctx *gin.Context, houseID pgtype.UUID, change func(q *dbqueries.Queries, residents []dbqueries.SelectHouseRoommatesRow) bool) {
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		HandleServerError(ctx, err, "no business pool party :(")
+		return
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+	// members are a part of the house ETag, conditional writes of the house wait for the change
+	if _, err = qtx.LockHouse(ctx, houseID); err != nil {
+		HandleServerError(ctx, err, "could not get house")
+		return
+	}
+
+	residentsBefore, err := qtx.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+	if ok := change(qtx, residentsBefore); !ok {
+		return
+	}
+	residentsAfter, err := qtx.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+	if err = recordMembershipChanges(ctx, qtx, houseID, residentsBefore, residentsAfter); err != nil {
+		HandleServerError(ctx, err, "error recording house activity")
+		return
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		HandleServerError(ctx, err, "error commiting transaction")
+		return
+	}
+	ctx.JSON(http.StatusOK, newHouseMemberResponses(residentsAfter))
+}
+
+// APIAddHouseMember godoc
+//
+//	@Summary      Add a member
+//	@Description  Adds a user into the house, only for the maker of the house. Requires a verified email
+//	@Tags         houses
+//
+//	@Accept  json
+//	@Param    id      path  string              true  "ID of the house"
+//	@Param    Member  body  HouseMemberRequest  true  "User to add"
+//
+//	@Produce  json
+//	@Success  200  {array}   HouseMemberResponse
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  404  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses/{id}/members [post]
+func (c *Controller) APIAddHouseMember(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if isMaker := isHouseMaker(ctx, c.DB, *houseID); !isMaker {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+		return
+	}
+
+	var req HouseMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+	userIDs, ok := requireUserIDs(ctx, []string{req.UserID})
+	if !ok {
+		return
+	}
+
+	authInfo := middleware.GetAuthInfo(ctx)
+	c.changeHouseMembers(ctx, *houseID, func(q *dbqueries.Queries, residents []dbqueries.SelectHouseRoommatesRow) bool {
+		blocking, err := blockingRoommates(ctx, q, authInfo.UserID, userIDs, residents)
+		if err != nil {
+			HandleServerError(ctx, err, "could not check blocked users")
+			return false
+		}
+		if len(blocking) > 0 {
+			utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorUserBlocked)
+			return false
+		}
+
+		if err = insertUsersToHouse(ctx, q, userIDs, *houseID); err != nil {
+			if isForeignKeyViolation(err) {
+				utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorUserNotFound)
+				return false
+			}
+			HandleServerError(ctx, err, "error assigning users to house")
+			return false
+		}
+		return true
+	})
+}
+
+// APIRemoveHouseMember godoc
+//
+//	@Summary      Remove a member
+//	@Description  Removes a user from the house, only for the maker of the house. The maker can not be removed
+//	@Tags         houses
+//
+//	@Param  id       path  string  true  "ID of the house"
+//	@Param  user_id  path  string  true  "ID of the user"
+//
+//	@Produce  json
+//	@Success  200  {array}   HouseMemberResponse
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  404  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses/{id}/members/{user_id} [delete]
+func (c *Controller) APIRemoveHouseMember(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	userID := requirePgUUID(ctx, "user_id")
+	if userID == nil {
+		return
+	}
+	// the maker shall never be free
+	if isMaker := isHouseMaker(ctx, c.DB, *houseID); !isMaker || *userID == middleware.GetAuthInfo(ctx).UserID {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+		return
+	}
+
+	c.changeHouseMembers(ctx, *houseID, func(q *dbqueries.Queries, _ []dbqueries.SelectHouseRoommatesRow) bool {
+		deleted, err := q.DeleteHouseUser(ctx, dbqueries.DeleteHouseUserParams{
+			HouseID: *houseID,
+			UserID:  *userID,
+		})
+		if err != nil {
+			HandleServerError(ctx, err, "could not remove user from house")
+			return false
+		}
+		if deleted == 0 {
+			utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorNotHouseResident)
+			return false
+		}
+		return true
+	})
+}
@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	l "roommates/locales"
+	"roommates/middleware"
+	"roommates/pagination"
+	"roommates/utils"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+type MessageResponse struct {
+	ID      string `json:"id" example:"3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f"`
+	Content string `json:"content" example:"Tere!"`
+	// empty when the sender has deleted the account
+	SenderID       string `json:"sender_id" example:"0b1d2c4e-8a51-4f5e-a6f5-6c2f0e4b7a10"`
+	SenderUsername string `json:"sender_username" example:"mari"`
+	// message whose chat command this answers, answers have no sender
+	ReplyToID string `json:"reply_to_id,omitempty" example:"9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"`
+	CreatedAt string `json:"created_at" example:"2025-01-31T12:00:00Z"`
+	UpdatedAt string `json:"updated_at" example:"2025-01-31T12:00:00Z"`
+}
+
+// whether the user takes part in the conversation,
+// recipients of a house conversation are the residents of the houses in it
+func isConversationRecipient(ctx *gin.Context, q *dbqueries.Queries, conversation dbqueries.Conversation) bool {
+	if conversation.RecipientType != dbqueries.ConversationRecipientTypeHouse {
+		return slices.Contains(conversation.RecipientIds, middleware.GetAuthInfo(ctx).UserID.String())
+	}
+	_, ok := conversationHouseID(ctx, q, conversation)
+	return ok
+}
+
+// conversation from the uri, responds with an error when it does not exist
+// or the user does not take part in it
+func (c *Controller) requireConversation(ctx *gin.Context) (dbqueries.Conversation, bool) {
+	conversationID := requirePgUUID(ctx, "id")
+	if conversationID == nil {
+		return dbqueries.Conversation{}, false
+	}
+	conversation, err := c.DB.SelectConversation(ctx, *conversationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorNotFound)
+			return conversation, false
+		}
+		HandleServerError(ctx, err, "could not get conversation")
+		return conversation, false
+	}
+	if !isConversationRecipient(ctx, c.DB, conversation) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotConversationRecipient)
+		return conversation, false
+	}
+	return conversation, true
+}
+
+// house of a house conversation the user lives in
+//
+//	bool == false -- not a house conversation or the user does not live in any of its houses
+func conversationHouseID(ctx *gin.Context, q *dbqueries.Queries, conversation dbqueries.Conversation) (pgtype.UUID, bool) {
+	var houseID pgtype.UUID
+	if conversation.RecipientType != dbqueries.ConversationRecipientTypeHouse {
+		return houseID, false
+	}
+	for _, id := range conversation.RecipientIds {
+		if err := houseID.Scan(id); err == nil && isHouseResident(ctx, q, houseID) {
+			return houseID, true
+		}
+	}
+	return pgtype.UUID{}, false
+}
+
+// APIListConversationMessages godoc
+//
+//	@Summary      Conversation messages
+//	@Description  Messages of the conversation, newest first. Only for the recipients. The Link header points to the next page when there is one
+//	@Tags         messages
+//
+//	@Param  id                 path   string  true   "ID of the conversation"
+//	@Param  limit              query  int     false  "Messages on a page, at most 100"  default(20)
+//	@Param  cursor             query  string  false  "Cursor from the Link header of the previous page"
+//	@Param  sort               query  string  false  "Sort by created_at, '-' in front sorts descending"  default(-created_at)
+//	@Param  filter[sender_id]  query  string  false  "ID of the sender"
+//
+//	@Produce  json
+//	@Success  200  {array}   MessageResponse
+//	@Header   200  {string}  Link  "Next page"
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  404  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/conversations/{id}/messages [get]
+func (c *Controller) APIListConversationMessages(ctx *gin.Context) {
+	conversation, ok := c.requireConversation(ctx)
+	if !ok {
+		return
+	}
+	page, ok := requirePage(ctx, messagesResource)
+	if !ok {
+		return
+	}
+
+	messages, next, err := c.messagesPage(ctx, conversation.ID, page)
+	if err != nil {
+		handlePageError(ctx, err, "could not get messages")
+		return
+	}
+
+	res := make([]MessageResponse, 0, len(messages))
+	for _, message := range messages {
+		var senderID, senderUsername string
+		if message.SenderID.Valid {
+			senderID = message.SenderID.String()
+		}
+		if message.SenderUsername != nil {
+			senderUsername = *message.SenderUsername
+		}
+		var replyToID string
+		if message.ReplyToID.Valid {
+			replyToID = message.ReplyToID.String()
+		}
+		res = append(res, MessageResponse{
+			ID:             message.ID.String(),
+			Content:        message.Content,
+			SenderID:       senderID,
+			SenderUsername: senderUsername,
+			ReplyToID:      replyToID,
+			CreatedAt:      formatTime(message.CreatedAt),
+			UpdatedAt:      formatTime(message.UpdatedAt),
+		})
+	}
+	pagination.SetLinkHeader(ctx.Writer.Header(), ctx.Request.URL, next)
+	ctx.JSON(http.StatusOK, res)
+}
+
+type SendMessageRequest struct {
+	Content string `json:"content" example:"/owe 12.50 pizza @mari"`
+}
+
+type SendMessageResponse struct {
+	Message MessageResponse `json:"message"`
+	// answer of the chat command in the message, null when it was not a command
+	Reply *MessageResponse `json:"reply"`
+	// where the answer points to, empty when nowhere
+	Link string `json:"link,omitempty" example:"/payments"`
+}
+
+func replyText(ctx *gin.Context, msgs []l.LKMessage) string {
+	texts := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		texts = append(texts, utils.T(ctx.Request.Context(), msg.Key, "", msg.Args...))
+	}
+	return strings.Join(texts, "\n")
+}
+
+// APISendConversationMessage godoc
+//
+//	@Summary      Send a message
+//	@Description  Sends a message into the conversation, only for the recipients.
+//	@Description  Slash-commands in house conversations are run and their answer is posted as a reply without a sender, /help lists the commands.
+//	@Description  Commands need the scopes of what they do, /owe needs write:payments and a verified email.
+//	@Description  Retries with the same Idempotency-Key header get the first response instead of sending the message again
+//	@Tags         messages
+//
+//	@Accept  json
+//	@Param    id               path    string              true   "ID of the conversation"
+//	@Param    Idempotency-Key  header  string              false  "Unique key of the request, kept for 24 hours"
+//	@Param    Message          body    SendMessageRequest  true   "Message"
+//
+//	@Produce  json
+//	@Success  201  {object}  SendMessageResponse
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  404  {object}  utils.HTTPError
+//	@Failure  409  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/conversations/{id}/messages [post]
+func (c *Controller) APISendConversationMessage(ctx *gin.Context) {
+	conversation, ok := c.requireConversation(ctx)
+	if !ok {
+		return
+	}
+
+	var req SendMessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Content == "" {
+		validationErrorResponse(ctx, []l.LKMessage{{Key: l.LKFormsContentErrorEmpty}})
+		return
+	}
+
+	sent, err := c.sendMessage(ctx, conversation, req.Content)
+	if err != nil {
+		HandleServerError(ctx, err, "could not send message")
+		return
+	}
+
+	authInfo := middleware.GetAuthInfo(ctx)
+	res := SendMessageResponse{
+		Message: MessageResponse{
+			ID:             sent.Message.ID.String(),
+			Content:        sent.Message.Content,
+			SenderID:       authInfo.UserID.String(),
+			SenderUsername: authInfo.Username,
+			CreatedAt:      formatTime(sent.Message.CreatedAt),
+			UpdatedAt:      formatTime(sent.Message.UpdatedAt),
+		},
+		Link: sent.Link,
+	}
+	if sent.Reply != nil {
+		res.Reply = &MessageResponse{
+			ID:        sent.Reply.ID.String(),
+			Content:   sent.Reply.Content,
+			ReplyToID: sent.Message.ID.String(),
+			CreatedAt: formatTime(sent.Reply.CreatedAt),
+			UpdatedAt: formatTime(sent.Reply.UpdatedAt),
+		}
+	}
+	ctx.JSON(http.StatusCreated, res)
+}
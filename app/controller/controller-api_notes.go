@@ -0,0 +1,311 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/models"
+	"roommates/pagination"
+	"roommates/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+type NoteRequest struct {
+	Title   string `json:"title" example:"Prügi"`
+	Content string `json:"content" example:"Prügi viiakse välja teisipäeviti"`
+}
+
+type NoteResponse struct {
+	ID      int32  `json:"id" example:"1"`
+	HouseID string `json:"house_id" example:"5f0c3c1e-2b7d-4a53-9d0e-1c2b3a4d5e6f"`
+	MakerID string `json:"maker_id" example:"0b1d2c4e-8a51-4f5e-a6f5-6c2f0e4b7a10"`
+	Title   string `json:"title" example:"Prügi"`
+	Content string `json:"content" example:"Prügi viiakse välja teisipäeviti"`
+}
+
+func newNoteResponse(note dbqueries.SelectNoteRow) NoteResponse {
+	return NoteResponse{
+		ID:      note.NoteID,
+		HouseID: note.HouseID.String(),
+		MakerID: note.MakerID.String(),
+		Title:   note.Title,
+		Content: note.Content,
+	}
+}
+
+// intended to be used with the API note routes
+type ReqNoteID struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+// note from the uri, responds with 404 when it does not exist
+func (c *Controller) requireNote(ctx *gin.Context) (dbqueries.SelectNoteRow, bool) {
+	var req ReqNoteID
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return dbqueries.SelectNoteRow{}, false
+	}
+
+	note, err := c.DB.SelectNote(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorNotFound)
+			return note, false
+		}
+		HandleServerError(ctx, err, "could not get note")
+		return note, false
+	}
+	return note, true
+}
+
+// binds and validates the note, will respond when it is not valid
+func bindNoteModel(ctx *gin.Context) (models.Note, bool) {
+	var req NoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return models.Note{}, false
+	}
+
+	model := models.Note{Title: req.Title, Content: req.Content}
+	if isValid, msgs := model.IsValid(); !isValid {
+		validationErrorResponse(ctx, msgs)
+		return model, false
+	}
+	return model, true
+}
+
+// APIListHouseNotes godoc
+//
+//	@Summary      House notes
+//	@Description  Notes of the house, last changed first. Only for residents. The Link header points to the next page when there is one
+//	@Tags         notes
+//
+//	@Param  id                path   string  true   "ID of the house"
+//	@Param  limit             query  int     false  "Notes on a page, at most 100"  default(20)
+//	@Param  cursor            query  string  false  "Cursor from the Link header of the previous page"
+//	@Param  sort              query  string  false  "Sort by title or updated_at, '-' in front sorts descending"  default(-updated_at)
+//	@Param  filter[title]     query  string  false  "Part of the title"
+//	@Param  filter[maker_id]  query  string  false  "ID of the maker of the note"
+//
+//	@Produce  json
+//	@Success  200  {array}   NoteResponse
+//	@Header   200  {string}  Link  "Next page"
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses/{id}/notes [get]
+func (c *Controller) APIListHouseNotes(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+	page, ok := requirePage(ctx, notesResource)
+	if !ok {
+		return
+	}
+
+	notes, next, err := c.notesPage(ctx, *houseID, page)
+	if err != nil {
+		handlePageError(ctx, err, "could not get notes")
+		return
+	}
+
+	res := make([]NoteResponse, 0, len(notes))
+	for _, note := range notes {
+		res = append(res, NoteResponse{
+			ID:      note.NoteID,
+			HouseID: note.HouseID.String(),
+			MakerID: note.MakerID.String(),
+			Title:   note.Title,
+			Content: note.Content,
+		})
+	}
+	pagination.SetLinkHeader(ctx.Writer.Header(), ctx.Request.URL, next)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// APICreateNote godoc
+//
+//	@Summary      Make a note
+//	@Description  Adds a note into the house, only for residents.
+//	@Description  Retries with the same Idempotency-Key header get the first response instead of making another note
+//	@Tags         notes
+//
+//	@Accept  json
+//	@Param    id               path    string       true   "ID of the house"
+//	@Param    Idempotency-Key  header  string       false  "Unique key of the request, kept for 24 hours"
+//	@Param    Note             body    NoteRequest  true   "Title and content of the note"
+//
+//	@Produce  json
+//	@Success  201  {object}  NoteResponse
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  409  {object}  utils.HTTPError
+//	@Failure  422  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses/{id}/notes [post]
+func (c *Controller) APICreateNote(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+
+	model, ok := bindNoteModel(ctx)
+	if !ok {
+		return
+	}
+
+	noteID, err := c.createNote(ctx, *houseID, model)
+	if err != nil {
+		HandleServerError(ctx, err, "could not save note")
+		return
+	}
+	note, err := c.DB.SelectNote(ctx, noteID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get note")
+		return
+	}
+	ctx.Header(string(g.HETag), noteETag(note))
+	ctx.JSON(http.StatusCreated, newNoteResponse(note))
+}
+
+// APIGetNote godoc
+//
+//	@Summary      Note
+//	@Description  Note of a house, only for residents
+//	@Tags         notes
+//
+//	@Param  id             path    int     true   "ID of the note"
+//	@Param  If-None-Match  header  string  false  "ETag of the note the client already has"
+//
+//	@Produce  json
+//	@Success  200  {object}  NoteResponse
+//	@Header   200  {string}  ETag  "Version of the note"
+//	@Success  304
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  404  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/notes/{id} [get]
+func (c *Controller) APIGetNote(ctx *gin.Context) {
+	note, ok := c.requireNote(ctx)
+	if !ok {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, note.HouseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+	if isNotModified(ctx, noteETag(note)) {
+		return
+	}
+	ctx.JSON(http.StatusOK, newNoteResponse(note))
+}
+
+// APIUpdateNote godoc
+//
+//	@Summary      Change a note
+//	@Description  Changes title and content of the note, only for the maker of the note
+//	@Tags         notes
+//
+//	@Accept  json
+//	@Param    id        path    int          true   "ID of the note"
+//	@Param    If-Match  header  string       false  "ETag of the note, the note is not changed when it does not match"
+//	@Param    Note      body    NoteRequest  true   "New title and content of the note"
+//
+//	@Produce  json
+//	@Success  200  {object}  NoteResponse
+//	@Header   200  {string}  ETag  "Version of the note"
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  404  {object}  utils.HTTPError
+//	@Failure  412  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/notes/{id} [put]
+func (c *Controller) APIUpdateNote(ctx *gin.Context) {
+	note, ok := c.requireNote(ctx)
+	if !ok {
+		return
+	}
+	if isMaker := isNoteMaker(ctx, c.DB, note.NoteID); !isMaker {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+		return
+	}
+
+	model, ok := bindNoteModel(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.updateNote(ctx, note, model); err != nil {
+		handleConditionalWriteError(ctx, err, "could not update note")
+		return
+	}
+	note, err := c.DB.SelectNote(ctx, note.NoteID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get note")
+		return
+	}
+	ctx.Header(string(g.HETag), noteETag(note))
+	ctx.JSON(http.StatusOK, newNoteResponse(note))
+}
+
+// APIDeleteNote godoc
+//
+//	@Summary      Delete a note
+//	@Description  Deletes the note, only for the maker of the note
+//	@Tags         notes
+//
+//	@Param  id        path    int     true   "ID of the note"
+//	@Param  If-Match  header  string  false  "ETag of the note, the note is not deleted when it does not match"
+//
+//	@Produce  json
+//	@Success  204
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  404  {object}  utils.HTTPError
+//	@Failure  412  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/notes/{id} [delete]
+func (c *Controller) APIDeleteNote(ctx *gin.Context) {
+	note, ok := c.requireNote(ctx)
+	if !ok {
+		return
+	}
+	if isMaker := isNoteMaker(ctx, c.DB, note.NoteID); !isMaker {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+		return
+	}
+
+	if err := c.deleteNote(ctx, note); err != nil {
+		handleConditionalWriteError(ctx, err, "could not delete note")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
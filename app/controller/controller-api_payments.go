@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/pagination"
+	"roommates/utils"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+type PaymentRequest struct {
+	Name   string `json:"name" example:"Elekter"`
+	Amount string `json:"amount" example:"42.50"`
+	// residents of the house who have to pay their share
+	PayerIDs []string `json:"payer_ids" example:"0b1d2c4e-8a51-4f5e-a6f5-6c2f0e4b7a10"`
+	// optional, shown in the calendar feeds until the payment is paid
+	DueDate string `json:"due_date" example:"2025-02-15"`
+}
+
+type PaymentResponse struct {
+	ID          string `json:"id" example:"9a7e1c2d-3b4f-4e5a-8c6d-7e8f9a0b1c2d"`
+	Name        string `json:"name" example:"Elekter"`
+	Amount      string `json:"amount" example:"42.50"`
+	RequesterID string `json:"requester_id" example:"0b1d2c4e-8a51-4f5e-a6f5-6c2f0e4b7a10"`
+	// house id and name are empty when the payment is in no house
+	HouseID   string `json:"house_id" example:"5f0c3c1e-2b7d-4a53-9d0e-1c2b3a4d5e6f"`
+	HouseName string `json:"house_name" example:"Kase 12"`
+	// status of the share of the user, empty when the user only requested the payment
+	Status    string `json:"status" example:"incomplete" enums:"done,incomplete"`
+	CreatedAt string `json:"created_at" example:"2025-01-31T12:00:00Z"`
+	// empty when the payment has no due date
+	DueDate string `json:"due_date,omitempty" example:"2025-02-15"`
+}
+
+func newPaymentResponse(payment dbqueries.SelectUserPaymentRow) PaymentResponse {
+	response := PaymentResponse{
+		ID:          payment.ID.String(),
+		Name:        payment.PaymentName,
+		Amount:      payment.Amount,
+		RequesterID: payment.RequesterID.String(),
+		HouseID:     payment.HouseID.String(),
+		Status:      string(payment.PaymentStatus.HousePaymentStatus),
+		CreatedAt:   formatTime(payment.CreatedAt),
+		DueDate:     models.FormatDate(payment.DueDate),
+	}
+	if payment.HouseName != nil {
+		response.HouseName = *payment.HouseName
+	}
+	return response
+}
+
+// APIListPayments godoc
+//
+//	@Summary      Payments
+//	@Description  Payments the user has requested or has to pay, newest first. The Link header points to the next page when there is one
+//	@Tags         payments
+//
+//	@Param  limit             query  int     false  "Payments on a page, at most 100"  default(20)
+//	@Param  cursor            query  string  false  "Cursor from the Link header of the previous page"
+//	@Param  sort              query  string  false  "Sort by name or created_at, '-' in front sorts descending"  default(-created_at)
+//	@Param  filter[house_id]  query  string  false  "ID of the house"
+//	@Param  filter[status]    query  string  false  "Status of the share of the user"  Enums(done, incomplete)
+//
+//	@Produce  json
+//	@Success  200  {array}   PaymentResponse
+//	@Header   200  {string}  Link  "Next page"
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/payments [get]
+func (c *Controller) APIListPayments(ctx *gin.Context) {
+	page, ok := requirePage(ctx, paymentsResource)
+	if !ok {
+		return
+	}
+	payments, next, err := c.paymentsPage(ctx, middleware.GetAuthInfo(ctx).UserID, page)
+	if err != nil {
+		handlePageError(ctx, err, "could not get payments")
+		return
+	}
+
+	res := make([]PaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		res = append(res, newPaymentResponse(dbqueries.SelectUserPaymentRow(payment)))
+	}
+	pagination.SetLinkHeader(ctx.Writer.Header(), ctx.Request.URL, next)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// APIGetPayment godoc
+//
+//	@Summary      Payment
+//	@Description  Payment the user has requested or has to pay
+//	@Tags         payments
+//
+//	@Param  id             path    string  true   "ID of the payment"
+//	@Param  If-None-Match  header  string  false  "ETag of the payment the client already has"
+//
+//	@Produce  json
+//	@Success  200  {object}  PaymentResponse
+//	@Header   200  {string}  ETag  "Version of the payment"
+//	@Success  304
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  404  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/payments/{id} [get]
+func (c *Controller) APIGetPayment(ctx *gin.Context) {
+	paymentID := requirePgUUID(ctx, "id")
+	if paymentID == nil {
+		return
+	}
+
+	payment, err := c.DB.SelectUserPayment(ctx, dbqueries.SelectUserPaymentParams{
+		UserID:    middleware.GetAuthInfo(ctx).UserID,
+		PaymentID: *paymentID,
+	})
+	if err != nil {
+		// payments of others are not found either
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorNotFound)
+			return
+		}
+		HandleServerError(ctx, err, "could not get payment")
+		return
+	}
+	if isNotModified(ctx, paymentETag(payment)) {
+		return
+	}
+	ctx.JSON(http.StatusOK, newPaymentResponse(payment))
+}
+
+// APICreatePayment godoc
+//
+//	@Summary      Request a payment
+//	@Description  Requests a payment from residents of the house, requires a verified email.
+//	@Description  Retries with the same Idempotency-Key header get the first response instead of making another payment
+//	@Tags         payments
+//
+//	@Accept  json
+//	@Param    id               path    string          true   "ID of the house"
+//	@Param    Idempotency-Key  header  string          false  "Unique key of the request, kept for 24 hours"
+//	@Param    Payment          body    PaymentRequest  true   "Name, amount and payers of the payment"
+//
+//	@Produce  json
+//	@Success  201  {object}  PaymentResponse
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  403  {object}  utils.HTTPError
+//	@Failure  409  {object}  utils.HTTPError
+//	@Failure  422  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Security  ApiKeyAuth
+//	@Router    /api/v1/houses/{id}/payments [post]
+func (c *Controller) APICreatePayment(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+
+	var req PaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+	model := models.Payment{
+		Name:   req.Name,
+		Amount: req.Amount,
+		// the same payer given twice is one payer
+		PayerIDs: slices.Compact(slices.Sorted(slices.Values(req.PayerIDs))),
+		DueDate:  req.DueDate,
+	}
+	if isValid, msgs := model.IsValid(); !isValid {
+		validationErrorResponse(ctx, msgs)
+		return
+	}
+	if _, ok := requireUserIDs(ctx, req.PayerIDs); !ok {
+		return
+	}
+
+	residents, err := c.DB.SelectHouseRoommates(ctx, *houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+	payerIDs := filterPayers(model.PayerIDs, residents)
+	if len(payerIDs) != len(model.PayerIDs) {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, g.ErrorPayerNotResident)
+		return
+	}
+
+	paymentID, err := c.createPayment(ctx, *houseID, model, payerIDs)
+	if err != nil {
+		HandleServerError(ctx, err, "could not save payment")
+		return
+	}
+	payment, err := c.DB.SelectUserPayment(ctx, dbqueries.SelectUserPaymentParams{
+		UserID:    middleware.GetAuthInfo(ctx).UserID,
+		PaymentID: paymentID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not get payment")
+		return
+	}
+	ctx.Header(string(g.HETag), paymentETag(payment))
+	ctx.JSON(http.StatusCreated, newPaymentResponse(payment))
+}
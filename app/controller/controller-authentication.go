@@ -1,18 +1,23 @@
 package controller
 
 import (
+	"math"
 	"net/http"
 	"roommates/components"
 	"roommates/db/dbqueries"
+	"roommates/gintemplrenderer"
 	g "roommates/globals"
 	"roommates/locales"
 	"roommates/middleware"
 	"roommates/models"
 	"roommates/rdb"
 	"roommates/utils"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/invopop/ctxi18n"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -20,35 +25,159 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func (c *Controller) shouldUserBeSignedIn(ctx *gin.Context, req SignInRequest) (*dbqueries.GetUserCredentialsRow, error) {
-	credsInDb, err := c.DB.GetUserCredentials(ctx, req.Email)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, g.ErrorInvalidCredential
+// repeated failed sign-ins from the same address lock the account out there for longer and longer
+var signInLockout = rdb.Lockout{
+	Threshold: 5,
+	Base:      time.Minute,
+	Max:       time.Hour,
+	Window:    24 * time.Hour,
+}
+
+// failures from every address together, stops guessing spread over many addresses
+//
+// much higher than signInLockout so that someone failing on purpose
+// does not lock the owner out as easily
+var signInAccountLockout = rdb.Lockout{
+	Threshold: 50,
+	Base:      15 * time.Minute,
+	Max:       24 * time.Hour,
+	Window:    24 * time.Hour,
+}
+
+// failures are counted for emails without an account as well,
+// that way the lockout does not tell which accounts exist
+func signInAccountLockoutKey(email string) string {
+	return "sign-in:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func signInLockoutKey(email, ip string) string {
+	return signInAccountLockoutKey(email) + ":" + ip
+}
+
+// when the account is locked out the error is ErrorLockedOut and the duration is how long it still is
+func (c *Controller) shouldUserBeSignedIn(ctx *gin.Context, req SignInRequest) (*dbqueries.GetUserCredentialsRow, time.Duration, error) {
+	lockouts := []struct {
+		rule rdb.Lockout
+		key  string
+	}{
+		{signInLockout, signInLockoutKey(req.Email, ctx.ClientIP())},
+		{signInAccountLockout, signInAccountLockoutKey(req.Email)},
+	}
+	for _, lockout := range lockouts {
+		wait, err := c.RH.LockedOut(ctx, lockout.key)
+		if err != nil {
+			return nil, 0, err
+		}
+		if wait > 0 {
+			return nil, wait, g.ErrorLockedOut
 		}
-		return nil, err
+	}
+
+	credsInDb, err := c.DB.GetUserCredentials(ctx, req.Email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, err
 	}
 
 	// no need to check email as it's used to get stored credentials
-	if err = bcrypt.CompareHashAndPassword([]byte(credsInDb.Password),
-		[]byte(req.Password)); err != nil {
-		return nil, g.ErrorInvalidCredential
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(credsInDb.Password),
+		[]byte(req.Password)) != nil {
+		for _, lockout := range lockouts {
+			if _, err = c.RH.RecordFailure(ctx, lockout.rule, lockout.key); err != nil {
+				return nil, 0, err
+			}
+		}
+		return nil, 0, g.ErrorInvalidCredential
 	}
 
-	return &credsInDb, nil
+	for _, lockout := range lockouts {
+		if err = c.RH.ResetFailures(ctx, lockout.key); err != nil {
+			return nil, 0, err
+		}
+	}
+	return &credsInDb, 0, nil
+}
+
+// response for requests stopped by the rate limiter or the sign-in lockout
+//
+// login and register forms are rendered again with the error, htmx would not swap a 429
+func (c *Controller) RateLimited(ctx *gin.Context, wait time.Duration) {
+	message := utils.T(
+		ctx.Request.Context(),
+		locales.LKFormsErrorTooManyRequests,
+		"Too many attempts, try again in %d minutes",
+		int(math.Ceil(wait.Minutes())),
+	)
+
+	status := http.StatusTooManyRequests
+	if utils.IsRequestHTMX(ctx) {
+		status = http.StatusOK
+	}
+
+	switch ctx.FullPath() {
+	case g.RLogin:
+		var model models.Login
+		ctx.ShouldBind(&model)
+		model.Initial = true
+		model.Password = ""
+		model.Error = message
+		r := gintemplrenderer.New(ctx.Request.Context(), status, components.PageLogin(model, c.OIDC.List()))
+		ctx.Render(r.Status, r)
+	case g.RRegister:
+		var model models.Register
+		ctx.ShouldBind(&model)
+		model.Initial = true
+		model.Password = ""
+		model.Password2 = ""
+		model.Error = message
+		r := gintemplrenderer.New(ctx.Request.Context(), status, components.PageRegister(model))
+		ctx.Render(r.Status, r)
+	case g.RForgotPassword:
+		var model models.ForgotPassword
+		ctx.ShouldBind(&model)
+		model.Initial = true
+		model.Error = message
+		r := gintemplrenderer.New(ctx.Request.Context(), status, components.PageForgotPassword(model))
+		ctx.Render(r.Status, r)
+	case g.RLoginTwoFactor:
+		var model models.TwoFactorCode
+		ctx.ShouldBind(&model)
+		model.Initial = true
+		model.Code = ""
+		model.Error = message
+		r := gintemplrenderer.New(ctx.Request.Context(), status, components.PageLoginTwoFactor(model))
+		ctx.Render(r.Status, r)
+	default:
+		// htmx shows the text of a 429 as a notification, see HeaderComponent
+		if utils.IsRequestHTMX(ctx) {
+			ctx.String(http.StatusTooManyRequests, message)
+			return
+		}
+		utils.ErrorResponse(ctx, http.StatusTooManyRequests, errors.New(message))
+	}
 }
 
 func (c *Controller) signUserIn(ctx *gin.Context, sessionValue rdb.UserSessionValue) uuid.UUID {
+	sessionValue.IP = ctx.ClientIP()
+	sessionValue.UserAgent = ctx.Request.UserAgent()
+	// not worth failing the sign-in over, initials are shown instead
+	if avatarKey, err := c.DB.SelectUserAvatarKey(ctx, sessionValue.UserID); err != nil {
+		log.Error().Err(err).Caller().Msg("could not get avatar key")
+	} else if avatarKey != nil {
+		sessionValue.AvatarKey = *avatarKey
+	}
+	// emails sent outside of requests are written in the language of the last sign-in
+	if locale := ctxi18n.Locale(ctx.Request.Context()); locale != nil {
+		code := string(locale.Code())
+		err := c.DB.UpdateUserLocale(ctx, dbqueries.UpdateUserLocaleParams{
+			Locale: &code,
+			ID:     sessionValue.UserID,
+		})
+		if err != nil {
+			log.Error().Err(err).Caller().Msg("could not save locale")
+		}
+	}
 	token := c.RH.CreateUserSession(ctx, sessionValue)
-	ctx.SetCookie(
-		string(g.CSessionToken),
-		token.String(),
-		int(rdb.EUserSession.Seconds()),
-		"/",
-		"localhost",
-		true,
-		true,
-	)
+	middleware.SetSessionCookie(ctx, token.String())
 	return token
 }
 
@@ -58,7 +187,7 @@ func (c *Controller) signUserIn(ctx *gin.Context, sessionValue rdb.UserSessionVa
 //   - if error is nil and string is empty then response has been sent
 //   - response has not been sent if error is ErrorAccountAlreadyExists
 func (c *Controller) registerUser(ctx *gin.Context, user dbqueries.InsertUserParams) (pgtype.UUID, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), 12)
+	hashedPassword, err := hashPassword(user.Password)
 	if err != nil { // this should never be an issue
 		HandleServerError(ctx, err, "error processing password")
 		return pgtype.UUID{}, nil
@@ -67,7 +196,7 @@ func (c *Controller) registerUser(ctx *gin.Context, user dbqueries.InsertUserPar
 	userID, err := c.DB.InsertUser(ctx, dbqueries.InsertUserParams{
 		Email:    user.Email,
 		Username: user.Username,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 	})
 	if err != nil {
 		switch err := err.(type) {
@@ -92,8 +221,11 @@ type SignInRequest struct {
 	Password string `form:"password" json:"password" binding:"required"`
 }
 
+// when the user has two-factor enabled only TwoFactorToken is set,
+// the sign-in is finished with SignInTwoFactor
 type SignInResponse struct {
-	Token string `json:"token" binding:"required"`
+	Token          string `json:"token,omitempty"`
+	TwoFactorToken string `json:"two_factor_token,omitempty"`
 }
 
 // SignIn godoc
@@ -109,6 +241,7 @@ type SignInResponse struct {
 //	@Success  200  {object}  SignInResponse
 //	@Failure  400  {object}  utils.HTTPError
 //	@Failure  401  {object}  utils.HTTPError
+//	@Failure  429  {object}  utils.HTTPError
 //	@Failure  500  {object}  utils.HTTPError
 //
 //	@Security  ApiKeyAuth
@@ -120,16 +253,36 @@ func (c *Controller) SignIn(ctx *gin.Context) {
 		return
 	}
 
-	credsInDb, err := c.shouldUserBeSignedIn(ctx, req)
+	credsInDb, wait, err := c.shouldUserBeSignedIn(ctx, req)
 	if err != nil {
 		if errors.Is(err, g.ErrorInvalidCredential) {
 			utils.ErrorResponse(ctx, http.StatusUnauthorized, err)
 			return
 		}
+		if errors.Is(err, g.ErrorLockedOut) {
+			middleware.SetRetryAfter(ctx, wait)
+			c.RateLimited(ctx, wait)
+			return
+		}
 		HandleServerError(ctx, err, "error fetching user credentials")
 		return
 	}
 
+	twoFactor, err := c.DB.IsTwoFactorEnabled(ctx, credsInDb.ID)
+	if err != nil {
+		HandleServerError(ctx, err, "error checking two-factor")
+		return
+	}
+	if twoFactor {
+		twoFactorToken, err := c.startTwoFactor(ctx, credsInDb.ID, credsInDb.Username)
+		if err != nil {
+			HandleServerError(ctx, err, "error starting two-factor")
+			return
+		}
+		ctx.JSON(http.StatusOK, SignInResponse{TwoFactorToken: twoFactorToken})
+		return
+	}
+
 	token := c.signUserIn(ctx, rdb.UserSessionValue{
 		UserID:   credsInDb.ID,
 		Username: credsInDb.Username,
@@ -173,7 +326,7 @@ func (c *Controller) PageLogin(ctx *gin.Context) {
 
 	method := ctx.Request.Method
 	render := func(model models.Login) {
-		page := components.PageLogin(model)
+		page := components.PageLogin(model, c.OIDC.List())
 		RenderTempl(ctx, page)
 	}
 
@@ -190,10 +343,15 @@ func (c *Controller) PageLogin(ctx *gin.Context) {
 			return
 		}
 
-		credsInDb, err := c.shouldUserBeSignedIn(ctx, SignInRequest{
+		credsInDb, wait, err := c.shouldUserBeSignedIn(ctx, SignInRequest{
 			Email:    model.Email,
 			Password: model.Password,
 		})
+		if errors.Is(err, g.ErrorLockedOut) {
+			middleware.SetRetryAfter(ctx, wait)
+			c.RateLimited(ctx, wait)
+			return
+		}
 		if err != nil {
 			if errors.Is(err, g.ErrorInvalidCredential) {
 				model.Error = utils.T(
@@ -209,12 +367,7 @@ func (c *Controller) PageLogin(ctx *gin.Context) {
 			return
 		}
 
-		c.signUserIn(ctx, rdb.UserSessionValue{
-			UserID:   credsInDb.ID,
-			Username: credsInDb.Username,
-		})
-
-		utils.Redirect(ctx, "/")
+		c.signInOrStartTwoFactor(ctx, credsInDb.ID, credsInDb.Username)
 	default:
 		ctx.String(http.StatusMethodNotAllowed, "method %s not allowed", method)
 	}
@@ -268,6 +421,11 @@ func (c *Controller) PageRegister(ctx *gin.Context) {
 			return
 		}
 
+		// registration should not fail because of mail, resend is always available
+		if err = c.sendVerificationEmail(ctx, userID, model.Email); err != nil {
+			log.Error().Err(err).Msg("error sending verification email")
+		}
+
 		c.signUserIn(ctx, rdb.UserSessionValue{
 			UserID:   userID,
 			Username: model.Username,
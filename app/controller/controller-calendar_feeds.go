@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"roommates/accesstokens"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/ical"
+	"roommates/locales"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/rdb"
+	"roommates/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const calendarProductID = "-//Roommates//Calendar feed//ET"
+
+var reminderFrequencies = map[dbqueries.ReminderRecurrence]ical.Frequency{
+	dbqueries.ReminderRecurrenceDaily:   ical.Daily,
+	dbqueries.ReminderRecurrenceWeekly:  ical.Weekly,
+	dbqueries.ReminderRecurrenceMonthly: ical.Monthly,
+}
+
+func (c *Controller) renderCalendarFeeds(ctx *gin.Context, userID pgtype.UUID, created string) {
+	feeds, err := c.DB.SelectUserCalendarFeeds(ctx, userID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get calendar feeds")
+		return
+	}
+	houses, err := c.DB.UserHouses(ctx, userID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get houses")
+		return
+	}
+	RenderTempl(ctx, components.CalendarFeedSection(feeds, houses, created))
+}
+
+// creates the secret link of a calendar feed, the link is shown only in this response
+//
+// without a house the feed has the due dates of all houses of the user
+func (c *Controller) PostHxCalendarFeed(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	var houseID pgtype.UUID
+	if id := ctx.PostForm("house_id"); id != "" {
+		if err := houseID.Scan(id); err != nil || !houseID.Valid {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, g.ErrorInvalidID)
+			return
+		}
+		if !isHouseResident(ctx, c.DB, houseID) {
+			utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+			return
+		}
+	}
+
+	// the token is in the link, no prefix is needed to tell it apart
+	token := rand.Text()
+	_, err := c.DB.InsertCalendarFeed(ctx, dbqueries.InsertCalendarFeedParams{
+		UserID:    authInfo.UserID,
+		HouseID:   houseID,
+		TokenHash: accesstokens.Hash(token),
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not create calendar feed")
+		return
+	}
+
+	link := c.Mailer.URL(utils.ReplaceParam(g.RCalendarFeed, "token", token+".ics"))
+	c.renderCalendarFeeds(ctx, authInfo.UserID, link)
+}
+
+func (c *Controller) DeleteHxCalendarFeed(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, g.ErrorInvalidID)
+		return
+	}
+
+	deleted, err := c.DB.DeleteCalendarFeed(ctx, dbqueries.DeleteCalendarFeedParams{
+		ID:     id,
+		UserID: authInfo.UserID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not revoke calendar feed")
+		return
+	}
+	if deleted == 0 {
+		utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorInvalidID)
+		return
+	}
+
+	c.renderCalendarFeeds(ctx, authInfo.UserID, "")
+}
+
+// iCalendar file with the due dates of reminders and unpaid payments
+//
+// the feed of a house is empty after the user has moved out of it
+func (c *Controller) CalendarFeed(ctx *gin.Context) {
+	token, _ := strings.CutSuffix(ctx.Param("token"), ".ics")
+	feed, err := c.DB.SelectCalendarFeed(ctx, accesstokens.Hash(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorNotFound)
+			return
+		}
+		HandleServerError(ctx, err, "could not get calendar feed")
+		return
+	}
+	// calendar apps poll often, same as sessions last used is not updated every time
+	if !feed.LastUsedAt.Valid || time.Since(feed.LastUsedAt.Time) >= rdb.SessionTouchInterval {
+		c.DB.TouchCalendarFeed(ctx, feed.ID)
+	}
+
+	reminders, err := c.DB.SelectCalendarReminders(ctx, dbqueries.SelectCalendarRemindersParams{
+		UserID:  feed.UserID,
+		HouseID: feed.HouseID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not get reminders")
+		return
+	}
+	payments, err := c.DB.SelectCalendarPayments(ctx, dbqueries.SelectCalendarPaymentsParams{
+		UserID:  feed.UserID,
+		HouseID: feed.HouseID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not get payments")
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+	calendar := ical.Calendar{
+		ProductID: calendarProductID,
+		Name:      utils.T(reqCtx, locales.LKCalendarFeedsCalendarName, "Roommates"),
+	}
+	for _, reminder := range reminders {
+		summary := models.ParseReminderContent(reminder.Content).Title
+		if reminder.AssigneeUsername != nil {
+			summary += " · " + utils.T(reqCtx, locales.LKRemindersAssignedTo, "", *reminder.AssigneeUsername)
+		}
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("reminder-%d@roommates", reminder.ID),
+			Date:        reminder.DueDate.Time,
+			Summary:     summary,
+			Description: utils.T(reqCtx, locales.LKCalendarFeedsReminderDescription, "", reminder.HouseName),
+			URL:         c.Mailer.URL(utils.ReplaceParam(g.RHouseID, "id", reminder.HouseID.String())),
+			Repeat:      reminderFrequencies[reminder.Recurrence],
+			Modified:    reminder.UpdatedAt.Time,
+		})
+	}
+	for _, payment := range payments {
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("payment-%s@roommates", payment.ID.String()),
+			Date:        payment.DueDate.Time,
+			Summary:     utils.T(reqCtx, locales.LKCalendarFeedsPayment, "", payment.PaymentName, payment.Amount),
+			Description: utils.T(reqCtx, locales.LKCalendarFeedsPaymentDescription, "", payment.HouseName),
+			URL:         c.Mailer.URL(g.RPayments),
+			Modified:    payment.UpdatedAt.Time,
+		})
+	}
+
+	ctx.Header(string(g.HContentType), ical.ContentType)
+	ctx.Header(string(g.HContentDisposition), `inline; filename="roommates.ics"`)
+	ctx.Status(http.StatusOK)
+	if _, err = calendar.WriteTo(ctx.Writer); err != nil {
+		log.Error().Err(err).Int64("feed_id", feed.ID).Msg("error writing calendar feed")
+	}
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"roommates/accesstokens"
+	cc "roommates/chatcommands"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	l "roommates/locales"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/pagination"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// commands of the house chat
+func (c *Controller) chatCommands() *cc.Registry {
+	r := cc.NewRegistry()
+	r.Register(cc.Command{
+		Name:  "help",
+		Usage: l.LKChatCommandsUsageHelp,
+		Run: func(ctx *gin.Context, call cc.Call) (cc.Reply, error) {
+			reply := cc.Ok(l.LKChatCommandsCommands)
+			for _, cmd := range r.Commands() {
+				reply.Messages = append(reply.Messages, l.LKMessage{Key: cmd.Usage})
+			}
+			return reply, nil
+		},
+	}).Register(cc.Command{
+		Name:    "owe",
+		Usage:   l.LKChatCommandsUsageOwe,
+		Allowed: allPermissions(scopePermission(accesstokens.WritePayments), c.verifiedEmailPermission),
+		Run:     c.oweCommand,
+	}).Register(cc.Command{
+		Name:    "chore",
+		Usage:   l.LKChatCommandsUsageChore,
+		Allowed: scopePermission(accesstokens.WriteHouses),
+		Run:     c.choreCommand,
+	}).Register(cc.Command{
+		Name:    "note",
+		Usage:   l.LKChatCommandsUsageNote,
+		Allowed: scopePermission(accesstokens.ReadNotes),
+		Run:     c.noteCommand,
+	})
+	return r
+}
+
+// personal access tokens need the scope, sessions have every scope
+func scopePermission(scope accesstokens.Scope) cc.Permission {
+	return func(ctx *gin.Context, call cc.Call) (bool, error) {
+		return middleware.GetAuthInfo(ctx).HasScope(string(scope)), nil
+	}
+}
+
+func (c *Controller) verifiedEmailPermission(ctx *gin.Context, call cc.Call) (bool, error) {
+	return c.DB.IsEmailValidated(ctx, call.UserID)
+}
+
+// allowed when every permission allows
+func allPermissions(permissions ...cc.Permission) cc.Permission {
+	return func(ctx *gin.Context, call cc.Call) (bool, error) {
+		for _, permission := range permissions {
+			if allowed, err := permission(ctx, call); err != nil || !allowed {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// "/owe 12.50 pizza @anna @mari" -- anna and mari have to pay for the pizza
+func (c *Controller) oweCommand(ctx *gin.Context, call cc.Call) (cc.Reply, error) {
+	if len(call.Args) < 2 {
+		return cc.Fail(l.LKChatCommandsUsageOwe), nil
+	}
+	if len(call.Mentions) == 0 {
+		return cc.Fail(l.LKChatCommandsErrorNoPayers), nil
+	}
+
+	residents, err := c.DB.SelectHouseRoommates(ctx, call.HouseID)
+	if err != nil {
+		return cc.Reply{}, err
+	}
+	var payerIDs []pgtype.UUID
+	var payers []string
+	for _, mention := range call.Mentions {
+		i := slices.IndexFunc(residents, func(r dbqueries.SelectHouseRoommatesRow) bool {
+			return strings.EqualFold(r.Username, mention)
+		})
+		if i == -1 {
+			return cc.Fail(l.LKChatCommandsErrorNotResident, mention), nil
+		}
+		// the same payer mentioned twice is one payer
+		if !slices.Contains(payerIDs, residents[i].ID) {
+			payerIDs = append(payerIDs, residents[i].ID)
+			payers = append(payers, residents[i].Username)
+		}
+	}
+
+	model := models.Payment{
+		Name:   call.Text(1),
+		Amount: call.Args[0],
+	}
+	for _, id := range payerIDs {
+		model.PayerIDs = append(model.PayerIDs, id.String())
+	}
+	if isValid, msgs := model.IsValid(); !isValid {
+		return cc.FailWith(msgs), nil
+	}
+
+	if _, err = c.createPayment(ctx, call.HouseID, model, payerIDs); err != nil {
+		return cc.Reply{}, err
+	}
+	reply := cc.Ok(l.LKChatCommandsPaymentCreated, model.Name, model.Amount, strings.Join(payers, ", "))
+	reply.Link = g.RPayments
+	return reply, nil
+}
+
+// "/chore done trash" -- completes the reminder with "trash" in the title
+func (c *Controller) choreCommand(ctx *gin.Context, call cc.Call) (cc.Reply, error) {
+	if len(call.Args) < 2 || strings.ToLower(call.Args[0]) != "done" {
+		return cc.Fail(l.LKChatCommandsUsageChore), nil
+	}
+	search := call.Text(1)
+
+	reminders, err := c.DB.SelectHouseReminders(ctx, call.HouseID)
+	if err != nil {
+		return cc.Reply{}, err
+	}
+	var matchIDs []int32
+	var matchTitles []string
+	for _, reminder := range reminders {
+		title := models.ParseReminderContent(reminder.Content).Title
+		// exact match wins over reminders which only contain the words
+		if strings.EqualFold(title, search) {
+			matchIDs, matchTitles = []int32{reminder.ID}, []string{title}
+			break
+		}
+		if strings.Contains(strings.ToLower(title), strings.ToLower(search)) {
+			matchIDs = append(matchIDs, reminder.ID)
+			matchTitles = append(matchTitles, title)
+		}
+	}
+	if len(matchIDs) == 0 {
+		return cc.Fail(l.LKChatCommandsErrorReminderNotFound, search), nil
+	}
+	if len(matchIDs) > 1 {
+		return cc.Fail(l.LKChatCommandsErrorReminderAmbiguous, strings.Join(matchTitles, ", ")), nil
+	}
+
+	if _, err = c.completeReminder(ctx, matchIDs[0]); err != nil {
+		return cc.Reply{}, err
+	}
+	return cc.Ok(l.LKChatCommandsReminderCompleted, matchTitles[0]), nil
+}
+
+// "/note wifi" -- link to the last changed note with "wifi" in the title
+func (c *Controller) noteCommand(ctx *gin.Context, call cc.Call) (cc.Reply, error) {
+	search := call.Text(0)
+	if search == "" {
+		return cc.Fail(l.LKChatCommandsUsageNote), nil
+	}
+
+	notes, _, err := c.notesPage(ctx, call.HouseID, pagination.Page{
+		Limit:   1,
+		Sort:    notesResource.DefaultSort,
+		Filters: map[string]string{"title": search},
+	})
+	if err != nil {
+		return cc.Reply{}, err
+	}
+	if len(notes) == 0 {
+		return cc.Fail(l.LKChatCommandsErrorNoteNotFound, search), nil
+	}
+	reply := cc.Ok(l.LKChatCommandsNoteFound, notes[0].Title)
+	reply.Link = g.RNotes
+	return reply, nil
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	"roommates/gintemplrenderer"
+	g "roommates/globals"
+	"roommates/locales"
+	"roommates/mailer"
+	"roommates/middleware"
+	"roommates/rdb"
+	"roommates/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// how often a user can ask for a new verification email
+const verificationResendCooldown = 2 * time.Minute
+
+// stored with the verification token
+//
+// email is kept so that the link stops working when the email is changed
+type emailVerification struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Email  string      `json:"email"`
+}
+
+func (c *Controller) sendVerificationEmail(ctx *gin.Context, userID pgtype.UUID, email string) error {
+	token, err := c.RH.CreateToken(ctx, rdb.TPEmailVerification, emailVerification{
+		UserID: userID,
+		Email:  email,
+	}, rdb.EEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	url := c.Mailer.URL(utils.ReplaceParam(g.RVerifyEmailToken, "token", token))
+	return c.Mailer.Send(ctx.Request.Context(), mailer.Mail{
+		To:      email,
+		UserID:  userID,
+		Subject: utils.T(ctx.Request.Context(), locales.LKEmailVerifySubject, "Verify your email"),
+		Body:    components.EmailVerify(url),
+	})
+}
+
+// route middleware for actions which require a verified email
+//
+// htmx GET requests (modals) get a modal asking to verify the email instead
+func (c *Controller) RequireVerifiedEmail(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	verified, err := c.DB.IsEmailValidated(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "unable to check email verification")
+		ctx.Abort()
+		return
+	}
+	if verified {
+		ctx.Next()
+		return
+	}
+
+	if ctx.Request.Method == http.MethodGet && utils.IsRequestHTMX(ctx) {
+		RenderTempl(ctx, components.VerifyEmailRequiredModal())
+	} else {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorEmailNotVerified)
+	}
+	ctx.Abort()
+}
+
+// the link from the verification email, works without being logged in
+func (c *Controller) VerifyEmail(ctx *gin.Context) {
+	var value emailVerification
+	found, err := c.RH.ConsumeToken(ctx, rdb.TPEmailVerification, ctx.Param("token"), &value)
+	if err != nil {
+		HandleServerError(ctx, err, "unable to check verification token")
+		return
+	}
+
+	success := false
+	if found {
+		updated, err := c.DB.SetEmailValidated(ctx, dbqueries.SetEmailValidatedParams{
+			ID:    value.UserID,
+			Email: value.Email,
+		})
+		if err != nil {
+			HandleServerError(ctx, err, "could not verify email")
+			return
+		}
+		success = updated > 0
+	}
+
+	status := http.StatusOK
+	if !success {
+		status = http.StatusForbidden
+	}
+	r := gintemplrenderer.New(ctx.Request.Context(), status, components.PageVerifyEmail(success))
+	ctx.Render(r.Status, r)
+}
+
+func (c *Controller) HxVerifyEmailBanner(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	verified, err := c.DB.IsEmailValidated(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "unable to check email verification")
+		return
+	}
+	RenderTempl(ctx, components.VerifyEmailBanner(verified, ""))
+}
+
+func (c *Controller) ResendVerificationEmail(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	user, err := c.DB.SelectUserEmail(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get user email")
+		return
+	}
+	verified, err := c.DB.IsEmailValidated(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "unable to check email verification")
+		return
+	}
+	if verified {
+		RenderTempl(ctx, components.VerifyEmailBanner(true, ""))
+		return
+	}
+
+	allowed, err := c.RH.Cooldown(ctx, "verify-email:"+authInfo.UserID.String(), verificationResendCooldown)
+	if err != nil {
+		HandleServerError(ctx, err, "unable to check cooldown")
+		return
+	}
+	if !allowed {
+		notice := utils.T(ctx.Request.Context(), locales.LKVerifyEmailWait, "Try again in a few minutes")
+		RenderTempl(ctx, components.VerifyEmailBanner(false, notice))
+		return
+	}
+
+	if err = c.sendVerificationEmail(ctx, authInfo.UserID, user.Email); err != nil {
+		HandleServerError(ctx, err, "could not send verification email")
+		return
+	}
+	notice := utils.T(ctx.Request.Context(), locales.LKVerifyEmailSent, "A new link has been sent")
+	RenderTempl(ctx, components.VerifyEmailBanner(false, notice))
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/db/dbqueries"
+	"roommates/etag"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// sets the ETag of the response, responds with 304 when the client already has it
+//
+//	bool == true -- response was sent
+func isNotModified(ctx *gin.Context, tag string) bool {
+	ctx.Header(string(g.HETag), tag)
+	// the client may keep the response, but has to check it is still current
+	ctx.Header(string(g.HCacheControl), "private, no-cache")
+	if header := ctx.GetHeader(string(g.HIfNoneMatch)); header != "" && etag.NoneMatch(header, tag) {
+		ctx.AbortWithStatus(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// checks If-Match against the row locked inside of the write transaction,
+// so the resource cannot change between the check and the write,
+// requests without If-Match are let through
+//
+//   - ErrorPreconditionFailed -- resource has changed or is deleted
+func checkMatch(ctx *gin.Context, lock func() (string, error)) error {
+	header := ctx.GetHeader(string(g.HIfMatch))
+	if header == "" {
+		return nil
+	}
+	tag, err := lock()
+	if errors.Is(err, pgx.ErrNoRows) {
+		return g.ErrorPreconditionFailed
+	}
+	if err != nil {
+		return err
+	}
+	if !etag.Match(header, tag) {
+		return g.ErrorPreconditionFailed
+	}
+	return nil
+}
+
+// responds to an error of a write checked with checkMatch
+func handleConditionalWriteError(ctx *gin.Context, err error, publicError string) {
+	if errors.Is(err, g.ErrorPreconditionFailed) {
+		utils.ErrorResponse(ctx, http.StatusPreconditionFailed, err)
+		return
+	}
+	HandleServerError(ctx, err, publicError)
+}
+
+func checkNoteMatch(ctx *gin.Context, q *dbqueries.Queries, note dbqueries.SelectNoteRow) error {
+	return checkMatch(ctx, func() (string, error) {
+		updatedAt, err := q.LockNote(ctx, note.NoteID)
+		note.UpdatedAt = updatedAt
+		return noteETag(note), err
+	})
+}
+
+func checkHouseMatch(ctx *gin.Context, q *dbqueries.Queries, houseID pgtype.UUID) error {
+	return checkMatch(ctx, func() (string, error) {
+		house, err := q.LockHouse(ctx, houseID)
+		if err != nil {
+			return "", err
+		}
+		roommates, err := q.SelectHouseRoommates(ctx, houseID)
+		return houseETag(house, roommates), err
+	})
+}
+
+func noteETag(note dbqueries.SelectNoteRow) string {
+	return etag.New("note", strconv.Itoa(int(note.NoteID)), etag.Version(note.UpdatedAt.Time))
+}
+
+// members are a part of the house representation, but do not change updated_at of the house
+func houseETag(house dbqueries.House, roommates []dbqueries.SelectHouseRoommatesRow) string {
+	parts := []string{"house", house.ID.String(), etag.Version(house.UpdatedAt.Time)}
+	for _, roommate := range roommates {
+		parts = append(parts, roommate.ID.String(), roommate.Username)
+	}
+	return etag.New(parts...)
+}
+
+// settling a share does not change updated_at of the payment, so the status is a part of the tag
+func paymentETag(payment dbqueries.SelectUserPaymentRow) string {
+	return etag.New(
+		"payment",
+		payment.ID.String(),
+		etag.Version(payment.UpdatedAt.Time),
+		string(payment.PaymentStatus.HousePaymentStatus),
+	)
+}
+
+// tag of a page of a htmx list, the list is rendered differently for every user
+//
+//	versions -- ids and versions of the rows on the page
+func listETag(ctx *gin.Context, kind, nextURL string, versions []string) string {
+	parts := append([]string{kind, middleware.GetAuthInfo(ctx).UserID.String(), nextURL}, versions...)
+	return etag.New(parts...)
+}
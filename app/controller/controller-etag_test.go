@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"roommates/db/dbqueries"
+	"roommates/db/dbtest"
+	g "roommates/globals"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+func ifMatchContext(tag string) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+	if tag != "" {
+		ctx.Request.Header.Set(string(g.HIfMatch), tag)
+	}
+	return ctx
+}
+
+func insertHouseWithNote(t *testing.T, db *dbqueries.Queries) (pgtype.UUID, dbqueries.SelectNoteRow) {
+	t.Helper()
+	ctx := context.Background()
+	userID, err := db.InsertUser(ctx, dbqueries.InsertUserParams{Email: "maker@roommates.test", Username: "maker", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	houseID, err := db.InsertHouse(ctx, dbqueries.InsertHouseParams{Name: "house", MakerID: userID})
+	if err != nil {
+		t.Fatalf("could not insert house: %v", err)
+	}
+	noteID, err := db.InsertNote(ctx, dbqueries.InsertNoteParams{Title: "title", Content: "content", HouseID: houseID, MakerID: userID})
+	if err != nil {
+		t.Fatalf("could not insert note: %v", err)
+	}
+	note, err := db.SelectNote(ctx, noteID)
+	if err != nil {
+		t.Fatalf("could not get note: %v", err)
+	}
+	return houseID, note
+}
+
+// the note is changed by another request after the client fetched it
+func TestCheckNoteMatchInsideTransaction(t *testing.T) {
+	pool := dbtest.Pool(t)
+	db := dbqueries.New(pool)
+	bg := context.Background()
+	_, note := insertHouseWithNote(t, db)
+	fetched := noteETag(note)
+
+	tx, err := pool.Begin(bg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(bg)
+	if err = checkNoteMatch(ifMatchContext(fetched), db.WithTx(tx), note); err != nil {
+		t.Fatalf("checkNoteMatch with the current tag = %v", err)
+	}
+	tx.Rollback(bg)
+
+	err = db.UpdateNote(bg, dbqueries.UpdateNoteParams{ID: note.NoteID, Title: "changed", Content: "changed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the note was read before the change, the tag has to come from the locked row
+	tx, err = pool.Begin(bg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(bg)
+	err = checkNoteMatch(ifMatchContext(fetched), db.WithTx(tx), note)
+	if !errors.Is(err, g.ErrorPreconditionFailed) {
+		t.Errorf("checkNoteMatch with a stale tag = %v, want ErrorPreconditionFailed", err)
+	}
+	if err = checkNoteMatch(ifMatchContext(""), db.WithTx(tx), note); err != nil {
+		t.Errorf("checkNoteMatch without If-Match = %v", err)
+	}
+}
+
+func TestCheckHouseMatchMembers(t *testing.T) {
+	pool := dbtest.Pool(t)
+	db := dbqueries.New(pool)
+	bg := context.Background()
+	houseID, _ := insertHouseWithNote(t, db)
+
+	house, err := db.SelectHouse(bg, houseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetched := houseETag(house, nil)
+
+	// members are a part of the tag, a new member makes it stale without changing the house row
+	userID, err := db.InsertUser(bg, dbqueries.InsertUserParams{Email: "member@roommates.test", Username: "member", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.InsertUserIntoHouse(bg, dbqueries.InsertUserIntoHouseParams{UserID: userID, HouseID: houseID}); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := pool.Begin(bg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(bg)
+	err = checkHouseMatch(ifMatchContext(fetched), db.WithTx(tx), houseID)
+	if !errors.Is(err, g.ErrorPreconditionFailed) {
+		t.Errorf("checkHouseMatch after a member was added = %v, want ErrorPreconditionFailed", err)
+	}
+	if err = checkHouseMatch(ifMatchContext(fetched), db.WithTx(tx), pgtype.UUID{Bytes: [16]byte{1}, Valid: true}); !errors.Is(err, g.ErrorPreconditionFailed) {
+		t.Errorf("checkHouseMatch of a deleted house = %v, want ErrorPreconditionFailed", err)
+	}
+}
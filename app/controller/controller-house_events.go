@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// amount of events shown at once in the activity feed
+const activityPageSize = 20
+
+// renders a page of the house activity feed, newest first
+//
+// query param `before` is the id of the last event already shown
+func (c *Controller) HxHouseActivity(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+
+	var beforeID int64
+	if qBefore := ctx.Query("before"); qBefore != "" {
+		var err error
+		beforeID, err = strconv.ParseInt(qBefore, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	houseEvents, err := c.DB.SelectHouseEvents(ctx, dbqueries.SelectHouseEventsParams{
+		HouseID:  *houseID,
+		BeforeID: beforeID,
+		PageSize: activityPageSize,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not get house activity")
+		return
+	}
+
+	hasMore := len(houseEvents) == activityPageSize
+	tc := components.HouseActivity(houseID.String(), houseEvents, hasMore, beforeID == 0)
+	RenderTempl(ctx, tc)
+}
@@ -1,6 +1,137 @@
 package controller
 
 // TODO: API-s for
-// - adding a reminder
-// - editing reminder (status or content)
+// - editing reminder content
 // - delete reminder
+
+import (
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	"roommates/events"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+func (c *Controller) PostHxReminder(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+
+	residents, err := c.DB.SelectHouseRoommates(ctx, *houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+
+	var model models.Reminder
+	ctx.ShouldBind(&model)
+	model.HouseID = houseID.String()
+	isValid, _ := model.IsValid()
+	if !isValid {
+		RenderTempl(ctx, components.ReminderForm(&model, residents))
+		return
+	}
+
+	// an assignee who does not live in the house is left out, same as payers
+	var assigneeID pgtype.UUID
+	if assignees := filterPayers([]string{model.AssigneeID}, residents); len(assignees) == 1 {
+		assigneeID = assignees[0]
+	}
+
+	authInfo := middleware.GetAuthInfo(ctx)
+	_, err = c.DB.InsertReminder(ctx, dbqueries.InsertReminderParams{
+		Content:    model.GetContent(),
+		HouseID:    *houseID,
+		MakerID:    authInfo.UserID,
+		DueDate:    model.GetDueDate(),
+		Recurrence: model.GetRecurrence(),
+		AssigneeID: assigneeID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not save reminder")
+		return
+	}
+	utils.Redirect(ctx, "")
+}
+
+// intended to be used with RReminderID
+type ReqCompleteReminder struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+func (c *Controller) CompleteReminder(ctx *gin.Context) {
+	var req ReqCompleteReminder
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	reminder, err := c.completeReminder(ctx, req.ID)
+	if err != nil {
+		// only residents are able to complete reminders of the house
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+			return
+		}
+		HandleServerError(ctx, err, "could not complete reminder")
+		return
+	}
+	// a recurring reminder moved to its next due date
+	if reminder.ReminderStatus == dbqueries.HouseReminderStatusInProgress {
+		utils.Redirect(ctx, "")
+		return
+	}
+	// element is removed by htmx
+	ctx.Status(http.StatusOK)
+}
+
+// completes the reminder and records it in the house activity, a recurring reminder
+// stays in progress with its next due date
+//
+//	pgx.ErrNoRows -- reminder does not exist, is not in progress or the user does not live in its house
+func (c *Controller) completeReminder(ctx *gin.Context, reminderID int32) (dbqueries.CompleteReminderRow, error) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		return dbqueries.CompleteReminderRow{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	reminder, err := qtx.CompleteReminder(ctx, dbqueries.CompleteReminderParams{
+		ReminderID: reminderID,
+		UserID:     authInfo.UserID,
+	})
+	if err != nil {
+		return reminder, err
+	}
+
+	err = events.Record(ctx, qtx, events.Event{
+		Type:    events.ReminderCompleted,
+		HouseID: reminder.HouseID,
+		ActorID: authInfo.UserID,
+		Payload: events.Payload{
+			SubjectID: strconv.Itoa(int(reminderID)),
+			Subject:   models.ParseReminderContent(reminder.Content).Title,
+		},
+	})
+	if err != nil {
+		return reminder, err
+	}
+	return reminder, tx.Commit(ctx)
+}
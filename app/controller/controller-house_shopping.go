@@ -0,0 +1,377 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	"roommates/etag"
+	"roommates/events"
+	g "roommates/globals"
+	"roommates/locales"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func initialShoppingItemModel() models.ShoppingItem {
+	return models.ShoppingItem{ModelBase: models.ModelBase{Initial: true}}
+}
+
+// resident of the house from the uri, responds when the user does not live there
+func requireShoppingHouse(ctx *gin.Context, db *dbqueries.Queries) (pgtype.UUID, bool) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return pgtype.UUID{}, false
+	}
+	if !isHouseResident(ctx, db, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return pgtype.UUID{}, false
+	}
+	return *houseID, true
+}
+
+// shopping item id from the uri
+func requireShoppingItemID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("item_id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, g.ErrorInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+// changes whenever an item is added, checked off or taken off the list.
+// the list is rendered differently for every user, so the user is a part of it
+func shoppingListVersion(ctx *gin.Context, houseID pgtype.UUID, items []dbqueries.SelectShoppingItemsRow) string {
+	parts := []string{"shopping", houseID.String(), middleware.GetAuthInfo(ctx).UserID.String()}
+	for _, item := range items {
+		parts = append(parts, strconv.FormatInt(item.ID, 10), etag.Version(item.UpdatedAt.Time))
+	}
+	return etag.New(parts...)
+}
+
+func (c *Controller) renderShoppingList(ctx *gin.Context, houseID pgtype.UUID) {
+	items, err := c.DB.SelectShoppingItems(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get shopping list")
+		return
+	}
+	RenderTempl(ctx, components.ShoppingList(houseID.String(), items, shoppingListVersion(ctx, houseID, items)))
+}
+
+// polled by the list, 204 when the list has not changed since the version the client has
+func (c *Controller) HxShoppingList(ctx *gin.Context) {
+	houseID, ok := requireShoppingHouse(ctx, c.DB)
+	if !ok {
+		return
+	}
+	items, err := c.DB.SelectShoppingItems(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get shopping list")
+		return
+	}
+	version := shoppingListVersion(ctx, houseID, items)
+	// htmx does not swap on 204, so the checkboxes being clicked are left alone
+	if ctx.Query("version") == version {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	RenderTempl(ctx, components.ShoppingList(houseID.String(), items, version))
+}
+
+// puts the item on the list and records it into the house activity
+func (c *Controller) addShoppingItem(ctx *gin.Context, houseID pgtype.UUID, model models.ShoppingItem) error {
+	authInfo := middleware.GetAuthInfo(ctx)
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	name := strings.TrimSpace(model.Name)
+	itemID, err := qtx.InsertShoppingItem(ctx, dbqueries.InsertShoppingItemParams{
+		HouseID:  houseID,
+		ItemName: name,
+		Quantity: strings.TrimSpace(model.Quantity),
+		Category: model.GetCategory(),
+		AddedBy:  authInfo.UserID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = events.Record(ctx, qtx, events.Event{
+		Type:    events.ShoppingAdded,
+		HouseID: houseID,
+		ActorID: authInfo.UserID,
+		Payload: events.Payload{
+			SubjectID: strconv.FormatInt(itemID, 10),
+			Subject:   name,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (c *Controller) PostHxShoppingItem(ctx *gin.Context) {
+	houseID, ok := requireShoppingHouse(ctx, c.DB)
+	if !ok {
+		return
+	}
+	var model models.ShoppingItem
+	ctx.ShouldBind(&model)
+	isValid, _ := model.IsValid()
+	if !isValid {
+		RenderTempl(ctx, components.ShoppingItemForm(houseID.String(), &model))
+		return
+	}
+
+	if err := c.addShoppingItem(ctx, houseID, model); err != nil {
+		HandleServerError(ctx, err, "could not add shopping item")
+		return
+	}
+
+	// the list refreshes itself, the category is kept for adding the next item
+	ctx.Header(string(g.HHXTrigger), g.EvShoppingChanged)
+	next := initialShoppingItemModel()
+	next.Category = model.Category
+	RenderTempl(ctx, components.ShoppingItemForm(houseID.String(), &next))
+}
+
+func (c *Controller) DeleteHxShoppingItem(ctx *gin.Context) {
+	houseID, ok := requireShoppingHouse(ctx, c.DB)
+	if !ok {
+		return
+	}
+	id, ok := requireShoppingItemID(ctx)
+	if !ok {
+		return
+	}
+
+	// an item someone else already removed is not an error, the list is shown as it is now
+	_, err := c.DB.DeleteShoppingItem(ctx, dbqueries.DeleteShoppingItemParams{
+		ID:      id,
+		HouseID: houseID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not delete shopping item")
+		return
+	}
+	c.renderShoppingList(ctx, houseID)
+}
+
+// marks the item bought by the user or not bought, checking it off is recorded into the house activity
+//
+//	pgx.ErrNoRows -- item is not on the list anymore or someone else bought it
+func (c *Controller) setShoppingItemBought(ctx *gin.Context, houseID pgtype.UUID, itemID int64, bought bool) error {
+	authInfo := middleware.GetAuthInfo(ctx)
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	name, err := qtx.SetShoppingItemBought(ctx, dbqueries.SetShoppingItemBoughtParams{
+		Bought:  bought,
+		UserID:  authInfo.UserID,
+		ID:      itemID,
+		HouseID: houseID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if bought {
+		err = events.Record(ctx, qtx, events.Event{
+			Type:    events.ShoppingBought,
+			HouseID: houseID,
+			ActorID: authInfo.UserID,
+			Payload: events.Payload{
+				SubjectID: strconv.FormatInt(itemID, 10),
+				Subject:   name,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// checks the item off as bought by the user or puts it back on the list
+//
+// the checkbox shows the change before the response, the list in the response
+// tells if the change was made. an item bought by someone else stays theirs
+func (c *Controller) PostHxShoppingItemBought(ctx *gin.Context) {
+	houseID, ok := requireShoppingHouse(ctx, c.DB)
+	if !ok {
+		return
+	}
+	id, ok := requireShoppingItemID(ctx)
+	if !ok {
+		return
+	}
+
+	// unchecked checkboxes are not sent
+	err := c.setShoppingItemBought(ctx, houseID, id, ctx.PostForm("bought") != "")
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		HandleServerError(ctx, err, "could not check off shopping item")
+		return
+	}
+	c.renderShoppingList(ctx, houseID)
+}
+
+// items the user has checked off
+func boughtShoppingItems(ctx *gin.Context, items []dbqueries.SelectShoppingItemsRow) []dbqueries.SelectShoppingItemsRow {
+	userID := middleware.GetAuthInfo(ctx).UserID
+	var bought []dbqueries.SelectShoppingItemsRow
+	for _, item := range items {
+		if item.BoughtBy == userID {
+			bought = append(bought, item)
+		}
+	}
+	return bought
+}
+
+func (c *Controller) renderShoppingPurchase(ctx *gin.Context, houseID pgtype.UUID, model *models.ShoppingPurchase, modal bool) {
+	items, err := c.DB.SelectShoppingItems(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get shopping list")
+		return
+	}
+	residents, err := c.DB.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+	bought := boughtShoppingItems(ctx, items)
+	if model.Initial {
+		// everything the user bought, shared by everyone in the house
+		for _, item := range bought {
+			model.ItemIDs = append(model.ItemIDs, strconv.FormatInt(item.ID, 10))
+		}
+		for _, resident := range residents {
+			model.PayerIDs = append(model.PayerIDs, resident.ID.String())
+		}
+	}
+
+	if modal {
+		RenderTempl(ctx, components.ShoppingPurchaseModal(model, bought, residents))
+	} else {
+		RenderTempl(ctx, components.ShoppingPurchaseForm(model, bought, residents))
+	}
+}
+
+// "I bought these"
+func (c *Controller) GetHxShoppingPurchaseModal(ctx *gin.Context) {
+	houseID, ok := requireShoppingHouse(ctx, c.DB)
+	if !ok {
+		return
+	}
+	model := models.ShoppingPurchase{
+		Payment: models.Payment{
+			ModelBase: models.ModelBase{Initial: true},
+			HouseID:   houseID.String(),
+			Name:      utils.T(ctx.Request.Context(), locales.LKShoppingPaymentName, "Shopping"),
+		},
+		AsPayment: true,
+	}
+	c.renderShoppingPurchase(ctx, houseID, &model, true)
+}
+
+// takes the chosen items the user bought off the list, with a payment when asked for
+func (c *Controller) PostHxShoppingPurchase(ctx *gin.Context) {
+	houseID, ok := requireShoppingHouse(ctx, c.DB)
+	if !ok {
+		return
+	}
+
+	var model models.ShoppingPurchase
+	ctx.ShouldBind(&model)
+	model.HouseID = houseID.String()
+	isValid, _ := model.IsValid()
+	if !isValid {
+		c.renderShoppingPurchase(ctx, houseID, &model, false)
+		return
+	}
+	if model.AsPayment {
+		// same as making a payment from the payment form
+		c.RequireVerifiedEmail(ctx)
+		if ctx.IsAborted() {
+			return
+		}
+	}
+
+	residents, err := c.DB.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		HandleServerError(ctx, err, "could not begin transaction")
+		return
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	var paymentID pgtype.UUID
+	if model.AsPayment {
+		paymentID, err = insertPayment(ctx, qtx, houseID, model.Payment, filterPayers(model.PayerIDs, residents))
+		if err != nil {
+			HandleServerError(ctx, err, "could not save payment")
+			return
+		}
+	}
+	userID := middleware.GetAuthInfo(ctx).UserID
+	settled, err := qtx.SettleShoppingItems(ctx, dbqueries.SettleShoppingItemsParams{
+		PaymentID: paymentID,
+		HouseID:   houseID,
+		UserID:    userID,
+		ItemIds:   model.GetItemIDs(),
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not settle shopping items")
+		return
+	}
+	// the items were put back on the list or removed in the meantime
+	if len(settled) == 0 {
+		model.Error = utils.T(ctx.Request.Context(), locales.LKShoppingErrorNoItems, "")
+		c.renderShoppingPurchase(ctx, houseID, &model, false)
+		return
+	}
+
+	// the payment, when there is one, is recorded on its own with the amount
+	payload := events.Payload{Subject: strings.Join(settled, ", ")}
+	if paymentID.Valid {
+		payload.SubjectID = paymentID.String()
+	}
+	err = events.Record(ctx, qtx, events.Event{
+		Type:    events.ShoppingSettled,
+		HouseID: houseID,
+		ActorID: userID,
+		Payload: payload,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "error recording house activity")
+		return
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		HandleServerError(ctx, err, "could not commit transaction")
+		return
+	}
+	utils.Redirect(ctx, "")
+}
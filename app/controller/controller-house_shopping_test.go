@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"roommates/db/dbqueries"
+	"roommates/db/dbtest"
+	"roommates/events"
+	g "roommates/globals"
+	"roommates/models"
+	"roommates/rdb"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func userContext(userID pgtype.UUID) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), g.GAuth, &rdb.UserSessionValue{UserID: userID}))
+	return ctx
+}
+
+func houseEventTypes(t *testing.T, db *dbqueries.Queries, houseID pgtype.UUID) []events.Type {
+	t.Helper()
+	rows, err := db.SelectHouseEvents(context.Background(), dbqueries.SelectHouseEventsParams{HouseID: houseID, PageSize: 10})
+	if err != nil {
+		t.Fatalf("could not get house events: %v", err)
+	}
+	var types []events.Type
+	for _, row := range rows {
+		types = append(types, events.Type(row.EventType))
+	}
+	return types
+}
+
+// adding and checking off is recorded, putting an item back or failing to take it over is not
+func TestShoppingItemEvents(t *testing.T) {
+	pool := dbtest.Pool(t)
+	c := &Controller{Pool: pool, DB: dbqueries.New(pool)}
+	bg := context.Background()
+
+	buyer, err := c.DB.InsertUser(bg, dbqueries.InsertUserParams{Email: "buyer@roommates.test", Username: "buyer", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	other, err := c.DB.InsertUser(bg, dbqueries.InsertUserParams{Email: "other@roommates.test", Username: "other", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	houseID, err := c.DB.InsertHouse(bg, dbqueries.InsertHouseParams{Name: "house", MakerID: buyer})
+	if err != nil {
+		t.Fatalf("could not insert house: %v", err)
+	}
+
+	err = c.addShoppingItem(userContext(buyer), houseID, models.ShoppingItem{Name: " milk ", Quantity: "2"})
+	if err != nil {
+		t.Fatalf("addShoppingItem = %v", err)
+	}
+	items, err := c.DB.SelectShoppingItems(bg, houseID)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("SelectShoppingItems = %v, %v, want one item", items, err)
+	}
+	itemID := items[0].ID
+
+	if err = c.setShoppingItemBought(userContext(buyer), houseID, itemID, true); err != nil {
+		t.Fatalf("setShoppingItemBought = %v", err)
+	}
+	err = c.setShoppingItemBought(userContext(other), houseID, itemID, false)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("setShoppingItemBought of an item someone else bought = %v, want pgx.ErrNoRows", err)
+	}
+	if err = c.setShoppingItemBought(userContext(buyer), houseID, itemID, false); err != nil {
+		t.Fatalf("setShoppingItemBought = %v", err)
+	}
+
+	got := houseEventTypes(t, c.DB, houseID)
+	want := []events.Type{events.ShoppingBought, events.ShoppingAdded}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("house events = %v, want %v", got, want)
+	}
+	rows, err := c.DB.SelectHouseEvents(bg, dbqueries.SelectHouseEventsParams{HouseID: houseID, PageSize: 1})
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("SelectHouseEvents = %v, %v", rows, err)
+	}
+	if payload := events.ParsePayload(rows[0].Payload); payload.Subject != "milk" {
+		t.Errorf("event subject = %q, want the trimmed item name", payload.Subject)
+	}
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/locales"
+	"roommates/models"
+	"roommates/utils"
+	"roommates/webhooks"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	maxHouseWebhooks = 10
+	// deliveries shown per webhook
+	webhookDeliveriesLimit = 20
+)
+
+func initialWebhookModel() models.Webhook {
+	return models.Webhook{ModelBase: models.ModelBase{Initial: true}}
+}
+
+func (c *Controller) renderHouseWebhooks(ctx *gin.Context, houseID pgtype.UUID, created string, model models.Webhook) {
+	hooks, err := c.DB.SelectHouseWebhooks(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get webhooks")
+		return
+	}
+	RenderTempl(ctx, components.HouseWebhookSection(houseID.String(), hooks, created, model))
+}
+
+// webhook id from the uri
+func requireWebhookID(ctx *gin.Context) (pgtype.UUID, bool) {
+	var id pgtype.UUID
+	if err := id.Scan(ctx.Param("webhook_id")); err != nil || !id.Valid {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, g.ErrorInvalidID)
+		return id, false
+	}
+	return id, true
+}
+
+func (c *Controller) HxHouseWebhooks(ctx *gin.Context) {
+	house, ok := c.getMadeHouse(ctx)
+	if !ok {
+		return
+	}
+	c.renderHouseWebhooks(ctx, house.ID, "", initialWebhookModel())
+}
+
+// registers a webhook, the signing secret is shown only in this response
+func (c *Controller) PostHxHouseWebhook(ctx *gin.Context) {
+	house, ok := c.getMadeHouse(ctx)
+	if !ok {
+		return
+	}
+	var model models.Webhook
+	ctx.ShouldBind(&model)
+
+	isValid, _ := model.IsValid()
+	if !isValid {
+		c.renderHouseWebhooks(ctx, house.ID, "", model)
+		return
+	}
+
+	hooks, err := c.DB.SelectHouseWebhooks(ctx, house.ID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get webhooks")
+		return
+	}
+	if len(hooks) >= maxHouseWebhooks {
+		model.Error = utils.T(ctx.Request.Context(), locales.LKWebhooksErrorTooMany, "", maxHouseWebhooks)
+		c.renderHouseWebhooks(ctx, house.ID, "", model)
+		return
+	}
+
+	secret := webhooks.NewSecret()
+	slices.Sort(model.EventTypes)
+	_, err = c.DB.InsertHouseWebhook(ctx, dbqueries.InsertHouseWebhookParams{
+		HouseID:    house.ID,
+		Url:        strings.TrimSpace(model.URL),
+		Secret:     secret,
+		EventTypes: slices.Compact(model.EventTypes),
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not create webhook")
+		return
+	}
+
+	c.renderHouseWebhooks(ctx, house.ID, secret, initialWebhookModel())
+}
+
+func (c *Controller) DeleteHxHouseWebhook(ctx *gin.Context) {
+	house, ok := c.getMadeHouse(ctx)
+	if !ok {
+		return
+	}
+	id, ok := requireWebhookID(ctx)
+	if !ok {
+		return
+	}
+
+	deleted, err := c.DB.DeleteHouseWebhook(ctx, dbqueries.DeleteHouseWebhookParams{
+		ID:      id,
+		HouseID: house.ID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not delete webhook")
+		return
+	}
+	if deleted == 0 {
+		utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorInvalidID)
+		return
+	}
+
+	c.renderHouseWebhooks(ctx, house.ID, "", initialWebhookModel())
+}
+
+// enables a webhook which was disabled after too many failures
+func (c *Controller) PostHxHouseWebhookEnable(ctx *gin.Context) {
+	house, ok := c.getMadeHouse(ctx)
+	if !ok {
+		return
+	}
+	id, ok := requireWebhookID(ctx)
+	if !ok {
+		return
+	}
+
+	enabled, err := c.DB.EnableHouseWebhook(ctx, dbqueries.EnableHouseWebhookParams{
+		ID:      id,
+		HouseID: house.ID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not enable webhook")
+		return
+	}
+	if enabled == 0 {
+		utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorInvalidID)
+		return
+	}
+
+	c.renderHouseWebhooks(ctx, house.ID, "", initialWebhookModel())
+}
+
+func (c *Controller) HxHouseWebhookDeliveries(ctx *gin.Context) {
+	house, ok := c.getMadeHouse(ctx)
+	if !ok {
+		return
+	}
+	id, ok := requireWebhookID(ctx)
+	if !ok {
+		return
+	}
+
+	deliveries, err := c.DB.SelectWebhookDeliveries(ctx, dbqueries.SelectWebhookDeliveriesParams{
+		WebhookID: id,
+		HouseID:   house.ID,
+		Limit:     webhookDeliveriesLimit,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not get webhook deliveries")
+		return
+	}
+	RenderTempl(ctx, components.WebhookDeliveries(deliveries))
+}
@@ -1,20 +1,17 @@
 package controller
 
-// TODO: API-s for
-// - making a new house
-// - inviting a new user to the house
-// - changing the name of the house
-// - changing the picture of the house
-
 import (
 	"fmt"
 	"net/http"
 	"roommates/components"
 	"roommates/db/dbqueries"
+	"roommates/etag"
+	"roommates/events"
 	g "roommates/globals"
 	"roommates/middleware"
 	"roommates/models"
 	"roommates/utils"
+	"slices"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -40,6 +37,62 @@ func isHouseMaker(ctx *gin.Context, q *dbqueries.Queries, houseID pgtype.UUID) b
 	return isMaker
 }
 
+// is the authenticated user living in the house
+//
+// will log error if it occurs
+func isHouseResident(ctx *gin.Context, q *dbqueries.Queries, houseID pgtype.UUID) bool {
+	authInfo := middleware.GetAuthInfo(ctx)
+	isResident, err := q.IsUserInHouse(ctx, dbqueries.IsUserInHouseParams{
+		HouseID: houseID,
+		UserID:  authInfo.UserID,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Caller().
+			Str("house_id", houseID.String()).
+			Str("user_id", authInfo.UserID.String()).
+			Msg("")
+	}
+	return isResident
+}
+
+// records member-added and member-removed events by comparing residents before and after the change
+//
+// the authenticated user is not recorded as added since they are the one doing the adding
+func recordMembershipChanges(ctx *gin.Context, q *dbqueries.Queries, houseID pgtype.UUID, before, after []dbqueries.SelectHouseRoommatesRow) error {
+	authInfo := middleware.GetAuthInfo(ctx)
+	record := func(from, to []dbqueries.SelectHouseRoommatesRow, eventType events.Type) error {
+		stayed := make(map[pgtype.UUID]bool, len(to))
+		for _, roommate := range to {
+			stayed[roommate.ID] = true
+		}
+
+		for _, roommate := range from {
+			if stayed[roommate.ID] || roommate.ID == authInfo.UserID {
+				continue
+			}
+			err := events.Record(ctx, q, events.Event{
+				Type:    eventType,
+				HouseID: houseID,
+				ActorID: authInfo.UserID,
+				Payload: events.Payload{
+					SubjectID: roommate.ID.String(),
+					Subject:   roommate.Username,
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := record(after, before, events.MemberAdded); err != nil {
+		return err
+	}
+	return record(before, after, events.MemberRemoved)
+}
+
 func insertUsersToHouse(ctx *gin.Context, q *dbqueries.Queries, roomateIDs []pgtype.UUID, houseID pgtype.UUID) error {
 	for _, roomateID := range roomateIDs {
 		// not expecting hundreds of assignements here so should be fine
@@ -54,6 +107,71 @@ func insertUsersToHouse(ctx *gin.Context, q *dbqueries.Queries, roomateIDs []pgt
 	return nil
 }
 
+// makes the house with the roommates and the authenticated user in it
+//
+// roommates should already be checked with blockingRoommates
+func (c *Controller) createHouse(ctx *gin.Context, name string, roommateIDs []pgtype.UUID) (pgtype.UUID, error) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	// it is assumed the user making the house wants to be in the house
+	roommateIDs = append(roommateIDs, authInfo.UserID)
+
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	houseID, err := qtx.InsertHouse(ctx, dbqueries.InsertHouseParams{
+		Name:    name,
+		MakerID: authInfo.UserID,
+	})
+	if err != nil {
+		// currently there should not be unique violation issues
+		return pgtype.UUID{}, err
+	}
+
+	if err = insertUsersToHouse(ctx, qtx, roommateIDs, houseID); err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	residents, err := qtx.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	if err = recordMembershipChanges(ctx, qtx, houseID, nil, residents); err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return pgtype.UUID{}, err
+	}
+	return houseID, nil
+}
+
+// deletes the house along with the files of its picture
+func (c *Controller) deleteHouse(ctx *gin.Context, houseID pgtype.UUID) error {
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+	if err = checkHouseMatch(ctx, qtx, houseID); err != nil {
+		return err
+	}
+
+	pictureKey, err := qtx.DeleteHouse(ctx, houseID)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	c.Images.DeleteKey(pictureKey)
+	return nil
+}
+
 func renderHouseForm(ctx *gin.Context, model *models.House) {
 	tc := components.HouseForm(model)
 	RenderTempl(ctx, tc)
@@ -88,6 +206,22 @@ func (c *Controller) populateHouseModel(ctx *gin.Context, model *models.House) e
 	return nil
 }
 
+// roommates who have blocked the user adding them into the house
+//
+//	residents -- already live in the house and are not affected by blocks
+func blockingRoommates(ctx *gin.Context, q *dbqueries.Queries, userID pgtype.UUID, roommateIDs []pgtype.UUID, residents []dbqueries.SelectHouseRoommatesRow) ([]pgtype.UUID, error) {
+	blocking, err := q.SelectUsersBlockingUser(ctx, dbqueries.SelectUsersBlockingUserParams{
+		BlockedID: userID,
+		UserIds:   roommateIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(blocking, func(id pgtype.UUID) bool {
+		return slices.ContainsFunc(residents, func(r dbqueries.SelectHouseRoommatesRow) bool { return r.ID == id })
+	}), nil
+}
+
 func (c *Controller) HxRoomateSearch(ctx *gin.Context) {
 	var model models.House
 	ctx.ShouldBind(&model)
@@ -96,7 +230,7 @@ func (c *Controller) HxRoomateSearch(ctx *gin.Context) {
 	method := ctx.Request.Method
 	switch method {
 	case http.MethodGet:
-		render := func(foundUsers []dbqueries.UsersLikeExcludingExistingRow) {
+		render := func(foundUsers []dbqueries.SearchUsersExcludingExistingRow) {
 			tc := components.HouseRoommatesInputSearchResults(model.SearchedUser, foundUsers)
 			RenderTempl(ctx, tc)
 		}
@@ -106,9 +240,10 @@ func (c *Controller) HxRoomateSearch(ctx *gin.Context) {
 			return
 		}
 
-		users, err := c.DB.UsersLikeExcludingExisting(ctx, dbqueries.UsersLikeExcludingExistingParams{
-			Username:      model.SearchedUser,
+		users, err := c.DB.SearchUsersExcludingExisting(ctx, dbqueries.SearchUsersExcludingExistingParams{
+			SearcherID:    middleware.GetAuthInfo(ctx).UserID,
 			ExistingUsers: model.RoommateLabels,
+			Query:         model.SearchedUser,
 		})
 		if err != nil {
 			HandleServerError(ctx, err, "could not find users")
@@ -162,36 +297,19 @@ func (c *Controller) PostHxHouseForm(ctx *gin.Context) {
 		renderHouseForm(ctx, &model)
 		return
 	}
-	// it is assumed the user making the house wants to be in the house
-	roomateIDs = append(roomateIDs, authInfo.UserID)
-
-	tx, err := c.Pool.Begin(ctx.Request.Context())
+	blocking, err := blockingRoommates(ctx, c.DB, authInfo.UserID, roomateIDs, nil)
 	if err != nil {
-		HandleServerError(ctx, err, "no business pool party :(")
+		HandleServerError(ctx, err, "could not check blocked users")
 		return
 	}
-	defer tx.Rollback(ctx)
-	qtx := c.DB.WithTx(tx)
-
-	houseID, err := qtx.InsertHouse(ctx, dbqueries.InsertHouseParams{
-		Name:    model.Name,
-		MakerID: authInfo.UserID,
-	})
-	if err != nil {
-		// currently there should not be unique violation issues
-		HandleServerError(ctx, err, "unable to create this house")
-		return
-	}
-
-	err = insertUsersToHouse(ctx, qtx, roomateIDs, houseID)
-	if err != nil {
-		HandleServerError(ctx, err, "error assigning users to house")
+	if model.RemoveRoommates(ctx, blocking) {
+		renderHouseForm(ctx, &model)
 		return
 	}
 
-	err = tx.Commit(ctx)
+	houseID, err := c.createHouse(ctx, model.Name, roomateIDs)
 	if err != nil {
-		HandleServerError(ctx, err, "error commiting transaction")
+		HandleServerError(ctx, err, "unable to create this house")
 		return
 	}
 
@@ -213,7 +331,7 @@ func (c *Controller) DeleteHouse(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.DB.DeleteHouse(ctx, houseID); err != nil {
+	if err := c.deleteHouse(ctx, houseID); err != nil {
 		HandleServerError(ctx, err, "could not delete house")
 		return
 	}
@@ -257,6 +375,26 @@ func (c *Controller) PutHxHouseForm(ctx *gin.Context) {
 	}
 	defer tx.Rollback(ctx)
 	qtx := c.DB.WithTx(tx)
+	// API requests with If-Match compare members too
+	if _, err = qtx.LockHouse(ctx, houseID); err != nil {
+		HandleServerError(ctx, err, "could not get house")
+		return
+	}
+
+	residentsBefore, err := qtx.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+	blocking, err := blockingRoommates(ctx, qtx, authInfo.UserID, roomateIDs, residentsBefore)
+	if err != nil {
+		HandleServerError(ctx, err, "could not check blocked users")
+		return
+	}
+	if model.RemoveRoommates(ctx, blocking) {
+		renderHouseForm(ctx, &model)
+		return
+	}
 
 	// if len(roomateIDs) == 0 {
 	// 	if err := c.DB.DeleteHouse(ctx, houseID); err != nil {
@@ -276,6 +414,16 @@ func (c *Controller) PutHxHouseForm(ctx *gin.Context) {
 		return
 	}
 
+	residentsAfter, err := qtx.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+	if err = recordMembershipChanges(ctx, qtx, houseID, residentsBefore, residentsAfter); err != nil {
+		HandleServerError(ctx, err, "error recording house activity")
+		return
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		HandleServerError(ctx, err, "error commiting transaction")
@@ -303,3 +451,25 @@ func (c *Controller) HxHouseCardResidentsBadge(ctx *gin.Context) {
 	tc := components.HouseResidentBadge(residents)
 	RenderTempl(ctx, tc)
 }
+
+// renders the next page of the house cards on the houses page
+func (c *Controller) HxHousesList(ctx *gin.Context) {
+	page, ok := requirePage(ctx, housesResource)
+	if !ok {
+		return
+	}
+	houses, next, err := c.housesPage(ctx, middleware.GetAuthInfo(ctx).UserID, page)
+	if err != nil {
+		handlePageError(ctx, err, "could not get houses")
+		return
+	}
+	versions := make([]string, 0, len(houses))
+	for _, house := range houses {
+		versions = append(versions, house.ID.String(), etag.Version(house.UpdatedAt.Time))
+	}
+	nextURL := hxNextPageURL(ctx, g.RHxHousesList, next)
+	if isNotModified(ctx, listETag(ctx, "houses", nextURL, versions)) {
+		return
+	}
+	RenderTempl(ctx, components.HousesList(houses, nextURL))
+}
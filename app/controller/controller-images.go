@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/images"
+	"roommates/locales"
+	"roommates/middleware"
+	"roommates/rdb"
+	"roommates/utils"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// room for the rest of the multipart form besides the image
+const uploadOverhead = 1 << 20
+
+// processes and stores the "image" file of the form
+//
+// problems with the upload itself are returned as `problem` to be shown to the user,
+// err is only returned for server errors
+func (c *Controller) saveUploadedImage(ctx *gin.Context, kind images.Kind) (key, problem string, err error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, images.MaxUploadSize+uploadOverhead)
+	reqCtx := ctx.Request.Context()
+
+	header, err := ctx.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return "", utils.T(reqCtx, locales.LKImagesErrorTooLarge, "Image is too large"), nil
+		}
+		return "", utils.T(reqCtx, locales.LKImagesErrorMissing, "Choose an image"), nil
+	}
+	file, err := header.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	variants, err := images.Process(file, kind)
+	switch {
+	case errors.Is(err, images.ErrUnsupportedFormat):
+		return "", utils.T(reqCtx, locales.LKImagesErrorFormat, "Unsupported image format"), nil
+	case errors.Is(err, images.ErrTooLarge):
+		return "", utils.T(reqCtx, locales.LKImagesErrorTooLarge, "Image is too large"), nil
+	case err != nil:
+		return "", "", err
+	}
+
+	key, err = c.Images.Save(variants)
+	return key, "", err
+}
+
+// serves the stored images, a key never points to another image so they are cached for good
+func (c *Controller) Image(ctx *gin.Context) {
+	path, ok := c.Images.Path(ctx.Param("key"), ctx.Param("size"))
+	if !ok {
+		utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorInvalidID)
+		return
+	}
+	ctx.Header(string(g.HCacheControl), "private, max-age=31536000, immutable")
+	ctx.File(path)
+}
+
+func (c *Controller) PostHxAvatar(ctx *gin.Context) {
+	key, problem, err := c.saveUploadedImage(ctx, images.Avatar)
+	if err != nil {
+		HandleServerError(ctx, err, "could not save avatar")
+		return
+	}
+	if problem != "" {
+		RenderTempl(ctx, components.AvatarSection(middleware.GetAuthInfo(ctx).AvatarKey, problem))
+		return
+	}
+	c.setAvatar(ctx, key)
+}
+
+func (c *Controller) DeleteHxAvatar(ctx *gin.Context) {
+	c.setAvatar(ctx, "")
+}
+
+// empty key removes the avatar, the files of the previous one are deleted
+func (c *Controller) setAvatar(ctx *gin.Context, key string) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	var avatarKey *string
+	if key != "" {
+		avatarKey = &key
+	}
+
+	previous, err := c.DB.UpdateUserAvatarKey(ctx, dbqueries.UpdateUserAvatarKeyParams{
+		ID:        authInfo.UserID,
+		AvatarKey: avatarKey,
+	})
+	if err != nil {
+		c.Images.Delete(key)
+		HandleServerError(ctx, err, "could not update avatar")
+		return
+	}
+	c.Images.DeleteKey(previous)
+
+	// the navigation bar shows the avatar from the session
+	err = c.RH.UpdateUserSessions(ctx, authInfo.UserID, func(v *rdb.UserSessionValue) {
+		v.AvatarKey = key
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not update sessions")
+		return
+	}
+	RenderTempl(ctx, components.AvatarSection(key, ""))
+}
+
+// house from the id param when the user made it, otherwise responds with an error
+func (c *Controller) getMadeHouse(ctx *gin.Context) (dbqueries.House, bool) {
+	var houseID pgtype.UUID
+	if err := houseID.Scan(ctx.Param("id")); err != nil || !houseID.Valid {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorInvalidID)
+		return dbqueries.House{}, false
+	}
+	if !isHouseMaker(ctx, c.DB, houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+		return dbqueries.House{}, false
+	}
+
+	house, err := c.DB.SelectHouse(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get house")
+		return dbqueries.House{}, false
+	}
+	return house, true
+}
+
+func (c *Controller) PostHxHousePicture(ctx *gin.Context) {
+	house, ok := c.getMadeHouse(ctx)
+	if !ok {
+		return
+	}
+
+	key, problem, err := c.saveUploadedImage(ctx, images.HousePicture)
+	if err != nil {
+		HandleServerError(ctx, err, "could not save house picture")
+		return
+	}
+	if problem != "" {
+		RenderTempl(ctx, components.HousePicture(house, problem))
+		return
+	}
+	c.setHousePicture(ctx, house, key)
+}
+
+func (c *Controller) DeleteHxHousePicture(ctx *gin.Context) {
+	house, ok := c.getMadeHouse(ctx)
+	if !ok {
+		return
+	}
+	c.setHousePicture(ctx, house, "")
+}
+
+func (c *Controller) setHousePicture(ctx *gin.Context, house dbqueries.House, key string) {
+	house.PictureKey = nil
+	if key != "" {
+		house.PictureKey = &key
+	}
+
+	previous, err := c.DB.UpdateHousePictureKey(ctx, dbqueries.UpdateHousePictureKeyParams{
+		ID:         house.ID,
+		PictureKey: house.PictureKey,
+	})
+	if err != nil {
+		c.Images.Delete(key)
+		HandleServerError(ctx, err, "could not update house picture")
+		return
+	}
+	c.Images.DeleteKey(previous)
+	RenderTempl(ctx, components.HousePicture(house, ""))
+}
+
+// group conversation from the id param when the user takes part in it, otherwise responds with an error
+func (c *Controller) getGroupConversation(ctx *gin.Context) (dbqueries.Conversation, bool) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	var conversationID pgtype.UUID
+	if err := conversationID.Scan(ctx.Param("id")); err != nil || !conversationID.Valid {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorInvalidID)
+		return dbqueries.Conversation{}, false
+	}
+
+	conversation, err := c.DB.SelectConversation(ctx, conversationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorInvalidID)
+			return dbqueries.Conversation{}, false
+		}
+		HandleServerError(ctx, err, "could not get conversation")
+		return dbqueries.Conversation{}, false
+	}
+	// houses and direct messages have no image of their own
+	if conversation.RecipientType != dbqueries.ConversationRecipientTypeGroup ||
+		!slices.Contains(conversation.RecipientIds, authInfo.UserID.String()) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+		return dbqueries.Conversation{}, false
+	}
+	return conversation, true
+}
+
+func (c *Controller) PostHxConversationImage(ctx *gin.Context) {
+	conversation, ok := c.getGroupConversation(ctx)
+	if !ok {
+		return
+	}
+
+	key, problem, err := c.saveUploadedImage(ctx, images.ConversationImage)
+	if err != nil {
+		HandleServerError(ctx, err, "could not save conversation image")
+		return
+	}
+	if problem != "" {
+		RenderTempl(ctx, components.ConversationImage(conversation, problem))
+		return
+	}
+	c.setConversationImage(ctx, conversation, key)
+}
+
+func (c *Controller) DeleteHxConversationImage(ctx *gin.Context) {
+	conversation, ok := c.getGroupConversation(ctx)
+	if !ok {
+		return
+	}
+	c.setConversationImage(ctx, conversation, "")
+}
+
+func (c *Controller) setConversationImage(ctx *gin.Context, conversation dbqueries.Conversation, key string) {
+	conversation.ImageKey = nil
+	if key != "" {
+		conversation.ImageKey = &key
+	}
+
+	previous, err := c.DB.UpdateConversationImageKey(ctx, dbqueries.UpdateConversationImageKeyParams{
+		ID:       conversation.ID,
+		ImageKey: conversation.ImageKey,
+	})
+	if err != nil {
+		c.Images.Delete(key)
+		HandleServerError(ctx, err, "could not update conversation image")
+		return
+	}
+	c.Images.DeleteKey(previous)
+	RenderTempl(ctx, components.ConversationImage(conversation, ""))
+}
@@ -1,7 +1,136 @@
 package controller
 
+import (
+	"roommates/chatcommands"
+	"roommates/db/dbqueries"
+	"roommates/events"
+	l "roommates/locales"
+	"roommates/middleware"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// message the user sent with the answer of its chat command
+type sentMessage struct {
+	Message dbqueries.Message
+	// answer of the command, nil when the message was not a command
+	Reply *dbqueries.Message
+	// where the answer points to, "" when nowhere
+	Link string
+}
+
+// runs the chat command of the message, returns the translated answer
+//
+// messages which only look like commands ("/shrug") get no answer
+func (c *Controller) runChatCommand(ctx *gin.Context, content string, houseID pgtype.UUID) (string, string, error) {
+	inv, reply, err := c.chatCommands().Run(ctx, content, houseID, middleware.GetAuthInfo(ctx).UserID)
+	name := chatcommands.Prefix + inv.Name
+	switch {
+	case errors.Is(err, chatcommands.ErrNotCommand):
+		return "", "", nil
+	case errors.Is(err, chatcommands.ErrUnknownCommand):
+		return replyText(ctx, []l.LKMessage{{Key: l.LKChatCommandsErrorUnknown, Args: []any{name}}}), "", nil
+	case errors.Is(err, chatcommands.ErrForbidden):
+		return replyText(ctx, []l.LKMessage{{Key: l.LKChatCommandsErrorForbidden, Args: []any{name}}}), "", nil
+	case err != nil:
+		return "", "", err
+	}
+	return replyText(ctx, reply.Messages), reply.Link, nil
+}
+
+// saves the message into the conversation
+//
+// commands in house conversations are run first, their answer is saved
+// right after the message as a reply without a sender,
+// residents mentioned in house conversations are notified through the house events
+func (c *Controller) sendMessage(ctx *gin.Context, conversation dbqueries.Conversation, content string) (sentMessage, error) {
+	var sent sentMessage
+	var replyContent string
+	houseID, isHouse := conversationHouseID(ctx, c.DB, conversation)
+	if isHouse && chatcommands.IsCommand(content) {
+		var err error
+		replyContent, sent.Link, err = c.runChatCommand(ctx, content, houseID)
+		if err != nil {
+			return sent, err
+		}
+	}
+
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		return sent, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	sent.Message, err = qtx.InsertMessage(ctx, dbqueries.InsertMessageParams{
+		Content:        content,
+		ConversationID: conversation.ID,
+		SenderID:       middleware.GetAuthInfo(ctx).UserID,
+	})
+	if err != nil {
+		return sent, err
+	}
+	if isHouse {
+		if err = recordMentions(ctx, qtx, houseID, content); err != nil {
+			return sent, err
+		}
+	}
+	if replyContent != "" {
+		reply, err := qtx.InsertMessage(ctx, dbqueries.InsertMessageParams{
+			Content:        replyContent,
+			ConversationID: conversation.ID,
+			ReplyToID:      sent.Message.ID,
+		})
+		if err != nil {
+			return sent, err
+		}
+		sent.Reply = &reply
+	}
+	return sent, tx.Commit(ctx)
+}
+
+// records an event for every resident mentioned in the message,
+// usernames which are not residents and the sender mentioning themselves are left out
+func recordMentions(ctx *gin.Context, q *dbqueries.Queries, houseID pgtype.UUID, content string) error {
+	mentions := chatcommands.Mentions(content)
+	if len(mentions) == 0 {
+		return nil
+	}
+	residents, err := q.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		return err
+	}
+
+	userID := middleware.GetAuthInfo(ctx).UserID
+	var mentioned []pgtype.UUID
+	for _, mention := range mentions {
+		i := slices.IndexFunc(residents, func(r dbqueries.SelectHouseRoommatesRow) bool {
+			return strings.EqualFold(r.Username, mention)
+		})
+		if i == -1 || residents[i].ID == userID || slices.Contains(mentioned, residents[i].ID) {
+			continue
+		}
+		mentioned = append(mentioned, residents[i].ID)
+
+		err = events.Record(ctx, q, events.Event{
+			Type:    events.MessageMentioned,
+			HouseID: houseID,
+			ActorID: userID,
+			Payload: events.Payload{SubjectID: residents[i].ID.String(), Subject: residents[i].Username},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TODO: websocket communication for
-// - sending a new message
+// - sending a new message, done with sendMessage like APISendConversationMessage does
 // - receiving a new message
 // - event for message being deleted
 // - event for message being edited
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"roommates/db/dbqueries"
+	"roommates/db/dbtest"
+	"roommates/events"
+	"testing"
+)
+
+// commands in house conversations get an answer posted after them, other messages do not,
+// mentioned residents get an event
+func TestSendMessageReplies(t *testing.T) {
+	pool := dbtest.Pool(t)
+	c := &Controller{Pool: pool, DB: dbqueries.New(pool)}
+	bg := context.Background()
+
+	sender, err := c.DB.InsertUser(bg, dbqueries.InsertUserParams{Email: "sender@roommates.test", Username: "sender", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	mari, err := c.DB.InsertUser(bg, dbqueries.InsertUserParams{Email: "mari@roommates.test", Username: "Mari", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	houseID, err := c.DB.InsertHouse(bg, dbqueries.InsertHouseParams{Name: "house", MakerID: sender})
+	if err != nil {
+		t.Fatalf("could not insert house: %v", err)
+	}
+	if err = c.DB.InsertUserIntoHouse(bg, dbqueries.InsertUserIntoHouseParams{UserID: mari, HouseID: houseID}); err != nil {
+		t.Fatalf("could not add user into house: %v", err)
+	}
+	var conversation dbqueries.Conversation
+	err = pool.QueryRow(bg, "INSERT INTO conversations (recipient_ids, recipient_type) VALUES ($1, 'house') RETURNING id, recipient_ids, recipient_type",
+		[]string{houseID.String()}).Scan(&conversation.ID, &conversation.RecipientIds, &conversation.RecipientType)
+	if err != nil {
+		t.Fatalf("could not insert conversation: %v", err)
+	}
+
+	tests := []struct {
+		content string
+		reply   bool
+	}{
+		{"hello @mari, @sender and @mari again, @nobody", false},
+		{"/shrug", true},
+		{"/help", true},
+		// not a command, only starts like one
+		{"/ 12", false},
+	}
+	for _, tt := range tests {
+		sent, err := c.sendMessage(userContext(sender), conversation, tt.content)
+		if err != nil {
+			t.Fatalf("sendMessage(%q) = %v", tt.content, err)
+		}
+		if sent.Message.SenderID != sender || sent.Message.ReplyToID.Valid {
+			t.Errorf("sendMessage(%q) message = %+v, want one sent by the user", tt.content, sent.Message)
+		}
+		if (sent.Reply != nil) != tt.reply {
+			t.Fatalf("sendMessage(%q) reply = %+v, want a reply: %v", tt.content, sent.Reply, tt.reply)
+		}
+		if !tt.reply {
+			continue
+		}
+		if sent.Reply.SenderID.Valid || sent.Reply.ReplyToID != sent.Message.ID || sent.Reply.Content == "" {
+			t.Errorf("sendMessage(%q) reply = %+v, want an answer without a sender to %v", tt.content, sent.Reply, sent.Message.ID)
+		}
+	}
+
+	var replies int
+	err = pool.QueryRow(bg, "SELECT count(*) FROM messages WHERE conversation_id = $1 AND reply_to_id IS NOT NULL", conversation.ID).Scan(&replies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replies != 2 {
+		t.Errorf("conversation has %d replies, want 2", replies)
+	}
+
+	// mari once, the sender and usernames not living in the house not at all
+	got := houseEventTypes(t, c.DB, houseID)
+	if len(got) != 1 || got[0] != events.MessageMentioned {
+		t.Errorf("house events = %v, want one %v", got, events.MessageMentioned)
+	}
+}
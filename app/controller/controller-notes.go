@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"roommates/components"
 	"roommates/db/dbqueries"
+	"roommates/events"
 	g "roommates/globals"
 	"roommates/middleware"
 	"roommates/models"
@@ -57,26 +58,153 @@ func (c *Controller) populateNoteModel(ctx *gin.Context, noteID int32, houseID p
 	return &model, nil
 }
 
-type ReqHxNoteInHouseAccordion struct {
-	ID int32 `uri:"id" binding:"required"`
+// saves the note and records it into the house activity
+func (c *Controller) createNote(ctx *gin.Context, houseID pgtype.UUID, model models.Note) (int32, error) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	noteID, err := qtx.InsertNote(ctx, dbqueries.InsertNoteParams{
+		Title:   model.Title,
+		Content: model.Content,
+		MakerID: authInfo.UserID,
+		HouseID: houseID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = events.Record(ctx, qtx, events.Event{
+		Type:    events.NoteCreated,
+		HouseID: houseID,
+		ActorID: authInfo.UserID,
+		Payload: events.Payload{
+			SubjectID: strconv.Itoa(int(noteID)),
+			Subject:   model.Title,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return noteID, nil
 }
 
-func (c *Controller) HxNoteInHouseAccordion(ctx *gin.Context) {
-	var req ReqHxNoteInHouseAccordion
-	err := ctx.ShouldBindUri(&req)
+// changes title and content of the note and records it into the house activity
+func (c *Controller) updateNote(ctx *gin.Context, note dbqueries.SelectNoteRow, model models.Note) error {
+	tx, err := c.Pool.Begin(ctx.Request.Context())
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusForbidden, err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+	if err = checkNoteMatch(ctx, qtx, note); err != nil {
+		return err
+	}
+
+	err = qtx.UpdateNote(ctx, dbqueries.UpdateNoteParams{
+		ID:      note.NoteID,
+		Title:   model.Title,
+		Content: model.Content,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = events.Record(ctx, qtx, events.Event{
+		Type:    events.NoteEdited,
+		HouseID: note.HouseID,
+		ActorID: middleware.GetAuthInfo(ctx).UserID,
+		Payload: events.Payload{
+			SubjectID: strconv.Itoa(int(note.NoteID)),
+			Subject:   model.Title,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// deletes the note and records it into the house activity
+func (c *Controller) deleteNote(ctx *gin.Context, note dbqueries.SelectNoteRow) error {
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+	if err = checkNoteMatch(ctx, qtx, note); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteNote(ctx, note.NoteID); err != nil {
+		return err
+	}
+
+	err = events.Record(ctx, qtx, events.Event{
+		Type:    events.NoteDeleted,
+		HouseID: note.HouseID,
+		ActorID: middleware.GetAuthInfo(ctx).UserID,
+		Payload: events.Payload{
+			SubjectID: strconv.Itoa(int(note.NoteID)),
+			Subject:   note.Title,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// renders a page of the notes of the house for the notes page accordion
+func (c *Controller) HxHouseNotes(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+	page, ok := requirePage(ctx, notesResource)
+	if !ok {
 		return
 	}
 
-	note, err := c.DB.SelectNote(ctx, req.ID)
+	notes, next, err := c.notesPage(ctx, *houseID, page)
 	if err != nil {
-		HandleServerError(ctx, err, "could not get note")
+		handlePageError(ctx, err, "could not get notes")
 		return
 	}
 
-	tc := components.NoteInHouseAccordion(note)
-	RenderTempl(ctx, tc)
+	rows := make([]dbqueries.SelectNoteRow, 0, len(notes))
+	versions := make([]string, 0, len(notes))
+	for _, note := range notes {
+		row := dbqueries.SelectNoteRow{
+			NoteID:    note.NoteID,
+			Title:     note.Title,
+			Content:   note.Content,
+			MakerID:   note.MakerID,
+			HouseID:   note.HouseID,
+			HouseName: note.HouseName,
+			UpdatedAt: note.UpdatedAt,
+		}
+		rows = append(rows, row)
+		versions = append(versions, noteETag(row))
+	}
+	nextURL := hxNextPageURL(ctx, utils.ReplaceParam(g.RHxHouseNotes, "id", houseID.String()), next)
+	if isNotModified(ctx, listETag(ctx, "notes", nextURL, versions)) {
+		return
+	}
+	RenderTempl(ctx, components.HouseNotesList(rows, nextURL))
 }
 
 func (c *Controller) GetHxNoteModal(ctx *gin.Context) {
@@ -125,18 +253,10 @@ func (c *Controller) PostHxNote(ctx *gin.Context) {
 		return
 	}
 
-	authInfo := middleware.GetAuthInfo(ctx)
-	_, err := c.DB.InsertNote(ctx, dbqueries.InsertNoteParams{
-		Title:   model.Title,
-		Content: model.Content,
-		MakerID: authInfo.UserID,
-		HouseID: *houseID,
-	})
-	if err != nil {
+	if _, err := c.createNote(ctx, *houseID, model); err != nil {
 		HandleServerError(ctx, err, "could not save note")
 		return
 	}
-
 	utils.Redirect(ctx, "")
 }
 
@@ -158,7 +278,13 @@ func (c *Controller) DeleteNote(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.DB.DeleteNote(ctx, req.ID); err != nil {
+	note, err := c.DB.SelectNote(ctx, req.ID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get note")
+		return
+	}
+
+	if err = c.deleteNote(ctx, note); err != nil {
 		HandleServerError(ctx, err, "could not delete note")
 		return
 	}
@@ -191,12 +317,13 @@ func (c *Controller) PutHxNote(ctx *gin.Context) {
 		return
 	}
 
-	err = c.DB.UpdateNote(ctx, dbqueries.UpdateNoteParams{
-		ID:      req.ID,
-		Title:   model.Title,
-		Content: model.Content,
-	})
+	note, err := c.DB.SelectNote(ctx, req.ID)
 	if err != nil {
+		HandleServerError(ctx, err, "could not get note")
+		return
+	}
+
+	if err = c.updateNote(ctx, note, model); err != nil {
 		HandleServerError(ctx, err, "could not update note")
 		return
 	}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/notifications"
+	"roommates/utils"
+	"slices"
+
+	"github.com/a-h/templ"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+// amount of notifications shown in the notification center
+const notificationsPageSize = 50
+
+func (c *Controller) PageNotifications(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	list, err := c.DB.SelectUserNotifications(ctx, dbqueries.SelectUserNotificationsParams{
+		UserID:   authInfo.UserID,
+		PageSize: notificationsPageSize,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "error getting notifications")
+		return
+	}
+	preferences, err := notifications.Preferences(ctx, c.DB, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting notification preferences")
+		return
+	}
+
+	var tc templ.Component
+	if utils.IsRequestHTMX(ctx) {
+		tc = components.NotificationsPageContent(list, preferences)
+	} else {
+		tc = components.PageNotifications(components.SPageWrapper{
+			AuthInfo: authInfo,
+			PathURL:  ctx.Request.URL.Path,
+		}, list, preferences)
+	}
+	RenderTempl(ctx, tc)
+}
+
+func (c *Controller) HxNotificationBell(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	unread, err := c.DB.CountUnreadNotifications(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error counting notifications")
+		return
+	}
+	RenderTempl(ctx, components.NotificationBell(unread))
+}
+
+// intended to be used with RNotificationID
+type ReqNotification struct {
+	ID int64 `uri:"id" binding:"required"`
+}
+
+// marks the notification as read and redirects to where it leads
+func (c *Controller) ReadNotification(ctx *gin.Context) {
+	var req ReqNotification
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	authInfo := middleware.GetAuthInfo(ctx)
+	link, err := c.DB.MarkNotificationRead(ctx, dbqueries.MarkNotificationReadParams{
+		ID:     req.ID,
+		UserID: authInfo.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+			return
+		}
+		HandleServerError(ctx, err, "could not mark notification as read")
+		return
+	}
+
+	if link == "" {
+		link = g.RNotifications
+	}
+	utils.Redirect(ctx, link)
+}
+
+func (c *Controller) ReadAllNotifications(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	if err := c.DB.MarkAllNotificationsRead(ctx, authInfo.UserID); err != nil {
+		HandleServerError(ctx, err, "could not mark notifications as read")
+		return
+	}
+
+	list, err := c.DB.SelectUserNotifications(ctx, dbqueries.SelectUserNotificationsParams{
+		UserID:   authInfo.UserID,
+		PageSize: notificationsPageSize,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "error getting notifications")
+		return
+	}
+
+	ctx.Header(string(g.HHXTrigger), g.EvNotificationsChanged)
+	RenderTempl(ctx, components.NotificationList(list))
+}
+
+type ReqNotificationPreferences struct {
+	// enabled kinds, the rest are disabled
+	Kinds []string `form:"kinds[]"`
+}
+
+func (c *Controller) PostHxNotificationPreferences(ctx *gin.Context) {
+	var req ReqNotificationPreferences
+	ctx.ShouldBind(&req)
+
+	authInfo := middleware.GetAuthInfo(ctx)
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		HandleServerError(ctx, err, "no business pool party :(")
+		return
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	preferences := map[notifications.Kind]bool{}
+	for _, kind := range notifications.Kinds {
+		preferences[kind] = slices.Contains(req.Kinds, string(kind))
+		err = qtx.UpsertNotificationPreference(ctx, dbqueries.UpsertNotificationPreferenceParams{
+			UserID:  authInfo.UserID,
+			Kind:    string(kind),
+			Enabled: preferences[kind],
+		})
+		if err != nil {
+			HandleServerError(ctx, err, "could not save notification preferences")
+			return
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		HandleServerError(ctx, err, "error commiting transaction")
+		return
+	}
+	RenderTempl(ctx, components.NotificationPreferencesForm(preferences, true))
+}
@@ -0,0 +1,372 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	"roommates/gintemplrenderer"
+	g "roommates/globals"
+	"roommates/locales"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/oidcauth"
+	"roommates/rdb"
+	"roommates/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// stored with the state while the user is at the provider
+type pendingOIDC struct {
+	oidcauth.AuthRequest
+	Provider string `json:"provider"`
+	// set when the provider is connected from the profile page
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+// errors of connecting a provider are shown on the profile page with this query parameter
+const identityErrorParam = "identity_error"
+
+var identityErrors = map[string]locales.LK{
+	"failed": locales.LKOidcErrorFailed,
+	"state":  locales.LKOidcErrorState,
+	"taken":  locales.LKOidcErrorTaken,
+}
+
+func identityErrorMessage(ctx *gin.Context) string {
+	key, ok := identityErrors[ctx.Query(identityErrorParam)]
+	if !ok {
+		return ""
+	}
+	return utils.T(ctx.Request.Context(), key, "")
+}
+
+// 404 when the provider is not configured
+func (c *Controller) requireProvider(ctx *gin.Context) *oidcauth.Provider {
+	provider := c.OIDC.Get(ctx.Param("provider"))
+	if provider == nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, errors.New("unknown provider"))
+	}
+	return provider
+}
+
+// sends the user to the provider, the state cookie ties the callback to this browser
+func (c *Controller) redirectToProvider(ctx *gin.Context, provider *oidcauth.Provider, userID pgtype.UUID) {
+	req := oidcauth.NewAuthRequest()
+	state, err := c.RH.CreateToken(ctx, rdb.TPOIDC, pendingOIDC{
+		AuthRequest: req,
+		Provider:    provider.Name,
+		UserID:      userID,
+	}, rdb.EOIDC)
+	if err != nil {
+		HandleServerError(ctx, err, "could not start sign-in")
+		return
+	}
+
+	ctx.SetCookie(
+		string(g.COIDCState),
+		state,
+		int(rdb.EOIDC.Seconds()),
+		"/",
+		"localhost",
+		true,
+		true,
+	)
+	ctx.Redirect(http.StatusSeeOther, provider.AuthURL(state, req))
+}
+
+// sign-in with the provider failed, login page is shown with the error
+func (c *Controller) oidcSignInFailed(ctx *gin.Context, key locales.LK) {
+	model := models.Login{ModelBase: models.ModelBase{
+		Initial: true,
+		Error:   utils.T(ctx.Request.Context(), key, ""),
+	}}
+	r := gintemplrenderer.New(ctx.Request.Context(), http.StatusUnauthorized, components.PageLogin(model, c.OIDC.List()))
+	ctx.Render(r.Status, r)
+}
+
+func identityEmail(identity *oidcauth.Identity) *string {
+	if identity.Email == "" {
+		return nil
+	}
+	return &identity.Email
+}
+
+// username suggested by the provider if it is valid, otherwise made from the email
+func identityUsername(identity *oidcauth.Identity) string {
+	for _, username := range []string{
+		strings.TrimSpace(identity.Username),
+		strings.TrimSpace(strings.Split(identity.Email, "@")[0]),
+	} {
+		model := models.Register{Username: username}
+		if len(model.ValidateUsername()) == 0 {
+			return username
+		}
+	}
+	return "user-" + strings.ToLower(rand.Text()[:6])
+}
+
+// signs in the user of the identity
+func (c *Controller) signInWithIdentity(ctx *gin.Context, identity *oidcauth.Identity) {
+	userID, username, err := c.identityUser(ctx, identity)
+	switch {
+	case err == nil:
+		c.signInOrStartTwoFactor(ctx, userID, username)
+	case errors.Is(err, g.ErrorEmailNotVerified):
+		c.oidcSignInFailed(ctx, locales.LKOidcErrorEmailNotVerified)
+	case errors.Is(err, g.ErrorLinkUnverified):
+		c.oidcSignInFailed(ctx, locales.LKOidcErrorLinkUnverified)
+	case errors.Is(err, g.ErrorIdentityTaken):
+		c.oidcSignInFailed(ctx, locales.LKOidcErrorTaken)
+	default:
+		HandleServerError(ctx, err, "error signing in with identity")
+	}
+}
+
+// user of the identity
+//
+// unknown identities are linked to the user with the same email, or a new user is made,
+// only when the provider has verified the email
+//
+//   - ErrorEmailNotVerified -- provider has not verified the email
+//   - ErrorLinkUnverified -- user with the email has not verified it
+//   - ErrorIdentityTaken -- user with the email has another account of the provider
+func (c *Controller) identityUser(ctx context.Context, identity *oidcauth.Identity) (pgtype.UUID, string, error) {
+	user, err := c.DB.SelectIdentityUser(ctx, dbqueries.SelectIdentityUserParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	if err == nil {
+		return user.ID, user.Username, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.UUID{}, "", err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return pgtype.UUID{}, "", g.ErrorEmailNotVerified
+	}
+
+	existing, err := c.DB.SelectUserByEmail(ctx, identity.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.registerIdentityUser(ctx, identity)
+	}
+	if err != nil {
+		return pgtype.UUID{}, "", err
+	}
+
+	// whoever registered the email might not own it, the owner has to sign in with the password first
+	if !existing.EmailValidated {
+		return pgtype.UUID{}, "", g.ErrorLinkUnverified
+	}
+	err = c.DB.InsertUserIdentity(ctx, dbqueries.InsertUserIdentityParams{
+		UserID:   existing.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identityEmail(identity),
+	})
+	if isUniqueViolation(err) {
+		return pgtype.UUID{}, "", g.ErrorIdentityTaken
+	}
+	if err != nil {
+		return pgtype.UUID{}, "", err
+	}
+	return existing.ID, existing.Username, nil
+}
+
+// new users get a random password which is not marked as set, a real one can be set with the password reset
+func (c *Controller) registerIdentityUser(ctx context.Context, identity *oidcauth.Identity) (pgtype.UUID, string, error) {
+	password, err := hashPassword(rand.Text())
+	if err != nil {
+		return pgtype.UUID{}, "", err
+	}
+	username := identityUsername(identity)
+
+	tx, err := c.Pool.Begin(ctx)
+	if err != nil {
+		return pgtype.UUID{}, "", err
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	userID, err := qtx.InsertVerifiedUser(ctx, dbqueries.InsertVerifiedUserParams{
+		Email:    identity.Email,
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		return pgtype.UUID{}, "", err
+	}
+	err = qtx.InsertUserIdentity(ctx, dbqueries.InsertUserIdentityParams{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identityEmail(identity),
+	})
+	if err != nil {
+		return pgtype.UUID{}, "", err
+	}
+	return userID, username, tx.Commit(ctx)
+}
+
+// connects the identity to the user who started it from the profile page
+func (c *Controller) connectIdentity(ctx *gin.Context, userID pgtype.UUID, identity *oidcauth.Identity) {
+	failed := func(reason string) {
+		utils.Redirect(ctx, g.RProfile+"?"+identityErrorParam+"="+reason)
+	}
+
+	user, err := c.DB.SelectIdentityUser(ctx, dbqueries.SelectIdentityUserParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	if err == nil {
+		if user.ID != userID {
+			failed("taken")
+			return
+		}
+		utils.Redirect(ctx, g.RProfile)
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		HandleServerError(ctx, err, "error getting identity")
+		return
+	}
+
+	err = c.DB.InsertUserIdentity(ctx, dbqueries.InsertUserIdentityParams{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identityEmail(identity),
+	})
+	if err != nil {
+		// another account of the same provider is already connected
+		if isUniqueViolation(err) {
+			failed("taken")
+			return
+		}
+		HandleServerError(ctx, err, "error connecting identity")
+		return
+	}
+	utils.Redirect(ctx, g.RProfile)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	// https://www.postgresql.org/docs/current/errcodes-appendix.html
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+//------------------------------------------------------------------------------
+
+// sends the user to the provider for signing in
+func (c *Controller) PageLoginOIDC(ctx *gin.Context) {
+	if middleware.GetAuthInfo(ctx) != nil {
+		ctx.Redirect(http.StatusSeeOther, "/")
+		return
+	}
+	provider := c.requireProvider(ctx)
+	if provider == nil {
+		return
+	}
+	c.redirectToProvider(ctx, provider, pgtype.UUID{})
+}
+
+// sends the user to the provider for connecting it to their account
+func (c *Controller) ConnectIdentity(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	provider := c.requireProvider(ctx)
+	if provider == nil {
+		return
+	}
+	c.redirectToProvider(ctx, provider, authInfo.UserID)
+}
+
+// provider sends the user back here, both for signing in and connecting
+func (c *Controller) OIDCCallback(ctx *gin.Context) {
+	provider := c.requireProvider(ctx)
+	if provider == nil {
+		return
+	}
+
+	state := ctx.Query("state")
+	cookieState, _ := ctx.Cookie(string(g.COIDCState))
+	utils.DeleteCookie(ctx, g.COIDCState)
+	if state == "" || state != cookieState {
+		c.oidcSignInFailed(ctx, locales.LKOidcErrorState)
+		return
+	}
+
+	var pending pendingOIDC
+	found, err := c.RH.ConsumeToken(ctx, rdb.TPOIDC, state, &pending)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting sign-in state")
+		return
+	}
+	if !found || pending.Provider != provider.Name {
+		c.oidcSignInFailed(ctx, locales.LKOidcErrorState)
+		return
+	}
+
+	connecting := pending.UserID.Valid
+	if connecting {
+		// the browser has to still be signed in as the user who started it
+		authInfo := middleware.GetAuthInfo(ctx)
+		if authInfo == nil || authInfo.UserID != pending.UserID {
+			utils.Redirect(ctx, g.RLogin)
+			return
+		}
+	}
+
+	// canceled or denied at the provider
+	if ctx.Query("error") != "" {
+		if connecting {
+			utils.Redirect(ctx, g.RProfile+"?"+identityErrorParam+"=failed")
+			return
+		}
+		c.oidcSignInFailed(ctx, locales.LKOidcErrorFailed)
+		return
+	}
+
+	identity, err := provider.Exchange(ctx.Request.Context(), ctx.Query("code"), pending.AuthRequest)
+	if err != nil {
+		log.Warn().Err(err).Str("provider", provider.Name).Msg("OIDC code exchange failed")
+		if connecting {
+			utils.Redirect(ctx, g.RProfile+"?"+identityErrorParam+"=failed")
+			return
+		}
+		c.oidcSignInFailed(ctx, locales.LKOidcErrorFailed)
+		return
+	}
+
+	if connecting {
+		c.connectIdentity(ctx, pending.UserID, identity)
+		return
+	}
+	c.signInWithIdentity(ctx, identity)
+}
+
+func (c *Controller) DeleteHxIdentity(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	_, err := c.DB.DeleteUserIdentity(ctx, dbqueries.DeleteUserIdentityParams{
+		UserID:   authInfo.UserID,
+		Provider: ctx.Param("provider"),
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not disconnect")
+		return
+	}
+
+	identities, err := c.DB.SelectUserIdentities(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting identities")
+		return
+	}
+	RenderTempl(ctx, components.IdentitySection(c.OIDC.List(), identities, ""))
+}
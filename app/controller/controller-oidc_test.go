@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"roommates/db/dbqueries"
+	"roommates/db/dbtest"
+	g "roommates/globals"
+	"roommates/oidcauth"
+	"roommates/oidcauth/oidctest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// controller with the mock provider, the database is set by the tests that need it
+func newOIDCController(t *testing.T) (*Controller, *oidctest.Provider) {
+	t.Helper()
+	mock := oidctest.NewProvider(t)
+	providers := oidcauth.New(context.Background(), mock.Client(), "https://roommates.test", []oidcauth.ProviderConfig{mock.Config("mock")})
+	return &Controller{OIDC: providers}, mock
+}
+
+// identity the mock provider gives for the user
+func signInAtProvider(t *testing.T, c *Controller, mock *oidctest.Provider, user oidctest.User) *oidcauth.Identity {
+	t.Helper()
+	provider := c.OIDC.Get("mock")
+	req := oidcauth.NewAuthRequest()
+	code := mock.Authorize(t, provider.AuthURL("state", req), user)
+	identity, err := provider.Exchange(context.Background(), code, req)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	return identity
+}
+
+func TestOIDCCallbackStateMismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := newOIDCController(t)
+
+	for name, cookie := range map[string]string{
+		"other state": "state-of-another-sign-in",
+		"no cookie":   "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/login/oidc/mock/callback?state=state&code=code", nil)
+			if cookie != "" {
+				ctx.Request.AddCookie(&http.Cookie{Name: string(g.COIDCState), Value: cookie})
+			}
+			ctx.Params = gin.Params{{Key: "provider", Value: "mock"}}
+
+			// the state is checked before the token store, which the controller does not have
+			c.OIDCCallback(ctx)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestIdentityUserLinking(t *testing.T) {
+	c, mock := newOIDCController(t)
+	pool := dbtest.Pool(t)
+	c.Pool, c.DB = pool, dbqueries.New(pool)
+	ctx := context.Background()
+
+	userID, err := c.DB.InsertUser(ctx, dbqueries.InsertUserParams{
+		Email:    "user@roommates.test",
+		Username: "user",
+		Password: "not-a-hash",
+	})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	verified := oidctest.User{
+		Subject:       "subject-1",
+		Email:         "user@roommates.test",
+		EmailVerified: true,
+	}
+
+	// whoever registered the email has not shown they own it
+	identity := signInAtProvider(t, c, mock, verified)
+	if _, _, err = c.identityUser(ctx, identity); !errors.Is(err, g.ErrorLinkUnverified) {
+		t.Fatalf("identityUser = %v, want ErrorLinkUnverified", err)
+	}
+
+	// the provider has not verified the email
+	unverified := verified
+	unverified.EmailVerified = false
+	identity = signInAtProvider(t, c, mock, unverified)
+	if _, _, err = c.identityUser(ctx, identity); !errors.Is(err, g.ErrorEmailNotVerified) {
+		t.Fatalf("identityUser = %v, want ErrorEmailNotVerified", err)
+	}
+
+	if _, err = c.DB.SetEmailValidated(ctx, dbqueries.SetEmailValidatedParams{ID: userID, Email: verified.Email}); err != nil {
+		t.Fatalf("could not validate email: %v", err)
+	}
+	identity = signInAtProvider(t, c, mock, verified)
+	gotID, _, err := c.identityUser(ctx, identity)
+	if err != nil || gotID != userID {
+		t.Fatalf("identityUser = %v, %v, want the user with the email", gotID, err)
+	}
+
+	// linked now, found by the identity even when the email changes at the provider
+	changed := verified
+	changed.Email = "new@roommates.test"
+	identity = signInAtProvider(t, c, mock, changed)
+	if gotID, _, err = c.identityUser(ctx, identity); err != nil || gotID != userID {
+		t.Errorf("identityUser = %v, %v, want the linked user", gotID, err)
+	}
+}
+
+func TestIdentityUserRegisters(t *testing.T) {
+	c, mock := newOIDCController(t)
+	pool := dbtest.Pool(t)
+	c.Pool, c.DB = pool, dbqueries.New(pool)
+	ctx := context.Background()
+
+	identity := signInAtProvider(t, c, mock, oidctest.User{
+		Subject:           "subject-2",
+		Email:             "new@roommates.test",
+		EmailVerified:     true,
+		PreferredUsername: "newuser",
+	})
+	userID, username, err := c.identityUser(ctx, identity)
+	if err != nil {
+		t.Fatalf("identityUser: %v", err)
+	}
+	if username != "newuser" {
+		t.Errorf("username = %q, want newuser", username)
+	}
+	validated, err := c.DB.IsEmailValidated(ctx, userID)
+	if err != nil || !validated {
+		t.Errorf("email of the new user is not validated: %v", err)
+	}
+
+	// the random password is not known to the user, account deletion is confirmed by email
+	passwordSet, err := c.DB.IsUserPasswordSet(ctx, userID)
+	if err != nil || passwordSet {
+		t.Errorf("IsUserPasswordSet = %v, %v, want false", passwordSet, err)
+	}
+	_, err = c.DB.UpdateUserPassword(ctx, dbqueries.UpdateUserPasswordParams{ID: userID, Password: "not-a-hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passwordSet, err = c.DB.IsUserPasswordSet(ctx, userID); err != nil || !passwordSet {
+		t.Errorf("IsUserPasswordSet after setting a password = %v, %v, want true", passwordSet, err)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"roommates/components"
+	g "roommates/globals"
 	"roommates/middleware"
+	"roommates/rdb"
 	"roommates/utils"
 
 	"github.com/a-h/templ"
@@ -33,29 +35,116 @@ func (c *Controller) PageMain(ctx *gin.Context) {
 }
 
 func (c *Controller) PageProfile(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	profile, err := c.DB.SelectUserProfile(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting profile")
+		return
+	}
+	sessions, err := c.RH.UserSessions(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting sessions")
+		return
+	}
+	twoFactor, err := c.twoFactorState(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting two-factor state")
+		return
+	}
+	accessTokens, err := c.DB.SelectUserAccessTokens(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting access tokens")
+		return
+	}
+	calendarFeeds, err := c.DB.SelectUserCalendarFeeds(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting calendar feeds")
+		return
+	}
+	houses, err := c.DB.UserHouses(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting houses")
+		return
+	}
+	identities, err := c.DB.SelectUserIdentities(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting identities")
+		return
+	}
+	deletionScheduledAt, err := c.DB.SelectDeletionScheduledAt(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting account deletion")
+		return
+	}
+	passwordSet, err := c.DB.IsUserPasswordSet(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting user password")
+		return
+	}
+	discoverability, err := c.DB.SelectUserDiscoverability(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting discoverability")
+		return
+	}
+	blocks, err := c.DB.SelectUserBlocks(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting blocked users")
+		return
+	}
+
+	data := components.ProfileData{
+		Profile:          profileModel(profile),
+		AvatarKey:        authInfo.AvatarKey,
+		Sessions:         sessions,
+		CurrentSessionID: rdb.SessionID(authInfo.Key),
+		TwoFactor:        twoFactor,
+		AccessTokens:     accessTokens,
+		CalendarFeeds:    calendarFeeds,
+		Houses:           houses,
+		Providers:        c.OIDC.List(),
+		Identities:       identities,
+		IdentityError:    identityErrorMessage(ctx),
+		Account: components.AccountState{
+			ScheduledAt: deletionScheduledAt.Time,
+			PasswordSet: passwordSet,
+		},
+		Discoverability: discoverability,
+		Blocks:          blocks,
+	}
+
 	var tc templ.Component
 	if utils.IsRequestHTMX(ctx) {
-		tc = components.ProfilePageContent()
+		tc = components.ProfilePageContent(data)
 	} else {
-		authInfo := middleware.GetAuthInfo(ctx)
 		tc = components.PageProfile(components.SPageWrapper{
 			AuthInfo: authInfo,
 			PathURL:  ctx.Request.URL.Path,
-		})
+		}, data)
 	}
 	RenderTempl(ctx, tc)
 }
 
 func (c *Controller) PagePayments(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	page, ok := requirePage(ctx, paymentsResource)
+	if !ok {
+		return
+	}
+	payments, next, err := c.paymentsPage(ctx, authInfo.UserID, page)
+	if err != nil {
+		handlePageError(ctx, err, "error getting payments")
+		return
+	}
+	nextURL := hxNextPageURL(ctx, g.RHxPaymentsList, next)
+
 	var tc templ.Component
 	if utils.IsRequestHTMX(ctx) {
-		tc = components.PaymentsPageContent()
+		tc = components.PaymentsPageContent(payments, nextURL)
 	} else {
-		authInfo := middleware.GetAuthInfo(ctx)
 		tc = components.PagePayments(components.SPageWrapper{
 			AuthInfo: authInfo,
 			PathURL:  ctx.Request.URL.Path,
-		})
+		}, payments, nextURL)
 	}
 	RenderTempl(ctx, tc)
 }
@@ -96,20 +185,30 @@ func (c *Controller) PageMessaging(ctx *gin.Context) {
 
 func (c *Controller) PageHouses(ctx *gin.Context) {
 	authInfo := middleware.GetAuthInfo(ctx)
-	houses, err := c.DB.UserHouses(ctx, authInfo.UserID)
+	page, ok := requirePage(ctx, housesResource)
+	if !ok {
+		return
+	}
+	asideHouses, err := c.DB.UserHouses(ctx, authInfo.UserID)
 	if err != nil {
 		HandleServerError(ctx, err, "error getting houses")
 		return
 	}
+	houses, next, err := c.housesPage(ctx, authInfo.UserID, page)
+	if err != nil {
+		handlePageError(ctx, err, "error getting houses")
+		return
+	}
+	nextURL := hxNextPageURL(ctx, g.RHxHousesList, next)
 
 	var tc templ.Component
 	if utils.IsRequestHTMX(ctx) {
-		tc = components.HousesPageContent(houses)
+		tc = components.HousesPageContent(asideHouses, houses, nextURL)
 	} else {
 		tc = components.PageHouses(components.SPageWrapper{
 			AuthInfo: authInfo,
 			PathURL:  ctx.Request.URL.Path,
-		}, houses)
+		}, asideHouses, houses, nextURL)
 	}
 	RenderTempl(ctx, tc)
 }
@@ -123,16 +222,36 @@ func (c *Controller) PageHouse(ctx *gin.Context) {
 		utils.ErrorResponse(ctx, http.StatusForbidden, fmt.Errorf("id invalid: %w", err))
 		return
 	}
+	if !isHouseResident(ctx, c.DB, houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+
+	house, err := c.DB.SelectHouse(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting house")
+		return
+	}
+	reminders, err := c.DB.SelectHouseReminders(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting reminders")
+		return
+	}
+	residents, err := c.DB.SelectHouseRoommates(ctx, houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting residents")
+		return
+	}
 
 	var tc templ.Component
 	if utils.IsRequestHTMX(ctx) {
-		tc = components.HousePageContent()
+		tc = components.HousePageContent(house, reminders, residents)
 	} else {
 		authInfo := middleware.GetAuthInfo(ctx)
 		tc = components.PageHouse(components.SPageWrapper{
 			AuthInfo: authInfo,
 			PathURL:  ctx.Request.URL.Path,
-		})
+		}, house, reminders, residents)
 	}
 	RenderTempl(ctx, tc)
 }
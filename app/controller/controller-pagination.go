@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"net/http"
+	"net/url"
+	"roommates/db/dbqueries"
+	"roommates/pagination"
+	"roommates/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// what the lists can be sorted and filtered by
+var (
+	housesResource = pagination.Resource{
+		Sorts:       []string{"name", "created_at"},
+		DefaultSort: pagination.Sort{Field: "name"},
+		Filters:     []string{"name"},
+	}
+	notesResource = pagination.Resource{
+		Sorts:       []string{"title", "updated_at"},
+		DefaultSort: pagination.Sort{Field: "updated_at", Desc: true},
+		Filters:     []string{"title", "maker_id"},
+	}
+	paymentsResource = pagination.Resource{
+		Sorts:       []string{"name", "created_at"},
+		DefaultSort: pagination.Sort{Field: "created_at", Desc: true},
+		Filters:     []string{"house_id", "status"},
+	}
+	messagesResource = pagination.Resource{
+		Sorts:       []string{"created_at"},
+		DefaultSort: pagination.Sort{Field: "created_at", Desc: true},
+		Filters:     []string{"sender_id"},
+	}
+)
+
+func isPaginationError(err error) bool {
+	return errors.Is(err, pagination.ErrInvalidLimit) ||
+		errors.Is(err, pagination.ErrInvalidCursor) ||
+		errors.Is(err, pagination.ErrInvalidSort) ||
+		errors.Is(err, pagination.ErrInvalidFilter)
+}
+
+// page from the query, responds with 400 when it is not valid
+func requirePage(ctx *gin.Context, r pagination.Resource) (pagination.Page, bool) {
+	page, err := pagination.Parse(ctx.Request.URL.Query(), r)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return page, false
+	}
+	return page, true
+}
+
+// responds to an error from loading a page, invalid cursors and filters are the fault of the client
+func handlePageError(ctx *gin.Context, err error, msg string) {
+	if isPaginationError(err) {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+	HandleServerError(ctx, err, msg)
+}
+
+// url of the next page of a htmx list fragment, keeps the sort and filters of the current request
+//
+//	"" -- there is no next page
+func hxNextPageURL(ctx *gin.Context, route, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	return pagination.NextURL(&url.URL{Path: route, RawQuery: ctx.Request.URL.RawQuery}, cursor)
+}
+
+// time in cursors and API responses
+func formatTime(t pgtype.Timestamptz) string {
+	return t.Time.UTC().Format(time.RFC3339Nano)
+}
+
+func parseCursorTime(value string) (pgtype.Timestamptz, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return pgtype.Timestamptz{}, pagination.ErrInvalidCursor
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
+
+func parseCursorUUID(value string) (pgtype.UUID, error) {
+	var id pgtype.UUID
+	if err := id.Scan(value); err != nil || !id.Valid {
+		return id, pagination.ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// uuid filter, not set when the filter is not given
+func parseFilterUUID(page pagination.Page, name string) (pgtype.UUID, error) {
+	var id pgtype.UUID
+	value, ok := page.Filters[name]
+	if !ok {
+		return id, nil
+	}
+	if err := id.Scan(value); err != nil || !id.Valid {
+		return id, pagination.ErrInvalidFilter
+	}
+	return id, nil
+}
+
+// houses of the user
+//
+//	string -- cursor of the next page, empty on the last page
+func (c *Controller) housesPage(ctx *gin.Context, userID pgtype.UUID, page pagination.Page) ([]dbqueries.SelectUserHousesPageRow, string, error) {
+	params := dbqueries.SelectUserHousesPageParams{
+		UserID:     userID,
+		NameFilter: pagination.EscapeLike(page.Filters["name"]),
+		Sort:       page.Sort.String(),
+		PageSize:   page.FetchLimit(),
+	}
+	byTime := page.Sort.Field == "created_at"
+	if page.After != nil {
+		var err error
+		if params.AfterID, err = parseCursorUUID(page.After.ID); err != nil {
+			return nil, "", err
+		}
+		if byTime {
+			if params.AfterTime, err = parseCursorTime(page.After.Value); err != nil {
+				return nil, "", err
+			}
+		} else {
+			params.AfterName = page.After.Value
+		}
+	}
+
+	houses, err := c.DB.SelectUserHousesPage(ctx, params)
+	if err != nil {
+		return nil, "", err
+	}
+	houses, hasMore := pagination.Trim(page, houses)
+	if !hasMore {
+		return houses, "", nil
+	}
+	last := houses[len(houses)-1]
+	value := last.Name
+	if byTime {
+		value = formatTime(last.CreatedAt)
+	}
+	return houses, page.CursorAfter(value, last.ID.String()), nil
+}
+
+// notes of the house
+//
+//	string -- cursor of the next page, empty on the last page
+func (c *Controller) notesPage(ctx *gin.Context, houseID pgtype.UUID, page pagination.Page) ([]dbqueries.SelectHouseNotesPageRow, string, error) {
+	makerID, err := parseFilterUUID(page, "maker_id")
+	if err != nil {
+		return nil, "", err
+	}
+	params := dbqueries.SelectHouseNotesPageParams{
+		HouseID:       houseID,
+		TitleFilter:   pagination.EscapeLike(page.Filters["title"]),
+		MakerIDFilter: makerID,
+		Sort:          page.Sort.String(),
+		PageSize:      page.FetchLimit(),
+	}
+	byTime := page.Sort.Field == "updated_at"
+	if page.After != nil {
+		afterID, err := strconv.ParseInt(page.After.ID, 10, 32)
+		if err != nil || afterID < 1 {
+			return nil, "", pagination.ErrInvalidCursor
+		}
+		params.AfterID = int32(afterID)
+		if byTime {
+			if params.AfterTime, err = parseCursorTime(page.After.Value); err != nil {
+				return nil, "", err
+			}
+		} else {
+			params.AfterTitle = page.After.Value
+		}
+	}
+
+	notes, err := c.DB.SelectHouseNotesPage(ctx, params)
+	if err != nil {
+		return nil, "", err
+	}
+	notes, hasMore := pagination.Trim(page, notes)
+	if !hasMore {
+		return notes, "", nil
+	}
+	last := notes[len(notes)-1]
+	value := last.Title
+	if byTime {
+		value = formatTime(last.UpdatedAt)
+	}
+	return notes, page.CursorAfter(value, strconv.Itoa(int(last.NoteID))), nil
+}
+
+// payments the user has requested or has to pay
+//
+//	string -- cursor of the next page, empty on the last page
+func (c *Controller) paymentsPage(ctx *gin.Context, userID pgtype.UUID, page pagination.Page) ([]dbqueries.SelectUserPaymentsPageRow, string, error) {
+	houseID, err := parseFilterUUID(page, "house_id")
+	if err != nil {
+		return nil, "", err
+	}
+	status, ok := page.Filters["status"]
+	if ok && status != string(dbqueries.HousePaymentStatusDone) && status != string(dbqueries.HousePaymentStatusIncomplete) {
+		return nil, "", pagination.ErrInvalidFilter
+	}
+	params := dbqueries.SelectUserPaymentsPageParams{
+		UserID:        userID,
+		HouseIDFilter: houseID,
+		StatusFilter:  status,
+		Sort:          page.Sort.String(),
+		PageSize:      page.FetchLimit(),
+	}
+	byTime := page.Sort.Field == "created_at"
+	if page.After != nil {
+		if params.AfterID, err = parseCursorUUID(page.After.ID); err != nil {
+			return nil, "", err
+		}
+		if byTime {
+			if params.AfterTime, err = parseCursorTime(page.After.Value); err != nil {
+				return nil, "", err
+			}
+		} else {
+			params.AfterName = page.After.Value
+		}
+	}
+
+	payments, err := c.DB.SelectUserPaymentsPage(ctx, params)
+	if err != nil {
+		return nil, "", err
+	}
+	payments, hasMore := pagination.Trim(page, payments)
+	if !hasMore {
+		return payments, "", nil
+	}
+	last := payments[len(payments)-1]
+	value := last.PaymentName
+	if byTime {
+		value = formatTime(last.CreatedAt)
+	}
+	return payments, page.CursorAfter(value, last.ID.String()), nil
+}
+
+// messages of the conversation
+//
+//	string -- cursor of the next page, empty on the last page
+func (c *Controller) messagesPage(ctx *gin.Context, conversationID pgtype.UUID, page pagination.Page) ([]dbqueries.SelectConversationMessagesPageRow, string, error) {
+	senderID, err := parseFilterUUID(page, "sender_id")
+	if err != nil {
+		return nil, "", err
+	}
+	params := dbqueries.SelectConversationMessagesPageParams{
+		ConversationID: conversationID,
+		SenderIDFilter: senderID,
+		Sort:           page.Sort.String(),
+		PageSize:       page.FetchLimit(),
+	}
+	if page.After != nil {
+		if params.AfterID, err = parseCursorUUID(page.After.ID); err != nil {
+			return nil, "", err
+		}
+		if params.AfterTime, err = parseCursorTime(page.After.Value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	messages, err := c.DB.SelectConversationMessagesPage(ctx, params)
+	if err != nil {
+		return nil, "", err
+	}
+	messages, hasMore := pagination.Trim(page, messages)
+	if !hasMore {
+		return messages, "", nil
+	}
+	last := messages[len(messages)-1]
+	return messages, page.CursorAfter(formatTime(last.CreatedAt), last.ID.String()), nil
+}
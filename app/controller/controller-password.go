@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	"roommates/gintemplrenderer"
+	g "roommates/globals"
+	"roommates/locales"
+	"roommates/mailer"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/rdb"
+	"roommates/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// how often a reset link can be sent to the same email
+const passwordResetCooldown = 2 * time.Minute
+
+// stored with the password reset token
+type passwordReset struct {
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	return string(hashed), err
+}
+
+// sets the new password and signs the user out everywhere
+func (c *Controller) setPassword(ctx *gin.Context, userID pgtype.UUID, password string) error {
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	_, err = c.DB.UpdateUserPassword(ctx, dbqueries.UpdateUserPasswordParams{
+		ID:       userID,
+		Password: hashed,
+	})
+	if err != nil {
+		return err
+	}
+	return c.RH.DeleteUserSessions(ctx, userID)
+}
+
+// sends the reset link when the account exists and the cooldown has passed,
+// the caller should not tell the user whether it was sent
+func (c *Controller) sendPasswordResetEmail(ctx *gin.Context, email string) error {
+	allowed, err := c.RH.Cooldown(ctx, "password-reset:"+strings.ToLower(email), passwordResetCooldown)
+	if err != nil || !allowed {
+		return err
+	}
+
+	creds, err := c.DB.GetUserCredentials(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := c.RH.CreateToken(ctx, rdb.TPPasswordReset, passwordReset{UserID: creds.ID}, rdb.EPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	url := c.Mailer.URL(utils.ReplaceParam(g.RResetPassword, "token", token))
+	return c.Mailer.Send(ctx.Request.Context(), mailer.Mail{
+		To:      creds.Email,
+		UserID:  creds.ID,
+		Subject: utils.T(ctx.Request.Context(), locales.LKEmailPasswordResetSubject, "Reset your password"),
+		Body:    components.EmailPasswordReset(url),
+	})
+}
+
+func (c *Controller) PageForgotPassword(ctx *gin.Context) {
+	method := ctx.Request.Method
+	render := func(model models.ForgotPassword) {
+		RenderTempl(ctx, components.PageForgotPassword(model))
+	}
+
+	switch method {
+	case http.MethodGet:
+		render(models.ForgotPassword{Login: models.Login{ModelBase: models.ModelBase{Initial: true}}})
+	case http.MethodPost:
+		var model models.ForgotPassword
+		ctx.ShouldBind(&model)
+
+		isValid, _ := model.IsValid()
+		if !isValid {
+			render(model)
+			return
+		}
+
+		if err := c.sendPasswordResetEmail(ctx, model.Email); err != nil {
+			HandleServerError(ctx, err, "could not send password reset email")
+			return
+		}
+		model.Sent = true
+		render(model)
+	default:
+		ctx.String(http.StatusMethodNotAllowed, "method %s not allowed", method)
+	}
+}
+
+// the link from the password reset email
+//
+// GET only checks the token, it is used up once the new password is set
+func (c *Controller) PageResetPassword(ctx *gin.Context) {
+	method := ctx.Request.Method
+	token := ctx.Param("token")
+	render := func(status int, model models.ResetPassword, state components.PasswordResetState) {
+		model.Token = token
+		r := gintemplrenderer.New(ctx.Request.Context(), status, components.PageResetPassword(model, state))
+		ctx.Render(r.Status, r)
+	}
+
+	var value passwordReset
+	found, err := c.RH.PeekToken(ctx, rdb.TPPasswordReset, token, &value)
+	if err != nil {
+		HandleServerError(ctx, err, "unable to check password reset token")
+		return
+	}
+	if !found {
+		render(http.StatusForbidden, models.ResetPassword{}, components.PasswordResetInvalid)
+		return
+	}
+
+	switch method {
+	case http.MethodGet:
+		model := models.ResetPassword{Login: models.Login{ModelBase: models.ModelBase{Initial: true}}}
+		render(http.StatusOK, model, components.PasswordResetForm)
+	case http.MethodPost:
+		var model models.ResetPassword
+		ctx.ShouldBind(&model)
+
+		isValid, _ := model.IsValid()
+		if !isValid {
+			render(http.StatusOK, model, components.PasswordResetForm)
+			return
+		}
+
+		// the token could have been used after it was checked
+		found, err = c.RH.ConsumeToken(ctx, rdb.TPPasswordReset, token, &value)
+		if err != nil {
+			HandleServerError(ctx, err, "unable to use password reset token")
+			return
+		}
+		if !found {
+			render(http.StatusForbidden, models.ResetPassword{}, components.PasswordResetInvalid)
+			return
+		}
+
+		if err = c.setPassword(ctx, value.UserID, model.Password); err != nil {
+			HandleServerError(ctx, err, "could not reset password")
+			return
+		}
+		utils.DeleteCookie(ctx, g.CSessionToken)
+		render(http.StatusOK, models.ResetPassword{}, components.PasswordResetDone)
+	default:
+		ctx.String(http.StatusMethodNotAllowed, "method %s not allowed", method)
+	}
+}
+
+// changes the password from the profile page, other devices are signed out
+func (c *Controller) PostHxChangePassword(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	var model models.ChangePassword
+	ctx.ShouldBind(&model)
+
+	isValid, _ := model.IsValid()
+	if !isValid {
+		RenderTempl(ctx, components.ChangePasswordForm(model))
+		return
+	}
+
+	current, err := c.DB.SelectUserPassword(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get user password")
+		return
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(current), []byte(model.CurrentPassword)); err != nil {
+		model.Error = utils.T(
+			ctx.Request.Context(),
+			locales.LKFormsPasswordErrorCurrentWrong,
+			g.ErrorWrongPassword.Error(),
+		)
+		RenderTempl(ctx, components.ChangePasswordForm(model))
+		return
+	}
+
+	if err = c.setPassword(ctx, authInfo.UserID, model.Password); err != nil {
+		HandleServerError(ctx, err, "could not change password")
+		return
+	}
+	// this session was deleted as well, keep the user signed in here
+	c.signUserIn(ctx, *authInfo)
+
+	RenderTempl(ctx, components.ChangePasswordForm(models.ChangePassword{
+		Login:   models.Login{ModelBase: models.ModelBase{Initial: true}},
+		Changed: true,
+	}))
+}
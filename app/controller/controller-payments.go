@@ -1,6 +1,238 @@
 package controller
 
 // TODO: API-s for
-// - making a payment
 // - deleting a payment
 // - adding payment file
+
+import (
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	"roommates/events"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+func renderPaymentForm(ctx *gin.Context, model *models.Payment, residents []dbqueries.SelectHouseRoommatesRow) {
+	tc := components.PaymentForm(model, residents)
+	RenderTempl(ctx, tc)
+}
+
+// converts payer ids into UUID, ids of users not living in the house are left out
+func filterPayers(payerIDs []string, residents []dbqueries.SelectHouseRoommatesRow) []pgtype.UUID {
+	isResident := make(map[string]bool, len(residents))
+	for _, resident := range residents {
+		isResident[resident.ID.String()] = true
+	}
+
+	var payers []pgtype.UUID
+	for _, payerID := range payerIDs {
+		if !isResident[payerID] {
+			continue
+		}
+		var id pgtype.UUID
+		if err := id.Scan(payerID); err == nil {
+			payers = append(payers, id)
+		}
+	}
+	return payers
+}
+
+func (c *Controller) GetHxPaymentModal(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+
+	house, err := c.DB.SelectHouse(ctx, *houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get house data")
+		return
+	}
+	residents, err := c.DB.SelectHouseRoommates(ctx, *houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+
+	model := models.Payment{
+		ModelBase: models.ModelBase{Initial: true},
+		HouseID:   house.ID.String(),
+		HouseName: house.Name,
+	}
+	tc := components.PaymentModal(&model, residents)
+	RenderTempl(ctx, tc)
+}
+
+// saves the payment requested by the user with its payers and records it in the house activity
+func (c *Controller) createPayment(ctx *gin.Context, houseID pgtype.UUID, model models.Payment, payerIDs []pgtype.UUID) (pgtype.UUID, error) {
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	paymentID, err := insertPayment(ctx, c.DB.WithTx(tx), houseID, model, payerIDs)
+	if err != nil {
+		return paymentID, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return paymentID, err
+	}
+	return paymentID, nil
+}
+
+// createPayment inside a transaction of the caller
+func insertPayment(ctx *gin.Context, qtx *dbqueries.Queries, houseID pgtype.UUID, model models.Payment, payerIDs []pgtype.UUID) (pgtype.UUID, error) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	paymentID, err := qtx.InsertPayment(ctx, dbqueries.InsertPaymentParams{
+		PaymentName: model.Name,
+		Amount:      model.GetAmount(),
+		RequesterID: authInfo.UserID,
+		HouseID:     houseID,
+		DueDate:     model.GetDueDate(),
+	})
+	if err != nil {
+		return paymentID, err
+	}
+
+	for _, payerID := range payerIDs {
+		err = qtx.InsertPaymentPayer(ctx, dbqueries.InsertPaymentPayerParams{
+			PaymentID: paymentID,
+			PayerID:   payerID,
+		})
+		if err != nil {
+			return paymentID, err
+		}
+	}
+
+	err = events.Record(ctx, qtx, events.Event{
+		Type:    events.PaymentCreated,
+		HouseID: houseID,
+		ActorID: authInfo.UserID,
+		Payload: events.Payload{
+			SubjectID: paymentID.String(),
+			Subject:   model.Name,
+			Amount:    model.Amount,
+		},
+	})
+	return paymentID, err
+}
+
+func (c *Controller) PostHxPayment(ctx *gin.Context) {
+	houseID := requirePgUUID(ctx, "id")
+	if houseID == nil {
+		return
+	}
+	if !isHouseResident(ctx, c.DB, *houseID) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotHouseResident)
+		return
+	}
+
+	residents, err := c.DB.SelectHouseRoommates(ctx, *houseID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get residents")
+		return
+	}
+
+	var model models.Payment
+	ctx.ShouldBind(&model)
+	isValid, _ := model.IsValid()
+	if !isValid {
+		renderPaymentForm(ctx, &model, residents)
+		return
+	}
+	payerIDs := filterPayers(model.PayerIDs, residents)
+
+	if _, err = c.createPayment(ctx, *houseID, model, payerIDs); err != nil {
+		HandleServerError(ctx, err, "could not save payment")
+		return
+	}
+	utils.Redirect(ctx, "")
+}
+
+// marks the share of the authenticated user as paid
+func (c *Controller) SettlePayment(ctx *gin.Context) {
+	paymentID := requirePgUUID(ctx, "id")
+	if paymentID == nil {
+		return
+	}
+
+	authInfo := middleware.GetAuthInfo(ctx)
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		HandleServerError(ctx, err, "no business pool party :(")
+		return
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	payment, err := qtx.SettlePaymentShare(ctx, dbqueries.SettlePaymentShareParams{
+		PaymentID: *paymentID,
+		PayerID:   authInfo.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+			return
+		}
+		HandleServerError(ctx, err, "could not settle payment")
+		return
+	}
+
+	// payments from before payments had a house are in no feed
+	if payment.HouseID.Valid {
+		err = events.Record(ctx, qtx, events.Event{
+			Type:    events.PaymentSettled,
+			HouseID: payment.HouseID,
+			ActorID: authInfo.UserID,
+			Payload: events.Payload{
+				SubjectID: paymentID.String(),
+				Subject:   payment.PaymentName,
+			},
+		})
+		if err != nil {
+			HandleServerError(ctx, err, "error recording house activity")
+			return
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		HandleServerError(ctx, err, "error commiting transaction")
+		return
+	}
+	utils.Redirect(ctx, "")
+}
+
+// renders the next page of the payment cards on the payments page
+func (c *Controller) HxPaymentsList(ctx *gin.Context) {
+	page, ok := requirePage(ctx, paymentsResource)
+	if !ok {
+		return
+	}
+	payments, next, err := c.paymentsPage(ctx, middleware.GetAuthInfo(ctx).UserID, page)
+	if err != nil {
+		handlePageError(ctx, err, "could not get payments")
+		return
+	}
+	versions := make([]string, 0, len(payments))
+	for _, payment := range payments {
+		versions = append(versions, paymentETag(dbqueries.SelectUserPaymentRow(payment)))
+	}
+	nextURL := hxNextPageURL(ctx, g.RHxPaymentsList, next)
+	if isNotModified(ctx, listETag(ctx, "payments", nextURL, versions)) {
+		return
+	}
+	RenderTempl(ctx, components.PaymentsList(payments, nextURL))
+}
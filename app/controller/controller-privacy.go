@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	// https://www.postgresql.org/docs/current/errcodes-appendix.html
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// id of the other user from the path, one can not block themselves
+func blockedUserID(ctx *gin.Context) (pgtype.UUID, bool) {
+	var userID pgtype.UUID
+	if err := userID.Scan(ctx.Param("id")); err != nil || !userID.Valid {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorInvalidID)
+		return userID, false
+	}
+	if userID == middleware.GetAuthInfo(ctx).UserID {
+		utils.ErrorResponse(ctx, http.StatusForbidden, g.ErrorNotAllowedToModify)
+		return userID, false
+	}
+	return userID, true
+}
+
+func (c *Controller) renderPrivacySection(ctx *gin.Context, userID pgtype.UUID, saved bool) {
+	discoverability, err := c.DB.SelectUserDiscoverability(ctx, userID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get discoverability")
+		return
+	}
+	blocks, err := c.DB.SelectUserBlocks(ctx, userID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get blocked users")
+		return
+	}
+	RenderTempl(ctx, components.PrivacySection(discoverability, blocks, saved))
+}
+
+// who can find the user in the roommate search
+func (c *Controller) PostHxPrivacy(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	discoverability := dbqueries.UserDiscoverability(ctx.PostForm("discoverability"))
+	if !discoverability.Valid() {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid discoverability"))
+		return
+	}
+
+	err := c.DB.UpdateUserDiscoverability(ctx, dbqueries.UpdateUserDiscoverabilityParams{
+		ID:              authInfo.UserID,
+		Discoverability: discoverability,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not update discoverability")
+		return
+	}
+	c.renderPrivacySection(ctx, authInfo.UserID, true)
+}
+
+// unblocking from the list on the profile page
+func (c *Controller) DeleteHxProfileBlock(ctx *gin.Context) {
+	userID, ok := blockedUserID(ctx)
+	if !ok {
+		return
+	}
+
+	authInfo := middleware.GetAuthInfo(ctx)
+	_, err := c.DB.DeleteUserBlock(ctx, dbqueries.DeleteUserBlockParams{
+		UserID:    authInfo.UserID,
+		BlockedID: userID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not unblock user")
+		return
+	}
+	c.renderPrivacySection(ctx, authInfo.UserID, false)
+}
+
+// blocked user can not find the user, add them into a house or message them
+func (c *Controller) PostHxUserBlock(ctx *gin.Context) {
+	userID, ok := blockedUserID(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.DB.InsertUserBlock(ctx, dbqueries.InsertUserBlockParams{
+		UserID:    middleware.GetAuthInfo(ctx).UserID,
+		BlockedID: userID,
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorUserNotFound)
+			return
+		}
+		HandleServerError(ctx, err, "could not block user")
+		return
+	}
+	RenderTempl(ctx, components.UserBlockButton(userID.String(), true))
+}
+
+func (c *Controller) DeleteHxUserBlock(ctx *gin.Context) {
+	userID, ok := blockedUserID(ctx)
+	if !ok {
+		return
+	}
+
+	_, err := c.DB.DeleteUserBlock(ctx, dbqueries.DeleteUserBlockParams{
+		UserID:    middleware.GetAuthInfo(ctx).UserID,
+		BlockedID: userID,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not unblock user")
+		return
+	}
+	RenderTempl(ctx, components.UserBlockButton(userID.String(), false))
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/rdb"
+	"roommates/utils"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+// profile form filled with what is stored
+func profileModel(profile dbqueries.SelectUserProfileRow) models.Profile {
+	model := models.Profile{
+		ModelBase:          models.ModelBase{Initial: true},
+		Username:           profile.Username,
+		IsFullNamePublic:   profile.IsFullNamePublic,
+		ContactInformation: models.ParseContactInformation(profile.ContactInformation),
+	}
+	if profile.FullName != nil {
+		model.FullName = *profile.FullName
+	}
+	return model
+}
+
+// saves username, full name and contact information
+func (c *Controller) PostHxProfileDetails(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	var model models.Profile
+	ctx.ShouldBind(&model)
+
+	isValid, _ := model.IsValid()
+	if !isValid {
+		RenderTempl(ctx, components.ProfileForm(model))
+		return
+	}
+
+	var fullName *string
+	if name := strings.TrimSpace(model.FullName); name != "" {
+		fullName = &name
+	}
+	contactInformation, err := json.Marshal(model.ContactInformation.Normalized())
+	if err != nil {
+		HandleServerError(ctx, err, "could not marshal contact information")
+		return
+	}
+
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		HandleServerError(ctx, err, "no business pool party :(")
+		return
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	err = qtx.UpdateUserProfile(ctx, dbqueries.UpdateUserProfileParams{
+		ID:               authInfo.UserID,
+		Username:         model.Username,
+		FullName:         fullName,
+		IsFullNamePublic: model.IsFullNamePublic,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not update profile")
+		return
+	}
+	err = qtx.UpsertUserContactInformation(ctx, dbqueries.UpsertUserContactInformationParams{
+		UserID:             authInfo.UserID,
+		ContactInformation: contactInformation,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not update contact information")
+		return
+	}
+	if err = tx.Commit(ctx); err != nil {
+		HandleServerError(ctx, err, "error commiting transaction")
+		return
+	}
+
+	// the header shows the username from the session
+	if model.Username != authInfo.Username {
+		err = c.RH.UpdateUserSessions(ctx, authInfo.UserID, func(v *rdb.UserSessionValue) {
+			v.Username = model.Username
+		})
+		if err != nil {
+			HandleServerError(ctx, err, "could not update sessions")
+			return
+		}
+	}
+
+	profile, err := c.DB.SelectUserProfile(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get profile")
+		return
+	}
+	model = profileModel(profile)
+	model.Saved = true
+	RenderTempl(ctx, components.ProfileForm(model))
+}
+
+// profile of any user, full name is shown to housemates or when public
+// and contact information only to housemates
+func (c *Controller) PageUser(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	var userID pgtype.UUID
+	if err := userID.Scan(ctx.Param("id")); err != nil || !userID.Valid {
+		utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorInvalidID)
+		return
+	}
+
+	profile, err := c.DB.SelectUserProfile(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorUserNotFound)
+			return
+		}
+		HandleServerError(ctx, err, "could not get profile")
+		return
+	}
+
+	isHousemate := userID == authInfo.UserID
+	if !isHousemate {
+		isHousemate, err = c.DB.AreUsersHousemates(ctx, dbqueries.AreUsersHousematesParams{
+			UserID:      authInfo.UserID,
+			OtherUserID: userID,
+		})
+		if err != nil {
+			HandleServerError(ctx, err, "could not check housemates")
+			return
+		}
+	}
+
+	isOwn := userID == authInfo.UserID
+	isBlocked := false
+	if !isOwn {
+		isBlocked, err = c.DB.IsUserBlocked(ctx, dbqueries.IsUserBlockedParams{
+			UserID:    authInfo.UserID,
+			BlockedID: userID,
+		})
+		if err != nil {
+			HandleServerError(ctx, err, "could not check blocked users")
+			return
+		}
+	}
+
+	user := components.UserProfile{
+		ID:          userID.String(),
+		Username:    profile.Username,
+		MemberSince: profile.CreatedAt.Time,
+		IsOwn:       isOwn,
+		IsBlocked:   isBlocked,
+	}
+	if profile.FullName != nil && (isHousemate || profile.IsFullNamePublic) {
+		user.FullName = *profile.FullName
+	}
+	if isHousemate {
+		user.Contacts = models.ParseContactInformation(profile.ContactInformation).Visible()
+	}
+
+	var tc templ.Component
+	if utils.IsRequestHTMX(ctx) {
+		tc = components.UserPageContent(user)
+	} else {
+		tc = components.PageUser(components.SPageWrapper{
+			AuthInfo: authInfo,
+			PathURL:  ctx.Request.URL.Path,
+		}, user)
+	}
+	RenderTempl(ctx, tc)
+}
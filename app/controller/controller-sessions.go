@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/components"
+	g "roommates/globals"
+	"roommates/middleware"
+	"roommates/rdb"
+	"roommates/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signs out a single session, signing out the current one redirects to login
+func (c *Controller) DeleteHxSession(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	id := ctx.Param("id")
+
+	if id == rdb.SessionID(authInfo.Key) {
+		if err := c.RH.DeleteUserSession(ctx, authInfo.Key); err != nil {
+			HandleServerError(ctx, err, "could not sign out")
+			return
+		}
+		utils.DeleteCookie(ctx, g.CSessionToken)
+		utils.Redirect(ctx, g.RLogin)
+		return
+	}
+
+	found, err := c.RH.DeleteUserSessionByID(ctx, authInfo.UserID, id)
+	if err != nil {
+		HandleServerError(ctx, err, "could not sign out session")
+		return
+	}
+	if !found {
+		utils.ErrorResponse(ctx, http.StatusNotFound, g.ErrorInvalidID)
+		return
+	}
+
+	sessions, err := c.RH.UserSessions(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "error getting sessions")
+		return
+	}
+	RenderTempl(ctx, components.SessionList(sessions, rdb.SessionID(authInfo.Key)))
+}
+
+// signs out every session of the user, including the current one
+func (c *Controller) DeleteHxSessions(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	if err := c.RH.DeleteUserSessions(ctx, authInfo.UserID); err != nil {
+		HandleServerError(ctx, err, "could not sign out sessions")
+		return
+	}
+	utils.DeleteCookie(ctx, g.CSessionToken)
+	utils.Redirect(ctx, g.RLogin)
+}
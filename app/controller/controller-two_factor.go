@@ -0,0 +1,488 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	g "roommates/globals"
+	"roommates/locales"
+	"roommates/middleware"
+	"roommates/models"
+	"roommates/rdb"
+	"roommates/twofactor"
+	"roommates/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// stored with the pending two-factor token, the password has already been checked
+type pendingTwoFactor struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	Username string      `json:"username"`
+}
+
+func twoFactorLockoutKey(userID pgtype.UUID) string {
+	return "two-factor:" + userID.String()
+}
+
+// password was right but the code is still needed, returns the token for the second step
+func (c *Controller) startTwoFactor(ctx *gin.Context, userID pgtype.UUID, username string) (string, error) {
+	return c.RH.CreateToken(ctx, rdb.TPTwoFactor, pendingTwoFactor{
+		UserID:   userID,
+		Username: username,
+	}, rdb.ETwoFactor)
+}
+
+// signs the user in from an html page, unless two-factor is enabled then the code is asked first
+func (c *Controller) signInOrStartTwoFactor(ctx *gin.Context, userID pgtype.UUID, username string) {
+	twoFactor, err := c.DB.IsTwoFactorEnabled(ctx, userID)
+	if err != nil {
+		HandleServerError(ctx, err, "error checking two-factor")
+		return
+	}
+	if twoFactor {
+		token, err := c.startTwoFactor(ctx, userID, username)
+		if err != nil {
+			HandleServerError(ctx, err, "error starting two-factor")
+			return
+		}
+		setTwoFactorCookie(ctx, token)
+		utils.Redirect(ctx, g.RLoginTwoFactor)
+		return
+	}
+
+	c.signUserIn(ctx, rdb.UserSessionValue{
+		UserID:   userID,
+		Username: username,
+	})
+	utils.Redirect(ctx, "/")
+}
+
+// checks the code of the pending sign-in, the token is used up only when the code is right
+//
+//   - ErrorInvalidCredential -- token has expired, has to sign in again
+//   - ErrorInvalidTwoFactorCode -- wrong code
+//   - ErrorLockedOut -- too many wrong codes, the duration is how long the lockout lasts
+func (c *Controller) finishTwoFactor(ctx *gin.Context, token, code string) (*pendingTwoFactor, time.Duration, error) {
+	var pending pendingTwoFactor
+	found, err := c.RH.PeekToken(ctx, rdb.TPTwoFactor, token, &pending)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !found {
+		return nil, 0, g.ErrorInvalidCredential
+	}
+
+	lockoutKey := twoFactorLockoutKey(pending.UserID)
+	wait, err := c.RH.LockedOut(ctx, lockoutKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	if wait > 0 {
+		return nil, wait, g.ErrorLockedOut
+	}
+
+	ok, err := c.checkTwoFactorCode(ctx, pending.UserID, code)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !ok {
+		if _, err = c.RH.RecordFailure(ctx, signInLockout, lockoutKey); err != nil {
+			return nil, 0, err
+		}
+		return nil, 0, g.ErrorInvalidTwoFactorCode
+	}
+	if err = c.RH.ResetFailures(ctx, lockoutKey); err != nil {
+		return nil, 0, err
+	}
+
+	// the same token could have been used at the same time
+	found, err = c.RH.ConsumeToken(ctx, rdb.TPTwoFactor, token, &pending)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !found {
+		return nil, 0, g.ErrorInvalidCredential
+	}
+	return &pending, 0, nil
+}
+
+// accepts a code from the authenticator app or an unused recovery code, both only once
+func (c *Controller) checkTwoFactorCode(ctx *gin.Context, userID pgtype.UUID, code string) (bool, error) {
+	row, err := c.DB.SelectUserTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if step, ok := twofactor.Validate(row.Secret, code, time.Now()); ok {
+		updated, err := c.DB.UseTOTPStep(ctx, dbqueries.UseTOTPStepParams{
+			Step:   step,
+			UserID: userID,
+		})
+		return updated > 0, err
+	}
+
+	used, err := c.DB.UseRecoveryCode(ctx, dbqueries.UseRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: twofactor.HashRecoveryCode(code),
+	})
+	return used > 0, err
+}
+
+func (c *Controller) twoFactorState(ctx *gin.Context, userID pgtype.UUID) (components.TwoFactorState, error) {
+	enabled, err := c.DB.IsTwoFactorEnabled(ctx, userID)
+	if err != nil || !enabled {
+		return components.TwoFactorState{}, err
+	}
+	left, err := c.DB.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return components.TwoFactorState{}, err
+	}
+	passwordSet, err := c.DB.IsUserPasswordSet(ctx, userID)
+	return components.TwoFactorState{Enabled: true, RecoveryCodesLeft: left, PasswordSet: passwordSet}, err
+}
+
+// renders the enrollment of the secret, a new one is generated when `secret` is empty
+func (c *Controller) renderTwoFactorEnroll(ctx *gin.Context, userID pgtype.UUID, secret string, model models.TwoFactorCode) {
+	user, err := c.DB.SelectUserEmail(ctx, userID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get user email")
+		return
+	}
+
+	issuer := utils.T(ctx.Request.Context(), locales.LKAppTitle, "Roommates")
+	key, err := twofactor.KeyFromSecret(issuer, user.Email, secret)
+	if secret == "" {
+		key, err = twofactor.Generate(issuer, user.Email)
+	}
+	if err != nil {
+		HandleServerError(ctx, err, "could not generate two-factor secret")
+		return
+	}
+	if secret == "" {
+		updated, err := c.DB.UpsertUserTOTP(ctx, dbqueries.UpsertUserTOTPParams{
+			UserID: userID,
+			Secret: key.Secret(),
+		})
+		if err != nil {
+			HandleServerError(ctx, err, "could not save two-factor secret")
+			return
+		}
+		// already enabled
+		if updated == 0 {
+			c.renderTwoFactorSection(ctx, userID)
+			return
+		}
+	}
+
+	qrCode, err := twofactor.QRCode(key)
+	if err != nil {
+		HandleServerError(ctx, err, "could not make QR code")
+		return
+	}
+	RenderTempl(ctx, components.TwoFactorEnroll(qrCode, key.Secret(), model))
+}
+
+func (c *Controller) renderTwoFactorSection(ctx *gin.Context, userID pgtype.UUID) {
+	state, err := c.twoFactorState(ctx, userID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get two-factor state")
+		return
+	}
+	RenderTempl(ctx, components.TwoFactorSection(state))
+}
+
+//------------------------------------------------------------------------------
+
+// starts the enrollment from the profile page
+func (c *Controller) PostHxTwoFactor(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	c.renderTwoFactorEnroll(ctx, authInfo.UserID, "", models.TwoFactorCode{ModelBase: models.ModelBase{Initial: true}})
+}
+
+// finishes the enrollment, recovery codes are shown once
+func (c *Controller) PostHxTwoFactorConfirm(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	var model models.TwoFactorCode
+	ctx.ShouldBind(&model)
+
+	row, err := c.DB.SelectUserTOTP(ctx, authInfo.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.renderTwoFactorSection(ctx, authInfo.UserID)
+			return
+		}
+		HandleServerError(ctx, err, "could not get two-factor secret")
+		return
+	}
+	if row.ConfirmedAt.Valid {
+		c.renderTwoFactorSection(ctx, authInfo.UserID)
+		return
+	}
+
+	isValid, _ := model.IsValid()
+	if !isValid {
+		c.renderTwoFactorEnroll(ctx, authInfo.UserID, row.Secret, model)
+		return
+	}
+	ok, err := c.checkTwoFactorCode(ctx, authInfo.UserID, model.Code)
+	if err != nil {
+		HandleServerError(ctx, err, "could not check two-factor code")
+		return
+	}
+	if !ok {
+		model.Error = utils.T(ctx.Request.Context(), locales.LKTwoFactorInvalidCode, g.ErrorInvalidTwoFactorCode.Error())
+		c.renderTwoFactorEnroll(ctx, authInfo.UserID, row.Secret, model)
+		return
+	}
+
+	codes, hashes := twofactor.NewRecoveryCodes()
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		HandleServerError(ctx, err, "no business pool party :(")
+		return
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	if _, err = qtx.ConfirmUserTOTP(ctx, authInfo.UserID); err != nil {
+		HandleServerError(ctx, err, "could not enable two-factor")
+		return
+	}
+	if err = qtx.DeleteRecoveryCodes(ctx, authInfo.UserID); err != nil {
+		HandleServerError(ctx, err, "could not delete recovery codes")
+		return
+	}
+	err = qtx.InsertRecoveryCodes(ctx, dbqueries.InsertRecoveryCodesParams{
+		UserID:     authInfo.UserID,
+		CodeHashes: hashes,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not save recovery codes")
+		return
+	}
+	if err = tx.Commit(ctx); err != nil {
+		HandleServerError(ctx, err, "error commiting transaction")
+		return
+	}
+
+	RenderTempl(ctx, components.TwoFactorSection(components.TwoFactorState{
+		Enabled:           true,
+		RecoveryCodesLeft: int64(len(codes)),
+		RecoveryCodes:     codes,
+	}))
+}
+
+// disabling requires the current password, users without one (signed up with OIDC)
+// give a code from the authenticator app or a recovery code instead
+func (c *Controller) PostHxTwoFactorDisable(ctx *gin.Context) {
+	authInfo := middleware.GetAuthInfo(ctx)
+	state, err := c.twoFactorState(ctx, authInfo.UserID)
+	if err != nil {
+		HandleServerError(ctx, err, "could not get two-factor state")
+		return
+	}
+	if !state.Enabled {
+		RenderTempl(ctx, components.TwoFactorSection(state))
+		return
+	}
+
+	if state.PasswordSet {
+		current, err := c.DB.SelectUserPassword(ctx, authInfo.UserID)
+		if err != nil {
+			HandleServerError(ctx, err, "could not get user password")
+			return
+		}
+		if bcrypt.CompareHashAndPassword([]byte(current), []byte(ctx.PostForm("password"))) != nil {
+			state.Error = utils.T(ctx.Request.Context(), locales.LKFormsPasswordErrorCurrentWrong, g.ErrorWrongPassword.Error())
+			RenderTempl(ctx, components.TwoFactorSection(state))
+			return
+		}
+	} else {
+		// same lockout as the second step of signing in, otherwise codes could be guessed here
+		lockoutKey := twoFactorLockoutKey(authInfo.UserID)
+		wait, err := c.RH.LockedOut(ctx, lockoutKey)
+		if err != nil {
+			HandleServerError(ctx, err, "error checking lockout")
+			return
+		}
+		if wait > 0 {
+			middleware.SetRetryAfter(ctx, wait)
+			c.RateLimited(ctx, wait)
+			return
+		}
+		ok, err := c.checkTwoFactorCode(ctx, authInfo.UserID, strings.TrimSpace(ctx.PostForm("code")))
+		if err != nil {
+			HandleServerError(ctx, err, "could not check two-factor code")
+			return
+		}
+		if !ok {
+			if _, err = c.RH.RecordFailure(ctx, signInLockout, lockoutKey); err != nil {
+				HandleServerError(ctx, err, "error recording failed code")
+				return
+			}
+			state.Error = utils.T(ctx.Request.Context(), locales.LKTwoFactorInvalidCode, g.ErrorInvalidTwoFactorCode.Error())
+			RenderTempl(ctx, components.TwoFactorSection(state))
+			return
+		}
+		if err = c.RH.ResetFailures(ctx, lockoutKey); err != nil {
+			HandleServerError(ctx, err, "error resetting failed codes")
+			return
+		}
+	}
+
+	tx, err := c.Pool.Begin(ctx.Request.Context())
+	if err != nil {
+		HandleServerError(ctx, err, "no business pool party :(")
+		return
+	}
+	defer tx.Rollback(ctx)
+	qtx := c.DB.WithTx(tx)
+
+	if err = qtx.DeleteUserTOTP(ctx, authInfo.UserID); err != nil {
+		HandleServerError(ctx, err, "could not disable two-factor")
+		return
+	}
+	if err = qtx.DeleteRecoveryCodes(ctx, authInfo.UserID); err != nil {
+		HandleServerError(ctx, err, "could not delete recovery codes")
+		return
+	}
+	if err = tx.Commit(ctx); err != nil {
+		HandleServerError(ctx, err, "error commiting transaction")
+		return
+	}
+	RenderTempl(ctx, components.TwoFactorSection(components.TwoFactorState{}))
+}
+
+//------------------------------------------------------------------------------
+
+// second step of PageLogin, the pending sign-in is kept in a cookie
+func (c *Controller) PageLoginTwoFactor(ctx *gin.Context) {
+	token, err := ctx.Cookie(string(g.CTwoFactorToken))
+	if err != nil || token == "" {
+		utils.Redirect(ctx, g.RLogin)
+		return
+	}
+
+	method := ctx.Request.Method
+	render := func(model models.TwoFactorCode) {
+		RenderTempl(ctx, components.PageLoginTwoFactor(model))
+	}
+
+	switch method {
+	case http.MethodGet:
+		render(models.TwoFactorCode{ModelBase: models.ModelBase{Initial: true}})
+	case http.MethodPost:
+		var model models.TwoFactorCode
+		ctx.ShouldBind(&model)
+
+		isValid, _ := model.IsValid()
+		if !isValid {
+			render(model)
+			return
+		}
+
+		pending, wait, err := c.finishTwoFactor(ctx, token, model.Code)
+		switch {
+		case errors.Is(err, g.ErrorLockedOut):
+			middleware.SetRetryAfter(ctx, wait)
+			c.RateLimited(ctx, wait)
+			return
+		case errors.Is(err, g.ErrorInvalidTwoFactorCode):
+			model.Error = utils.T(ctx.Request.Context(), locales.LKTwoFactorInvalidCode, err.Error())
+			render(model)
+			return
+		case errors.Is(err, g.ErrorInvalidCredential):
+			utils.DeleteCookie(ctx, g.CTwoFactorToken)
+			utils.Redirect(ctx, g.RLogin)
+			return
+		case err != nil:
+			HandleServerError(ctx, err, "error checking two-factor code")
+			return
+		}
+
+		utils.DeleteCookie(ctx, g.CTwoFactorToken)
+		c.signUserIn(ctx, rdb.UserSessionValue{
+			UserID:   pending.UserID,
+			Username: pending.Username,
+		})
+		utils.Redirect(ctx, "/")
+	default:
+		ctx.String(http.StatusMethodNotAllowed, "method %s not allowed", method)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type SignInTwoFactorRequest struct {
+	TwoFactorToken string `form:"two_factor_token" json:"two_factor_token" binding:"required"`
+	// code from the authenticator app or a recovery code
+	Code string `form:"code" json:"code" binding:"required"`
+}
+
+// SignInTwoFactor godoc
+//
+//	@Summary      Second step of user login
+//	@Description  Finishes the sign-in with the two-factor code when sign-in responded with two_factor_token
+//	@Tags         auth
+//
+//	@Accept  json
+//	@Param    SignInTwoFactor  body  SignInTwoFactorRequest  true  "Token from sign-in and the code"
+//
+//	@Produce  json
+//	@Success  200  {object}  SignInResponse
+//	@Failure  400  {object}  utils.HTTPError
+//	@Failure  401  {object}  utils.HTTPError
+//	@Failure  429  {object}  utils.HTTPError
+//	@Failure  500  {object}  utils.HTTPError
+//
+//	@Router    /api/v1/auth/sign-in/two-factor [post]
+func (c *Controller) SignInTwoFactor(ctx *gin.Context) {
+	var req SignInTwoFactorRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	pending, wait, err := c.finishTwoFactor(ctx, req.TwoFactorToken, req.Code)
+	switch {
+	case errors.Is(err, g.ErrorLockedOut):
+		middleware.SetRetryAfter(ctx, wait)
+		c.RateLimited(ctx, wait)
+		return
+	case errors.Is(err, g.ErrorInvalidTwoFactorCode), errors.Is(err, g.ErrorInvalidCredential):
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, err)
+		return
+	case err != nil:
+		HandleServerError(ctx, err, "error checking two-factor code")
+		return
+	}
+
+	token := c.signUserIn(ctx, rdb.UserSessionValue{
+		UserID:   pending.UserID,
+		Username: pending.Username,
+	})
+	ctx.JSON(http.StatusOK, SignInResponse{Token: token.String()})
+}
+
+// the cookie lives as long as the pending sign-in
+func setTwoFactorCookie(ctx *gin.Context, token string) {
+	ctx.SetCookie(
+		string(g.CTwoFactorToken),
+		token,
+		int(rdb.ETwoFactor.Seconds()),
+		"/",
+		"localhost",
+		true,
+		true,
+	)
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"roommates/db/dbqueries"
+	"roommates/db/dbtest"
+	"roommates/twofactor"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// codes from the app and recovery codes work once
+func TestCheckTwoFactorCodeOnlyOnce(t *testing.T) {
+	pool := dbtest.Pool(t)
+	c := &Controller{Pool: pool, DB: dbqueries.New(pool)}
+	bg := context.Background()
+
+	userID, err := c.DB.InsertUser(bg, dbqueries.InsertUserParams{Email: "anna@roommates.test", Username: "anna", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	key, err := twofactor.Generate("Roommates", "anna@roommates.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.DB.UpsertUserTOTP(bg, dbqueries.UpsertUserTOTPParams{UserID: userID, Secret: key.Secret()}); err != nil {
+		t.Fatalf("could not save secret: %v", err)
+	}
+	codes, hashes := twofactor.NewRecoveryCodes()
+	if err = c.DB.InsertRecoveryCodes(bg, dbqueries.InsertRecoveryCodesParams{UserID: userID, CodeHashes: hashes}); err != nil {
+		t.Fatalf("could not save recovery codes: %v", err)
+	}
+
+	code, err := totp.GenerateCodeCustom(key.Secret(), time.Now(), totp.ValidateOpts{Period: 30, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"code from the app", code, true},
+		{"same code again", code, false},
+		{"recovery code", codes[0], true},
+		{"same recovery code again", codes[0], false},
+		{"recovery code typed differently", "  " + codes[1][:5] + codes[1][6:] + " ", true},
+		{"wrong code", "000000", false},
+	}
+	for _, tt := range tests {
+		ok, err := c.checkTwoFactorCode(userContext(userID), userID, tt.code)
+		if err != nil {
+			t.Fatalf("%s: checkTwoFactorCode = %v", tt.name, err)
+		}
+		if ok != tt.want {
+			t.Errorf("%s: checkTwoFactorCode = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+
+	left, err := c.DB.CountRecoveryCodes(bg, userID)
+	if err != nil || left != int64(len(codes)-2) {
+		t.Errorf("CountRecoveryCodes = %d, %v, want %d", left, err, len(codes)-2)
+	}
+}
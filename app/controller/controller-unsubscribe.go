@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"net/http"
+	"roommates/components"
+	"roommates/db/dbqueries"
+	"roommates/gintemplrenderer"
+	"roommates/notifications"
+
+	"github.com/gin-gonic/gin"
+)
+
+// confirmation page for the unsubscribe link in emails
+func (c *Controller) PageUnsubscribe(ctx *gin.Context) {
+	_, kind, ok := c.Mailer.ParseUnsubscribeToken(ctx.Param("token"))
+	if !ok {
+		renderUnsubscribe(ctx, http.StatusForbidden, kind, components.UnsubscribeInvalid)
+		return
+	}
+	renderUnsubscribe(ctx, http.StatusOK, kind, components.UnsubscribeConfirm)
+}
+
+// turns off the kind of notifications the token is for
+//
+// also used by mail clients for one-click unsubscribe (RFC 8058)
+func (c *Controller) Unsubscribe(ctx *gin.Context) {
+	userID, kind, ok := c.Mailer.ParseUnsubscribeToken(ctx.Param("token"))
+	if !ok {
+		renderUnsubscribe(ctx, http.StatusForbidden, kind, components.UnsubscribeInvalid)
+		return
+	}
+
+	err := c.DB.UpsertNotificationPreference(ctx, dbqueries.UpsertNotificationPreferenceParams{
+		UserID:  userID,
+		Kind:    string(kind),
+		Enabled: false,
+	})
+	if err != nil {
+		HandleServerError(ctx, err, "could not save notification preferences")
+		return
+	}
+	renderUnsubscribe(ctx, http.StatusOK, kind, components.UnsubscribeDone)
+}
+
+func renderUnsubscribe(ctx *gin.Context, status int, kind notifications.Kind, state components.UnsubscribeState) {
+	r := gintemplrenderer.New(ctx.Request.Context(), status, components.PageUnsubscribe(kind, state))
+	ctx.Render(r.Status, r)
+}
@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"roommates/db/dbqueries"
 	"roommates/gintemplrenderer"
+	"roommates/images"
 	"roommates/logger"
+	"roommates/mailer"
+	"roommates/oidcauth"
 	"roommates/rdb"
 	"roommates/utils"
 	"strconv"
@@ -25,17 +28,23 @@ type SimpleResponse struct {
 // ---| CONTROLLER ---
 
 type Controller struct {
-	DB   *dbqueries.Queries
-	RH   *rdb.RedisHandler
-	Pool *pgxpool.Pool
+	DB     *dbqueries.Queries
+	RH     *rdb.RedisHandler
+	Pool   *pgxpool.Pool
+	Mailer *mailer.Mailer
+	OIDC   *oidcauth.Providers
+	Images *images.Store
 }
 
-func New(dbpool *pgxpool.Pool, rh *rdb.RedisHandler) *Controller {
+func New(dbpool *pgxpool.Pool, rh *rdb.RedisHandler, m *mailer.Mailer, oidc *oidcauth.Providers, store *images.Store) *Controller {
 	dbHandler := dbqueries.New(dbpool)
 	return &Controller{
-		DB:   dbHandler,
-		RH:   rh,
-		Pool: dbpool,
+		DB:     dbHandler,
+		RH:     rh,
+		Pool:   dbpool,
+		Mailer: m,
+		OIDC:   oidc,
+		Images: store,
 	}
 }
 
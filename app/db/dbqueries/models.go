@@ -168,20 +168,157 @@ func (e HouseReminderStatus) Valid() bool {
 	return false
 }
 
+type ReminderRecurrence string
+
+const (
+	ReminderRecurrenceNone    ReminderRecurrence = "none"
+	ReminderRecurrenceDaily   ReminderRecurrence = "daily"
+	ReminderRecurrenceWeekly  ReminderRecurrence = "weekly"
+	ReminderRecurrenceMonthly ReminderRecurrence = "monthly"
+)
+
+func (e *ReminderRecurrence) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReminderRecurrence(s)
+	case string:
+		*e = ReminderRecurrence(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReminderRecurrence: %T", src)
+	}
+	return nil
+}
+
+type NullReminderRecurrence struct {
+	ReminderRecurrence ReminderRecurrence `json:"reminder_recurrence"`
+	Valid              bool               `json:"valid"` // Valid is true if ReminderRecurrence is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReminderRecurrence) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReminderRecurrence, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReminderRecurrence.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReminderRecurrence) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReminderRecurrence), nil
+}
+
+func (e ReminderRecurrence) Valid() bool {
+	switch e {
+	case ReminderRecurrenceNone,
+		ReminderRecurrenceDaily,
+		ReminderRecurrenceWeekly,
+		ReminderRecurrenceMonthly:
+		return true
+	}
+	return false
+}
+
+type UserDiscoverability string
+
+const (
+	UserDiscoverabilityEveryone   UserDiscoverability = "everyone"
+	UserDiscoverabilityHousemates UserDiscoverability = "housemates"
+	UserDiscoverabilityEmail      UserDiscoverability = "email"
+)
+
+func (e *UserDiscoverability) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = UserDiscoverability(s)
+	case string:
+		*e = UserDiscoverability(s)
+	default:
+		return fmt.Errorf("unsupported scan type for UserDiscoverability: %T", src)
+	}
+	return nil
+}
+
+type NullUserDiscoverability struct {
+	UserDiscoverability UserDiscoverability `json:"user_discoverability"`
+	Valid               bool                `json:"valid"` // Valid is true if UserDiscoverability is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullUserDiscoverability) Scan(value interface{}) error {
+	if value == nil {
+		ns.UserDiscoverability, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.UserDiscoverability.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullUserDiscoverability) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.UserDiscoverability), nil
+}
+
+func (e UserDiscoverability) Valid() bool {
+	switch e {
+	case UserDiscoverabilityEveryone,
+		UserDiscoverabilityHousemates,
+		UserDiscoverabilityEmail:
+		return true
+	}
+	return false
+}
+
+type AccessToken struct {
+	ID         int64              `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Name       string             `json:"name"`
+	TokenHash  string             `json:"token_hash"`
+	Scopes     []string           `json:"scopes"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type CalendarFeed struct {
+	ID         int64              `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	HouseID    pgtype.UUID        `json:"house_id"`
+	TokenHash  string             `json:"token_hash"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
 type Conversation struct {
-	ID                pgtype.UUID               `json:"id"`
-	Name              *string                   `json:"name"`
-	ConversationImage []byte                    `json:"conversation_image"`
-	RecipientIds      []string                  `json:"recipient_ids"`
-	RecipientType     ConversationRecipientType `json:"recipient_type"`
+	ID            pgtype.UUID               `json:"id"`
+	Name          *string                   `json:"name"`
+	RecipientIds  []string                  `json:"recipient_ids"`
+	RecipientType ConversationRecipientType `json:"recipient_type"`
+	ImageKey      *string                   `json:"image_key"`
 }
 
 type House struct {
-	ID        pgtype.UUID        `json:"id"`
-	Name      string             `json:"name"`
-	MakerID   pgtype.UUID        `json:"maker_id"`
+	ID         pgtype.UUID        `json:"id"`
+	Name       string             `json:"name"`
+	MakerID    pgtype.UUID        `json:"maker_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	PictureKey *string            `json:"picture_key"`
+}
+
+type HouseEvent struct {
+	ID        int64              `json:"id"`
+	EventType string             `json:"event_type"`
+	Payload   []byte             `json:"payload"`
+	HouseID   pgtype.UUID        `json:"house_id"`
+	ActorID   pgtype.UUID        `json:"actor_id"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type HouseNote struct {
@@ -201,6 +338,8 @@ type HousePayment struct {
 	RequesterID pgtype.UUID        `json:"requester_id"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	HouseID     pgtype.UUID        `json:"house_id"`
+	DueDate     pgtype.Date        `json:"due_date"`
 }
 
 type HousePaymentPayer struct {
@@ -225,6 +364,22 @@ type HouseReminder struct {
 	MakerID        pgtype.UUID         `json:"maker_id"`
 	CreatedAt      pgtype.Timestamptz  `json:"created_at"`
 	UpdatedAt      pgtype.Timestamptz  `json:"updated_at"`
+	DueDate        pgtype.Date         `json:"due_date"`
+	Recurrence     ReminderRecurrence  `json:"recurrence"`
+	AssigneeID     pgtype.UUID         `json:"assignee_id"`
+	DueEventDate   pgtype.Date         `json:"due_event_date"`
+}
+
+type HouseWebhook struct {
+	ID           pgtype.UUID        `json:"id"`
+	HouseID      pgtype.UUID        `json:"house_id"`
+	Url          string             `json:"url"`
+	Secret       string             `json:"secret"`
+	EventTypes   []string           `json:"event_types"`
+	FailureCount int32              `json:"failure_count"`
+	DisabledAt   pgtype.Timestamptz `json:"disabled_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Message struct {
@@ -234,18 +389,80 @@ type Message struct {
 	SenderID       pgtype.UUID        `json:"sender_id"`
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	ReplyToID      pgtype.UUID        `json:"reply_to_id"`
+}
+
+type Notification struct {
+	ID        int64              `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Kind      string             `json:"kind"`
+	Subject   string             `json:"subject"`
+	Link      string             `json:"link"`
+	HouseID   pgtype.UUID        `json:"house_id"`
+	ActorID   pgtype.UUID        `json:"actor_id"`
+	EventID   *int64             `json:"event_id"`
+	ReadAt    pgtype.Timestamptz `json:"read_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	EmailedAt pgtype.Timestamptz `json:"emailed_at"`
+}
+
+type NotificationPreference struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Kind    string      `json:"kind"`
+	Enabled bool        `json:"enabled"`
+}
+
+type Outbox struct {
+	ID         int64              `json:"id"`
+	Topic      string             `json:"topic"`
+	MessageKey string             `json:"message_key"`
+	Payload    []byte             `json:"payload"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID       int64              `json:"id"`
+	UserID   pgtype.UUID        `json:"user_id"`
+	CodeHash string             `json:"code_hash"`
+	UsedAt   pgtype.Timestamptz `json:"used_at"`
+}
+
+type ShoppingItem struct {
+	ID        int64              `json:"id"`
+	HouseID   pgtype.UUID        `json:"house_id"`
+	ItemName  string             `json:"item_name"`
+	Quantity  string             `json:"quantity"`
+	Category  string             `json:"category"`
+	AddedBy   pgtype.UUID        `json:"added_by"`
+	BoughtBy  pgtype.UUID        `json:"bought_by"`
+	BoughtAt  pgtype.Timestamptz `json:"bought_at"`
+	SettledAt pgtype.Timestamptz `json:"settled_at"`
+	PaymentID pgtype.UUID        `json:"payment_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type User struct {
-	ID               pgtype.UUID        `json:"id"`
-	Email            string             `json:"email"`
-	EmailValidated   bool               `json:"email_validated"`
-	Username         string             `json:"username"`
-	Password         string             `json:"password"`
-	FullName         *string            `json:"full_name"`
-	IsFullNamePublic bool               `json:"is_full_name_public"`
-	CreatedAt        pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	ID                  pgtype.UUID         `json:"id"`
+	Email               string              `json:"email"`
+	EmailValidated      bool                `json:"email_validated"`
+	Username            string              `json:"username"`
+	Password            string              `json:"password"`
+	FullName            *string             `json:"full_name"`
+	IsFullNamePublic    bool                `json:"is_full_name_public"`
+	CreatedAt           pgtype.Timestamptz  `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz  `json:"updated_at"`
+	DeletionScheduledAt pgtype.Timestamptz  `json:"deletion_scheduled_at"`
+	AvatarKey           *string             `json:"avatar_key"`
+	Discoverability     UserDiscoverability `json:"discoverability"`
+	Locale              *string             `json:"locale"`
+	PasswordSet         bool                `json:"password_set"`
+}
+
+type UserBlock struct {
+	UserID    pgtype.UUID        `json:"user_id"`
+	BlockedID pgtype.UUID        `json:"blocked_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type UserContactInformation struct {
@@ -257,3 +474,32 @@ type UserHouse struct {
 	UserID  pgtype.UUID `json:"user_id"`
 	HouseID pgtype.UUID `json:"house_id"`
 }
+
+type UserIdentity struct {
+	ID        int64              `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Provider  string             `json:"provider"`
+	Subject   string             `json:"subject"`
+	Email     *string            `json:"email"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserID       pgtype.UUID        `json:"user_id"`
+	Secret       string             `json:"secret"`
+	LastUsedStep int64              `json:"last_used_step"`
+	ConfirmedAt  pgtype.Timestamptz `json:"confirmed_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID         int64              `json:"id"`
+	WebhookID  pgtype.UUID        `json:"webhook_id"`
+	EventID    int64              `json:"event_id"`
+	EventType  string             `json:"event_type"`
+	Attempt    int32              `json:"attempt"`
+	StatusCode *int32             `json:"status_code"`
+	Error      string             `json:"error"`
+	DurationMs int32              `json:"duration_ms"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
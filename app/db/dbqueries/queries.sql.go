@@ -11,16 +11,196 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const deleteHouse = `-- name: DeleteHouse :exec
-DELETE FROM houses
+const areUsersHousemates = `-- name: AreUsersHousemates :one
+SELECT EXISTS (
+    SELECT 1
+    FROM user_houses a
+      INNER JOIN user_houses b ON a.house_id = b.house_id
+    WHERE a.user_id = $1
+      AND b.user_id = $2
+  )
+`
+
+type AreUsersHousematesParams struct {
+	UserID      pgtype.UUID `json:"user_id"`
+	OtherUserID pgtype.UUID `json:"other_user_id"`
+}
+
+func (q *Queries) AreUsersHousemates(ctx context.Context, arg AreUsersHousematesParams) (bool, error) {
+	row := q.db.QueryRow(ctx, areUsersHousemates, arg.UserID, arg.OtherUserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const cancelUserDeletion = `-- name: CancelUserDeletion :exec
+UPDATE users
+SET deletion_scheduled_at = NULL
 WHERE id = $1
 `
 
-func (q *Queries) DeleteHouse(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteHouse, id)
+func (q *Queries) CancelUserDeletion(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, cancelUserDeletion, id)
 	return err
 }
 
+const completeReminder = `-- name: CompleteReminder :one
+UPDATE house_reminders hr
+SET reminder_status = CASE
+    WHEN hr.recurrence = 'none'
+    OR hr.due_date IS NULL THEN 'complete'
+    ELSE hr.reminder_status
+  END,
+  due_date = CASE
+    hr.recurrence
+    WHEN 'daily' THEN hr.due_date + 1
+    WHEN 'weekly' THEN hr.due_date + 7
+    WHEN 'monthly' THEN (hr.due_date + INTERVAL '1 month')::date
+    ELSE hr.due_date
+  END
+WHERE hr.id = $1
+  AND hr.house_id IN (
+    SELECT house_id
+    FROM user_houses
+    WHERE user_id = $2
+  )
+RETURNING hr.house_id,
+  hr.content,
+  hr.reminder_status
+`
+
+type CompleteReminderParams struct {
+	ReminderID int32       `json:"reminder_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+}
+
+type CompleteReminderRow struct {
+	HouseID        pgtype.UUID         `json:"house_id"`
+	Content        []byte              `json:"content"`
+	ReminderStatus HouseReminderStatus `json:"reminder_status"`
+}
+
+// a recurring reminder with a due date moves to its next due date instead of being completed
+func (q *Queries) CompleteReminder(ctx context.Context, arg CompleteReminderParams) (CompleteReminderRow, error) {
+	row := q.db.QueryRow(ctx, completeReminder, arg.ReminderID, arg.UserID)
+	var i CompleteReminderRow
+	err := row.Scan(&i.HouseID, &i.Content, &i.ReminderStatus)
+	return i, err
+}
+
+const confirmUserTOTP = `-- name: ConfirmUserTOTP :execrows
+UPDATE user_totp
+SET confirmed_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+  AND confirmed_at IS NULL
+`
+
+func (q *Queries) ConfirmUserTOTP(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, confirmUserTOTP, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countRecoveryCodes = `-- name: CountRecoveryCodes :one
+SELECT COUNT(*)
+FROM recovery_codes
+WHERE user_id = $1
+  AND used_at IS NULL
+`
+
+func (q *Queries) CountRecoveryCodes(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countRecoveryCodes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUnreadNotifications = `-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = $1
+  AND read_at IS NULL
+`
+
+func (q *Queries) CountUnreadNotifications(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnreadNotifications, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteAccessToken = `-- name: DeleteAccessToken :execrows
+DELETE FROM access_tokens
+WHERE id = $1
+  AND user_id = $2
+`
+
+type DeleteAccessTokenParams struct {
+	ID     int64       `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteAccessToken(ctx context.Context, arg DeleteAccessTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAccessToken, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteCalendarFeed = `-- name: DeleteCalendarFeed :execrows
+DELETE FROM calendar_feeds
+WHERE id = $1
+  AND user_id = $2
+`
+
+type DeleteCalendarFeedParams struct {
+	ID     int64       `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteCalendarFeed(ctx context.Context, arg DeleteCalendarFeedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCalendarFeed, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteHouse = `-- name: DeleteHouse :one
+DELETE FROM houses
+WHERE id = $1
+RETURNING picture_key
+`
+
+func (q *Queries) DeleteHouse(ctx context.Context, id pgtype.UUID) (*string, error) {
+	row := q.db.QueryRow(ctx, deleteHouse, id)
+	var picture_key *string
+	err := row.Scan(&picture_key)
+	return picture_key, err
+}
+
+const deleteHouseUser = `-- name: DeleteHouseUser :execrows
+DELETE FROM user_houses
+WHERE house_id = $1
+  AND user_id = $2
+`
+
+type DeleteHouseUserParams struct {
+	HouseID pgtype.UUID `json:"house_id"`
+	UserID  pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteHouseUser(ctx context.Context, arg DeleteHouseUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteHouseUser, arg.HouseID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteHouseUsers = `-- name: DeleteHouseUsers :exec
 DELETE FROM user_houses
 WHERE house_id = $1
@@ -31,6 +211,25 @@ func (q *Queries) DeleteHouseUsers(ctx context.Context, houseID pgtype.UUID) err
 	return err
 }
 
+const deleteHouseWebhook = `-- name: DeleteHouseWebhook :execrows
+DELETE FROM house_webhooks
+WHERE id = $1
+  AND house_id = $2
+`
+
+type DeleteHouseWebhookParams struct {
+	ID      pgtype.UUID `json:"id"`
+	HouseID pgtype.UUID `json:"house_id"`
+}
+
+func (q *Queries) DeleteHouseWebhook(ctx context.Context, arg DeleteHouseWebhookParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteHouseWebhook, arg.ID, arg.HouseID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteNote = `-- name: DeleteNote :exec
 DELETE FROM house_notes
 WHERE id = $1
@@ -41,6 +240,320 @@ func (q *Queries) DeleteNote(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteOldWebhookDeliveries = `-- name: DeleteOldWebhookDeliveries :exec
+DELETE FROM webhook_deliveries
+WHERE webhook_id = $1
+  AND id <= (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE webhook_id = $1
+    ORDER BY id DESC
+    OFFSET $2::int
+    LIMIT 1
+  )
+`
+
+type DeleteOldWebhookDeliveriesParams struct {
+	WebhookID pgtype.UUID `json:"webhook_id"`
+	Keep      int32       `json:"keep"`
+}
+
+// keeps only the newest `keep` deliveries of the webhook
+func (q *Queries) DeleteOldWebhookDeliveries(ctx context.Context, arg DeleteOldWebhookDeliveriesParams) error {
+	_, err := q.db.Exec(ctx, deleteOldWebhookDeliveries, arg.WebhookID, arg.Keep)
+	return err
+}
+
+const deleteOutboxMessages = `-- name: DeleteOutboxMessages :exec
+DELETE FROM outbox
+WHERE id = ANY($1::bigint [])
+`
+
+func (q *Queries) DeleteOutboxMessages(ctx context.Context, ids []int64) error {
+	_, err := q.db.Exec(ctx, deleteOutboxMessages, ids)
+	return err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteRecoveryCodes, userID)
+	return err
+}
+
+const deleteShoppingItem = `-- name: DeleteShoppingItem :execrows
+DELETE FROM shopping_items
+WHERE id = $1
+  AND house_id = $2
+  AND settled_at IS NULL
+`
+
+type DeleteShoppingItemParams struct {
+	ID      int64       `json:"id"`
+	HouseID pgtype.UUID `json:"house_id"`
+}
+
+func (q *Queries) DeleteShoppingItem(ctx context.Context, arg DeleteShoppingItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteShoppingItem, arg.ID, arg.HouseID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUser = `-- name: DeleteUser :one
+DELETE FROM users
+WHERE id = $1
+RETURNING avatar_key
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id pgtype.UUID) (*string, error) {
+	row := q.db.QueryRow(ctx, deleteUser, id)
+	var avatar_key *string
+	err := row.Scan(&avatar_key)
+	return avatar_key, err
+}
+
+const deleteUserBlock = `-- name: DeleteUserBlock :execrows
+DELETE FROM user_blocks
+WHERE user_id = $1
+  AND blocked_id = $2
+`
+
+type DeleteUserBlockParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	BlockedID pgtype.UUID `json:"blocked_id"`
+}
+
+func (q *Queries) DeleteUserBlock(ctx context.Context, arg DeleteUserBlockParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserBlock, arg.UserID, arg.BlockedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserIdentity = `-- name: DeleteUserIdentity :execrows
+DELETE FROM user_identities
+WHERE user_id = $1
+  AND provider = $2
+`
+
+type DeleteUserIdentityParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	Provider string      `json:"provider"`
+}
+
+func (q *Queries) DeleteUserIdentity(ctx context.Context, arg DeleteUserIdentityParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserIdentity, arg.UserID, arg.Provider)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserSoleHouses = `-- name: DeleteUserSoleHouses :many
+DELETE FROM houses h
+WHERE h.maker_id = $1
+  AND NOT EXISTS (
+    SELECT 1
+    FROM user_houses uh
+    WHERE uh.house_id = h.id
+      AND uh.user_id <> $1
+  )
+RETURNING h.picture_key
+`
+
+// houses nobody else lives in would be left without anyone to see them
+func (q *Queries) DeleteUserSoleHouses(ctx context.Context, userID pgtype.UUID) ([]*string, error) {
+	rows, err := q.db.Query(ctx, deleteUserSoleHouses, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*string
+	for rows.Next() {
+		var picture_key *string
+		if err := rows.Scan(&picture_key); err != nil {
+			return nil, err
+		}
+		items = append(items, picture_key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteUserTOTP = `-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserTOTP(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserTOTP, userID)
+	return err
+}
+
+const enableHouseWebhook = `-- name: EnableHouseWebhook :execrows
+UPDATE house_webhooks
+SET failure_count = 0,
+  disabled_at = NULL
+WHERE id = $1
+  AND house_id = $2
+`
+
+type EnableHouseWebhookParams struct {
+	ID      pgtype.UUID `json:"id"`
+	HouseID pgtype.UUID `json:"house_id"`
+}
+
+func (q *Queries) EnableHouseWebhook(ctx context.Context, arg EnableHouseWebhookParams) (int64, error) {
+	result, err := q.db.Exec(ctx, enableHouseWebhook, arg.ID, arg.HouseID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const exportUserMessages = `-- name: ExportUserMessages :many
+SELECT id,
+  conversation_id,
+  content,
+  created_at,
+  updated_at
+FROM messages
+WHERE sender_id = $1
+ORDER BY created_at
+`
+
+type ExportUserMessagesRow struct {
+	ID             pgtype.UUID        `json:"id"`
+	ConversationID pgtype.UUID        `json:"conversation_id"`
+	Content        string             `json:"content"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) ExportUserMessages(ctx context.Context, senderID pgtype.UUID) ([]ExportUserMessagesRow, error) {
+	rows, err := q.db.Query(ctx, exportUserMessages, senderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUserMessagesRow
+	for rows.Next() {
+		var i ExportUserMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ConversationID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserNotes = `-- name: ExportUserNotes :many
+SELECT hn.id,
+  hn.title,
+  hn.content,
+  h.name house_name,
+  hn.created_at,
+  hn.updated_at
+FROM house_notes hn
+  INNER JOIN houses h ON h.id = hn.house_id
+WHERE hn.maker_id = $1
+ORDER BY hn.id
+`
+
+type ExportUserNotesRow struct {
+	ID        int32              `json:"id"`
+	Title     string             `json:"title"`
+	Content   string             `json:"content"`
+	HouseName string             `json:"house_name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) ExportUserNotes(ctx context.Context, makerID pgtype.UUID) ([]ExportUserNotesRow, error) {
+	rows, err := q.db.Query(ctx, exportUserNotes, makerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUserNotesRow
+	for rows.Next() {
+		var i ExportUserNotesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.HouseName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserProfile = `-- name: ExportUserProfile :one
+SELECT u.email,
+  u.email_validated,
+  u.username,
+  u.full_name,
+  u.is_full_name_public,
+  u.created_at,
+  u.updated_at,
+  uci.contact_information
+FROM users u
+  LEFT JOIN user_contact_information uci ON uci.user_id = u.id
+WHERE u.id = $1
+`
+
+type ExportUserProfileRow struct {
+	Email              string             `json:"email"`
+	EmailValidated     bool               `json:"email_validated"`
+	Username           string             `json:"username"`
+	FullName           *string            `json:"full_name"`
+	IsFullNamePublic   bool               `json:"is_full_name_public"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+	ContactInformation []byte             `json:"contact_information"`
+}
+
+func (q *Queries) ExportUserProfile(ctx context.Context, id pgtype.UUID) (ExportUserProfileRow, error) {
+	row := q.db.QueryRow(ctx, exportUserProfile, id)
+	var i ExportUserProfileRow
+	err := row.Scan(
+		&i.Email,
+		&i.EmailValidated,
+		&i.Username,
+		&i.FullName,
+		&i.IsFullNamePublic,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ContactInformation,
+	)
+	return i, err
+}
+
 const getUserCredentials = `-- name: GetUserCredentials :one
 SELECT id,
   email,
@@ -73,45 +586,403 @@ func (q *Queries) GetUserCredentials(ctx context.Context, email string) (GetUser
 	return i, err
 }
 
-const insertHouse = `-- name: InsertHouse :one
-INSERT INTO houses (name, maker_id)
-VALUES ($1, $2)
-RETURNING id
+const incrementWebhookFailures = `-- name: IncrementWebhookFailures :one
+UPDATE house_webhooks
+SET failure_count = failure_count + 1,
+  disabled_at = CASE
+    WHEN failure_count + 1 >= $1::int THEN COALESCE(disabled_at, CURRENT_TIMESTAMP)
+    ELSE disabled_at
+  END
+WHERE id = $2
+RETURNING disabled_at IS NOT NULL AS disabled
 `
 
-type InsertHouseParams struct {
-	Name    string      `json:"name"`
-	MakerID pgtype.UUID `json:"maker_id"`
+type IncrementWebhookFailuresParams struct {
+	DisableAfter int32       `json:"disable_after"`
+	ID           pgtype.UUID `json:"id"`
 }
 
-func (q *Queries) InsertHouse(ctx context.Context, arg InsertHouseParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, insertHouse, arg.Name, arg.MakerID)
-	var id pgtype.UUID
-	err := row.Scan(&id)
-	return id, err
+// disables the webhook when the failures reach disable_after
+func (q *Queries) IncrementWebhookFailures(ctx context.Context, arg IncrementWebhookFailuresParams) (bool, error) {
+	row := q.db.QueryRow(ctx, incrementWebhookFailures, arg.DisableAfter, arg.ID)
+	var disabled bool
+	err := row.Scan(&disabled)
+	return disabled, err
 }
 
-const insertNote = `-- name: InsertNote :one
-INSERT INTO house_notes (title, content, house_id, maker_id)
+const insertAccessToken = `-- name: InsertAccessToken :one
+INSERT INTO access_tokens (user_id, name, token_hash, scopes)
 VALUES ($1, $2, $3, $4)
 RETURNING id
 `
 
-type InsertNoteParams struct {
-	Title   string      `json:"title"`
-	Content string      `json:"content"`
-	HouseID pgtype.UUID `json:"house_id"`
-	MakerID pgtype.UUID `json:"maker_id"`
+type InsertAccessTokenParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	Name      string      `json:"name"`
+	TokenHash string      `json:"token_hash"`
+	Scopes    []string    `json:"scopes"`
 }
 
-func (q *Queries) InsertNote(ctx context.Context, arg InsertNoteParams) (int32, error) {
-	row := q.db.QueryRow(ctx, insertNote,
-		arg.Title,
-		arg.Content,
-		arg.HouseID,
-		arg.MakerID,
+func (q *Queries) InsertAccessToken(ctx context.Context, arg InsertAccessTokenParams) (int64, error) {
+	row := q.db.QueryRow(ctx, insertAccessToken,
+		arg.UserID,
+		arg.Name,
+		arg.TokenHash,
+		arg.Scopes,
 	)
-	var id int32
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertCalendarFeed = `-- name: InsertCalendarFeed :one
+INSERT INTO calendar_feeds (user_id, house_id, token_hash)
+VALUES ($1, $2, $3)
+RETURNING id
+`
+
+type InsertCalendarFeedParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	HouseID   pgtype.UUID `json:"house_id"`
+	TokenHash string      `json:"token_hash"`
+}
+
+func (q *Queries) InsertCalendarFeed(ctx context.Context, arg InsertCalendarFeedParams) (int64, error) {
+	row := q.db.QueryRow(ctx, insertCalendarFeed, arg.UserID, arg.HouseID, arg.TokenHash)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertHouse = `-- name: InsertHouse :one
+INSERT INTO houses (name, maker_id)
+VALUES ($1, $2)
+RETURNING id
+`
+
+type InsertHouseParams struct {
+	Name    string      `json:"name"`
+	MakerID pgtype.UUID `json:"maker_id"`
+}
+
+func (q *Queries) InsertHouse(ctx context.Context, arg InsertHouseParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertHouse, arg.Name, arg.MakerID)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertHouseEvent = `-- name: InsertHouseEvent :one
+INSERT INTO house_events (event_type, payload, house_id, actor_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id,
+  created_at
+`
+
+type InsertHouseEventParams struct {
+	EventType string      `json:"event_type"`
+	Payload   []byte      `json:"payload"`
+	HouseID   pgtype.UUID `json:"house_id"`
+	ActorID   pgtype.UUID `json:"actor_id"`
+}
+
+type InsertHouseEventRow struct {
+	ID        int64              `json:"id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) InsertHouseEvent(ctx context.Context, arg InsertHouseEventParams) (InsertHouseEventRow, error) {
+	row := q.db.QueryRow(ctx, insertHouseEvent,
+		arg.EventType,
+		arg.Payload,
+		arg.HouseID,
+		arg.ActorID,
+	)
+	var i InsertHouseEventRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const insertHouseWebhook = `-- name: InsertHouseWebhook :one
+INSERT INTO house_webhooks (house_id, url, secret, event_types)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`
+
+type InsertHouseWebhookParams struct {
+	HouseID    pgtype.UUID `json:"house_id"`
+	Url        string      `json:"url"`
+	Secret     string      `json:"secret"`
+	EventTypes []string    `json:"event_types"`
+}
+
+func (q *Queries) InsertHouseWebhook(ctx context.Context, arg InsertHouseWebhookParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertHouseWebhook,
+		arg.HouseID,
+		arg.Url,
+		arg.Secret,
+		arg.EventTypes,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertMessage = `-- name: InsertMessage :one
+INSERT INTO messages (content, conversation_id, sender_id, reply_to_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, content, conversation_id, sender_id, created_at, updated_at, reply_to_id
+`
+
+type InsertMessageParams struct {
+	Content        string      `json:"content"`
+	ConversationID pgtype.UUID `json:"conversation_id"`
+	SenderID       pgtype.UUID `json:"sender_id"`
+	ReplyToID      pgtype.UUID `json:"reply_to_id"`
+}
+
+func (q *Queries) InsertMessage(ctx context.Context, arg InsertMessageParams) (Message, error) {
+	row := q.db.QueryRow(ctx, insertMessage,
+		arg.Content,
+		arg.ConversationID,
+		arg.SenderID,
+		arg.ReplyToID,
+	)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.ConversationID,
+		&i.SenderID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReplyToID,
+	)
+	return i, err
+}
+
+const insertNote = `-- name: InsertNote :one
+INSERT INTO house_notes (title, content, house_id, maker_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`
+
+type InsertNoteParams struct {
+	Title   string      `json:"title"`
+	Content string      `json:"content"`
+	HouseID pgtype.UUID `json:"house_id"`
+	MakerID pgtype.UUID `json:"maker_id"`
+}
+
+func (q *Queries) InsertNote(ctx context.Context, arg InsertNoteParams) (int32, error) {
+	row := q.db.QueryRow(ctx, insertNote,
+		arg.Title,
+		arg.Content,
+		arg.HouseID,
+		arg.MakerID,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertNotification = `-- name: InsertNotification :execrows
+INSERT INTO notifications (
+    user_id,
+    kind,
+    subject,
+    link,
+    house_id,
+    actor_id,
+    event_id
+  )
+SELECT $1::uuid,
+  $2::text,
+  $3::text,
+  $4::text,
+  $5::uuid,
+  $6::uuid,
+  $7::bigint
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM notification_preferences
+    WHERE user_id = $1::uuid
+      AND kind = $2::text
+      AND NOT enabled
+  ) ON CONFLICT DO NOTHING
+`
+
+type InsertNotificationParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Kind    string      `json:"kind"`
+	Subject string      `json:"subject"`
+	Link    string      `json:"link"`
+	HouseID pgtype.UUID `json:"house_id"`
+	ActorID pgtype.UUID `json:"actor_id"`
+	EventID *int64      `json:"event_id"`
+}
+
+func (q *Queries) InsertNotification(ctx context.Context, arg InsertNotificationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertNotification,
+		arg.UserID,
+		arg.Kind,
+		arg.Subject,
+		arg.Link,
+		arg.HouseID,
+		arg.ActorID,
+		arg.EventID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const insertOutboxMessage = `-- name: InsertOutboxMessage :exec
+INSERT INTO outbox (topic, message_key, payload)
+VALUES ($1, $2, $3)
+`
+
+type InsertOutboxMessageParams struct {
+	Topic      string `json:"topic"`
+	MessageKey string `json:"message_key"`
+	Payload    []byte `json:"payload"`
+}
+
+func (q *Queries) InsertOutboxMessage(ctx context.Context, arg InsertOutboxMessageParams) error {
+	_, err := q.db.Exec(ctx, insertOutboxMessage, arg.Topic, arg.MessageKey, arg.Payload)
+	return err
+}
+
+const insertPayment = `-- name: InsertPayment :one
+INSERT INTO house_payments (
+    payment_name,
+    amount,
+    requester_id,
+    house_id,
+    due_date
+  )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id
+`
+
+type InsertPaymentParams struct {
+	PaymentName string         `json:"payment_name"`
+	Amount      pgtype.Numeric `json:"amount"`
+	RequesterID pgtype.UUID    `json:"requester_id"`
+	HouseID     pgtype.UUID    `json:"house_id"`
+	DueDate     pgtype.Date    `json:"due_date"`
+}
+
+func (q *Queries) InsertPayment(ctx context.Context, arg InsertPaymentParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertPayment,
+		arg.PaymentName,
+		arg.Amount,
+		arg.RequesterID,
+		arg.HouseID,
+		arg.DueDate,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertPaymentPayer = `-- name: InsertPaymentPayer :exec
+INSERT INTO house_payment_payers (payment_id, payer_id)
+VALUES ($1, $2) ON CONFLICT DO NOTHING
+`
+
+type InsertPaymentPayerParams struct {
+	PaymentID pgtype.UUID `json:"payment_id"`
+	PayerID   pgtype.UUID `json:"payer_id"`
+}
+
+func (q *Queries) InsertPaymentPayer(ctx context.Context, arg InsertPaymentPayerParams) error {
+	_, err := q.db.Exec(ctx, insertPaymentPayer, arg.PaymentID, arg.PayerID)
+	return err
+}
+
+const insertRecoveryCodes = `-- name: InsertRecoveryCodes :exec
+INSERT INTO recovery_codes (user_id, code_hash)
+SELECT $1::uuid,
+  UNNEST($2::text [])
+`
+
+type InsertRecoveryCodesParams struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	CodeHashes []string    `json:"code_hashes"`
+}
+
+func (q *Queries) InsertRecoveryCodes(ctx context.Context, arg InsertRecoveryCodesParams) error {
+	_, err := q.db.Exec(ctx, insertRecoveryCodes, arg.UserID, arg.CodeHashes)
+	return err
+}
+
+const insertReminder = `-- name: InsertReminder :one
+INSERT INTO house_reminders (
+    content,
+    house_id,
+    maker_id,
+    due_date,
+    recurrence,
+    assignee_id
+  )
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`
+
+type InsertReminderParams struct {
+	Content    []byte             `json:"content"`
+	HouseID    pgtype.UUID        `json:"house_id"`
+	MakerID    pgtype.UUID        `json:"maker_id"`
+	DueDate    pgtype.Date        `json:"due_date"`
+	Recurrence ReminderRecurrence `json:"recurrence"`
+	AssigneeID pgtype.UUID        `json:"assignee_id"`
+}
+
+func (q *Queries) InsertReminder(ctx context.Context, arg InsertReminderParams) (int32, error) {
+	row := q.db.QueryRow(ctx, insertReminder,
+		arg.Content,
+		arg.HouseID,
+		arg.MakerID,
+		arg.DueDate,
+		arg.Recurrence,
+		arg.AssigneeID,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertShoppingItem = `-- name: InsertShoppingItem :one
+INSERT INTO shopping_items (
+    house_id,
+    item_name,
+    quantity,
+    category,
+    added_by
+  )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id
+`
+
+type InsertShoppingItemParams struct {
+	HouseID  pgtype.UUID `json:"house_id"`
+	ItemName string      `json:"item_name"`
+	Quantity string      `json:"quantity"`
+	Category string      `json:"category"`
+	AddedBy  pgtype.UUID `json:"added_by"`
+}
+
+func (q *Queries) InsertShoppingItem(ctx context.Context, arg InsertShoppingItemParams) (int64, error) {
+	row := q.db.QueryRow(ctx, insertShoppingItem,
+		arg.HouseID,
+		arg.ItemName,
+		arg.Quantity,
+		arg.Category,
+		arg.AddedBy,
+	)
+	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
@@ -149,6 +1020,43 @@ func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (pgtype.
 	return id, err
 }
 
+const insertUserBlock = `-- name: InsertUserBlock :exec
+INSERT INTO user_blocks (user_id, blocked_id)
+VALUES ($1, $2) ON CONFLICT DO NOTHING
+`
+
+type InsertUserBlockParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	BlockedID pgtype.UUID `json:"blocked_id"`
+}
+
+func (q *Queries) InsertUserBlock(ctx context.Context, arg InsertUserBlockParams) error {
+	_, err := q.db.Exec(ctx, insertUserBlock, arg.UserID, arg.BlockedID)
+	return err
+}
+
+const insertUserIdentity = `-- name: InsertUserIdentity :exec
+INSERT INTO user_identities (user_id, provider, subject, email)
+VALUES ($1, $2, $3, $4)
+`
+
+type InsertUserIdentityParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	Provider string      `json:"provider"`
+	Subject  string      `json:"subject"`
+	Email    *string     `json:"email"`
+}
+
+func (q *Queries) InsertUserIdentity(ctx context.Context, arg InsertUserIdentityParams) error {
+	_, err := q.db.Exec(ctx, insertUserIdentity,
+		arg.UserID,
+		arg.Provider,
+		arg.Subject,
+		arg.Email,
+	)
+	return err
+}
+
 const insertUserIntoHouse = `-- name: InsertUserIntoHouse :exec
 INSERT INTO user_houses (user_id, house_id)
 VALUES ($1, $2) ON CONFLICT DO NOTHING
@@ -164,6 +1072,112 @@ func (q *Queries) InsertUserIntoHouse(ctx context.Context, arg InsertUserIntoHou
 	return err
 }
 
+const insertVerifiedUser = `-- name: InsertVerifiedUser :one
+INSERT INTO users (email, email_validated, username, password, password_set)
+VALUES ($1, TRUE, $2, $3, FALSE)
+RETURNING id
+`
+
+type InsertVerifiedUserParams struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// user made from an identity, the provider has verified the email
+func (q *Queries) InsertVerifiedUser(ctx context.Context, arg InsertVerifiedUserParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, insertVerifiedUser, arg.Email, arg.Username, arg.Password)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertWebhookDelivery = `-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+    webhook_id,
+    event_id,
+    event_type,
+    attempt,
+    status_code,
+    error,
+    duration_ms
+  )
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type InsertWebhookDeliveryParams struct {
+	WebhookID  pgtype.UUID `json:"webhook_id"`
+	EventID    int64       `json:"event_id"`
+	EventType  string      `json:"event_type"`
+	Attempt    int32       `json:"attempt"`
+	StatusCode *int32      `json:"status_code"`
+	Error      string      `json:"error"`
+	DurationMs int32       `json:"duration_ms"`
+}
+
+func (q *Queries) InsertWebhookDelivery(ctx context.Context, arg InsertWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, insertWebhookDelivery,
+		arg.WebhookID,
+		arg.EventID,
+		arg.EventType,
+		arg.Attempt,
+		arg.StatusCode,
+		arg.Error,
+		arg.DurationMs,
+	)
+	return err
+}
+
+const isEmailValidated = `-- name: IsEmailValidated :one
+SELECT email_validated
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) IsEmailValidated(ctx context.Context, id pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isEmailValidated, id)
+	var email_validated bool
+	err := row.Scan(&email_validated)
+	return email_validated, err
+}
+
+const isTwoFactorEnabled = `-- name: IsTwoFactorEnabled :one
+SELECT EXISTS (
+    SELECT 1
+    FROM user_totp
+    WHERE user_id = $1
+      AND confirmed_at IS NOT NULL
+  )
+`
+
+func (q *Queries) IsTwoFactorEnabled(ctx context.Context, userID pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isTwoFactorEnabled, userID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const isUserBlocked = `-- name: IsUserBlocked :one
+SELECT EXISTS (
+    SELECT 1
+    FROM user_blocks
+    WHERE user_id = $1
+      AND blocked_id = $2
+  )
+`
+
+type IsUserBlockedParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	BlockedID pgtype.UUID `json:"blocked_id"`
+}
+
+func (q *Queries) IsUserBlocked(ctx context.Context, arg IsUserBlockedParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isUserBlocked, arg.UserID, arg.BlockedID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const isUserHouseMaker = `-- name: IsUserHouseMaker :one
 SELECT EXISTS (
     SELECT 1
@@ -185,6 +1199,27 @@ func (q *Queries) IsUserHouseMaker(ctx context.Context, arg IsUserHouseMakerPara
 	return exists, err
 }
 
+const isUserInHouse = `-- name: IsUserInHouse :one
+SELECT EXISTS (
+    SELECT 1
+    FROM user_houses
+    WHERE house_id = $1
+      AND user_id = $2
+  )
+`
+
+type IsUserInHouseParams struct {
+	HouseID pgtype.UUID `json:"house_id"`
+	UserID  pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) IsUserInHouse(ctx context.Context, arg IsUserInHouseParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isUserInHouse, arg.HouseID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const isUserNoteMaker = `-- name: IsUserNoteMaker :one
 SELECT EXISTS (
     SELECT 1
@@ -206,9 +1241,626 @@ func (q *Queries) IsUserNoteMaker(ctx context.Context, arg IsUserNoteMakerParams
 	return exists, err
 }
 
-const selectHouse = `-- name: SelectHouse :one
-SELECT id, name, maker_id, created_at, updated_at
-FROM houses
+const isUserPasswordSet = `-- name: IsUserPasswordSet :one
+SELECT password_set
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) IsUserPasswordSet(ctx context.Context, id pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isUserPasswordSet, id)
+	var password_set bool
+	err := row.Scan(&password_set)
+	return password_set, err
+}
+
+const lockHouse = `-- name: LockHouse :one
+SELECT id, name, maker_id, created_at, updated_at, picture_key
+FROM houses
+WHERE id = $1 FOR
+UPDATE
+`
+
+// held until the end of the transaction, members are changed under the same lock
+func (q *Queries) LockHouse(ctx context.Context, id pgtype.UUID) (House, error) {
+	row := q.db.QueryRow(ctx, lockHouse, id)
+	var i House
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.MakerID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PictureKey,
+	)
+	return i, err
+}
+
+const lockNote = `-- name: LockNote :one
+SELECT updated_at
+FROM house_notes
+WHERE id = $1 FOR
+UPDATE
+`
+
+func (q *Queries) LockNote(ctx context.Context, id int32) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, lockNote, id)
+	var updated_at pgtype.Timestamptz
+	err := row.Scan(&updated_at)
+	return updated_at, err
+}
+
+const markAllNotificationsRead = `-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+SET read_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+  AND read_at IS NULL
+`
+
+func (q *Queries) MarkAllNotificationsRead(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markAllNotificationsRead, userID)
+	return err
+}
+
+const markNotificationEmailed = `-- name: MarkNotificationEmailed :exec
+UPDATE notifications
+SET emailed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) MarkNotificationEmailed(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, markNotificationEmailed, id)
+	return err
+}
+
+const markNotificationRead = `-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+WHERE id = $1
+  AND user_id = $2
+RETURNING link
+`
+
+type MarkNotificationReadParams struct {
+	ID     int64       `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (string, error) {
+	row := q.db.QueryRow(ctx, markNotificationRead, arg.ID, arg.UserID)
+	var link string
+	err := row.Scan(&link)
+	return link, err
+}
+
+const markRemindersDue = `-- name: MarkRemindersDue :many
+UPDATE house_reminders hr
+SET due_event_date = hr.due_date
+WHERE hr.id IN (
+    SELECT id
+    FROM house_reminders
+    WHERE reminder_status = 'in-progress'
+      AND due_date <= CURRENT_DATE
+      AND due_event_date IS DISTINCT FROM due_date
+    ORDER BY due_date,
+      id
+    LIMIT $1 FOR
+    UPDATE SKIP LOCKED
+  )
+RETURNING hr.id,
+  hr.house_id,
+  hr.content
+`
+
+type MarkRemindersDueRow struct {
+	ID      int32       `json:"id"`
+	HouseID pgtype.UUID `json:"house_id"`
+	Content []byte      `json:"content"`
+}
+
+// reminders whose due date has come and have not had their event for it yet
+func (q *Queries) MarkRemindersDue(ctx context.Context, limit int32) ([]MarkRemindersDueRow, error) {
+	rows, err := q.db.Query(ctx, markRemindersDue, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MarkRemindersDueRow
+	for rows.Next() {
+		var i MarkRemindersDueRow
+		if err := rows.Scan(&i.ID, &i.HouseID, &i.Content); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resetWebhookFailures = `-- name: ResetWebhookFailures :exec
+UPDATE house_webhooks
+SET failure_count = 0
+WHERE id = $1
+  AND failure_count <> 0
+`
+
+func (q *Queries) ResetWebhookFailures(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, resetWebhookFailures, id)
+	return err
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :exec
+UPDATE users
+SET deletion_scheduled_at = $1
+WHERE id = $2
+`
+
+type ScheduleUserDeletionParams struct {
+	ScheduledAt pgtype.Timestamptz `json:"scheduled_at"`
+	UserID      pgtype.UUID        `json:"user_id"`
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) error {
+	_, err := q.db.Exec(ctx, scheduleUserDeletion, arg.ScheduledAt, arg.UserID)
+	return err
+}
+
+const searchUsersExcludingExisting = `-- name: SearchUsersExcludingExisting :many
+SELECT u.id,
+  u.username
+FROM users u
+WHERE u.id <> $1
+  AND u.username NOT IN (
+    SELECT UNNEST($2::text [])
+  )
+  AND NOT EXISTS (
+    SELECT 1
+    FROM user_blocks b
+    WHERE (
+        b.user_id = u.id
+        AND b.blocked_id = $1
+      )
+      OR (
+        b.user_id = $1
+        AND b.blocked_id = u.id
+      )
+  )
+  AND (
+    LOWER(u.email) = LOWER($3::text)
+    OR (
+      STARTS_WITH(LOWER(u.username), LOWER($3::text))
+      AND (
+        u.discoverability = 'everyone'
+        OR (
+          u.discoverability = 'housemates'
+          AND EXISTS (
+            SELECT 1
+            FROM user_houses a
+              INNER JOIN user_houses b ON a.house_id = b.house_id
+            WHERE a.user_id = u.id
+              AND b.user_id = $1
+          )
+        )
+      )
+    )
+  )
+ORDER BY u.username
+LIMIT 10
+`
+
+type SearchUsersExcludingExistingParams struct {
+	SearcherID    pgtype.UUID `json:"searcher_id"`
+	ExistingUsers []string    `json:"existing_users"`
+	Query         string      `json:"query"`
+}
+
+type SearchUsersExcludingExistingRow struct {
+	ID       pgtype.UUID `json:"id"`
+	Username string      `json:"username"`
+}
+
+// users can be found by the exact email, username search respects their discoverability
+func (q *Queries) SearchUsersExcludingExisting(ctx context.Context, arg SearchUsersExcludingExistingParams) ([]SearchUsersExcludingExistingRow, error) {
+	rows, err := q.db.Query(ctx, searchUsersExcludingExisting, arg.SearcherID, arg.ExistingUsers, arg.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchUsersExcludingExistingRow
+	for rows.Next() {
+		var i SearchUsersExcludingExistingRow
+		if err := rows.Scan(&i.ID, &i.Username); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectAccessToken = `-- name: SelectAccessToken :one
+SELECT t.id,
+  t.user_id,
+  u.username,
+  t.scopes,
+  t.last_used_at
+FROM access_tokens t
+  JOIN users u ON u.id = t.user_id
+WHERE t.token_hash = $1
+`
+
+type SelectAccessTokenRow struct {
+	ID         int64              `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Username   string             `json:"username"`
+	Scopes     []string           `json:"scopes"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+}
+
+// user of the token, used to authenticate API requests
+func (q *Queries) SelectAccessToken(ctx context.Context, tokenHash string) (SelectAccessTokenRow, error) {
+	row := q.db.QueryRow(ctx, selectAccessToken, tokenHash)
+	var i SelectAccessTokenRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.Scopes,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const selectCalendarFeed = `-- name: SelectCalendarFeed :one
+SELECT id,
+  user_id,
+  house_id,
+  last_used_at
+FROM calendar_feeds
+WHERE token_hash = $1
+`
+
+type SelectCalendarFeedRow struct {
+	ID         int64              `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	HouseID    pgtype.UUID        `json:"house_id"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+}
+
+func (q *Queries) SelectCalendarFeed(ctx context.Context, tokenHash string) (SelectCalendarFeedRow, error) {
+	row := q.db.QueryRow(ctx, selectCalendarFeed, tokenHash)
+	var i SelectCalendarFeedRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.HouseID,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const selectCalendarPayments = `-- name: SelectCalendarPayments :many
+SELECT hp.id,
+  hp.payment_name,
+  COALESCE(hp.amount, 0)::text amount,
+  hp.due_date,
+  hp.updated_at,
+  h.id house_id,
+  h.name house_name
+FROM house_payments hp
+  INNER JOIN houses h ON h.id = hp.house_id
+  INNER JOIN user_houses uh ON uh.house_id = hp.house_id
+  AND uh.user_id = $1
+WHERE hp.due_date IS NOT NULL
+  AND (
+    $2::uuid IS NULL
+    OR hp.house_id = $2::uuid
+  )
+  AND EXISTS (
+    SELECT 1
+    FROM house_payment_payers hpp
+    WHERE hpp.payment_id = hp.id
+      AND hpp.payment_status = 'incomplete'
+      AND (
+        $2::uuid IS NOT NULL
+        OR hpp.payer_id = $1
+        OR hp.requester_id = $1
+      )
+  )
+ORDER BY hp.due_date,
+  hp.id
+`
+
+type SelectCalendarPaymentsParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	HouseID pgtype.UUID `json:"house_id"`
+}
+
+type SelectCalendarPaymentsRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	PaymentName string             `json:"payment_name"`
+	Amount      string             `json:"amount"`
+	DueDate     pgtype.Date        `json:"due_date"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	HouseID     pgtype.UUID        `json:"house_id"`
+	HouseName   string             `json:"house_name"`
+}
+
+// unpaid payments with a due date in the houses of the user, without a house only the ones the user pays or requested
+func (q *Queries) SelectCalendarPayments(ctx context.Context, arg SelectCalendarPaymentsParams) ([]SelectCalendarPaymentsRow, error) {
+	rows, err := q.db.Query(ctx, selectCalendarPayments, arg.UserID, arg.HouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectCalendarPaymentsRow
+	for rows.Next() {
+		var i SelectCalendarPaymentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PaymentName,
+			&i.Amount,
+			&i.DueDate,
+			&i.UpdatedAt,
+			&i.HouseID,
+			&i.HouseName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectCalendarReminders = `-- name: SelectCalendarReminders :many
+SELECT hr.id,
+  hr.content,
+  hr.due_date,
+  hr.recurrence,
+  hr.updated_at,
+  h.id house_id,
+  h.name house_name,
+  u.username assignee_username
+FROM house_reminders hr
+  INNER JOIN houses h ON h.id = hr.house_id
+  INNER JOIN user_houses uh ON uh.house_id = hr.house_id
+  AND uh.user_id = $1
+  LEFT JOIN users u ON u.id = hr.assignee_id
+WHERE hr.reminder_status = 'in-progress'
+  AND hr.due_date IS NOT NULL
+  AND (
+    $2::uuid IS NULL
+    OR hr.house_id = $2::uuid
+  )
+ORDER BY hr.due_date,
+  hr.id
+`
+
+type SelectCalendarRemindersParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	HouseID pgtype.UUID `json:"house_id"`
+}
+
+type SelectCalendarRemindersRow struct {
+	ID               int32              `json:"id"`
+	Content          []byte             `json:"content"`
+	DueDate          pgtype.Date        `json:"due_date"`
+	Recurrence       ReminderRecurrence `json:"recurrence"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	HouseID          pgtype.UUID        `json:"house_id"`
+	HouseName        string             `json:"house_name"`
+	AssigneeUsername *string            `json:"assignee_username"`
+}
+
+// reminders with a due date in the houses of the user, only in the given house when it is not NULL
+func (q *Queries) SelectCalendarReminders(ctx context.Context, arg SelectCalendarRemindersParams) ([]SelectCalendarRemindersRow, error) {
+	rows, err := q.db.Query(ctx, selectCalendarReminders, arg.UserID, arg.HouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectCalendarRemindersRow
+	for rows.Next() {
+		var i SelectCalendarRemindersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.DueDate,
+			&i.Recurrence,
+			&i.UpdatedAt,
+			&i.HouseID,
+			&i.HouseName,
+			&i.AssigneeUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectConversation = `-- name: SelectConversation :one
+SELECT id,
+  name,
+  recipient_ids,
+  recipient_type,
+  image_key
+FROM conversations
+WHERE id = $1
+`
+
+func (q *Queries) SelectConversation(ctx context.Context, id pgtype.UUID) (Conversation, error) {
+	row := q.db.QueryRow(ctx, selectConversation, id)
+	var i Conversation
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.RecipientIds,
+		&i.RecipientType,
+		&i.ImageKey,
+	)
+	return i, err
+}
+
+const selectConversationMessagesPage = `-- name: SelectConversationMessagesPage :many
+SELECT m.id,
+  m.content,
+  m.sender_id,
+  u.username sender_username,
+  m.reply_to_id,
+  m.created_at,
+  m.updated_at
+FROM messages m
+  LEFT JOIN users u ON m.sender_id = u.id
+WHERE m.conversation_id = $1
+  AND (
+    $2::uuid IS NULL
+    OR m.sender_id = $2::uuid
+  )
+  AND (
+    $3::uuid IS NULL
+    OR CASE
+      $4::text
+      WHEN 'created_at' THEN (m.created_at, m.id) > ($5::timestamptz, $3::uuid)
+      ELSE (m.created_at, m.id) < ($5::timestamptz, $3::uuid)
+    END
+  )
+ORDER BY CASE
+    WHEN $4::text = 'created_at' THEN m.created_at
+  END,
+  CASE
+    WHEN $4::text = '-created_at' THEN m.created_at
+  END DESC,
+  CASE
+    WHEN $4::text LIKE '-%' THEN m.id
+  END DESC,
+  m.id
+LIMIT $6
+`
+
+type SelectConversationMessagesPageParams struct {
+	ConversationID pgtype.UUID        `json:"conversation_id"`
+	SenderIDFilter pgtype.UUID        `json:"sender_id_filter"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	Sort           string             `json:"sort"`
+	AfterTime      pgtype.Timestamptz `json:"after_time"`
+	PageSize       int32              `json:"page_size"`
+}
+
+type SelectConversationMessagesPageRow struct {
+	ID             pgtype.UUID        `json:"id"`
+	Content        string             `json:"content"`
+	SenderID       pgtype.UUID        `json:"sender_id"`
+	SenderUsername *string            `json:"sender_username"`
+	ReplyToID      pgtype.UUID        `json:"reply_to_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+// after_* are from the last message of the previous page
+func (q *Queries) SelectConversationMessagesPage(ctx context.Context, arg SelectConversationMessagesPageParams) ([]SelectConversationMessagesPageRow, error) {
+	rows, err := q.db.Query(ctx, selectConversationMessagesPage,
+		arg.ConversationID,
+		arg.SenderIDFilter,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterTime,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectConversationMessagesPageRow
+	for rows.Next() {
+		var i SelectConversationMessagesPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.SenderID,
+			&i.SenderUsername,
+			&i.ReplyToID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectDeletionScheduledAt = `-- name: SelectDeletionScheduledAt :one
+SELECT deletion_scheduled_at
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) SelectDeletionScheduledAt(ctx context.Context, id pgtype.UUID) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, selectDeletionScheduledAt, id)
+	var deletion_scheduled_at pgtype.Timestamptz
+	err := row.Scan(&deletion_scheduled_at)
+	return deletion_scheduled_at, err
+}
+
+const selectEventWebhooks = `-- name: SelectEventWebhooks :many
+SELECT id,
+  url,
+  secret
+FROM house_webhooks
+WHERE house_id = $1
+  AND disabled_at IS NULL
+  AND $2::text = ANY(event_types)
+`
+
+type SelectEventWebhooksParams struct {
+	HouseID   pgtype.UUID `json:"house_id"`
+	EventType string      `json:"event_type"`
+}
+
+type SelectEventWebhooksRow struct {
+	ID     pgtype.UUID `json:"id"`
+	Url    string      `json:"url"`
+	Secret string      `json:"secret"`
+}
+
+// enabled webhooks of the house subscribed to the event type
+func (q *Queries) SelectEventWebhooks(ctx context.Context, arg SelectEventWebhooksParams) ([]SelectEventWebhooksRow, error) {
+	rows, err := q.db.Query(ctx, selectEventWebhooks, arg.HouseID, arg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectEventWebhooksRow
+	for rows.Next() {
+		var i SelectEventWebhooksRow
+		if err := rows.Scan(&i.ID, &i.Url, &i.Secret); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectHouse = `-- name: SelectHouse :one
+SELECT id, name, maker_id, created_at, updated_at, picture_key
+FROM houses
 WHERE id = $1
 `
 
@@ -221,36 +1873,1374 @@ func (q *Queries) SelectHouse(ctx context.Context, id pgtype.UUID) (House, error
 		&i.MakerID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PictureKey,
 	)
 	return i, err
 }
 
-const selectHouseRoommates = `-- name: SelectHouseRoommates :many
-SELECT u.id,
-  u.username
-FROM users u
-WHERE u.id IN (
-    SELECT user_id
-    FROM user_houses
-    WHERE house_id = $1
-  )
-ORDER BY u.username
+const selectHouseEvents = `-- name: SelectHouseEvents :many
+SELECT he.id,
+  he.event_type,
+  he.payload,
+  he.created_at,
+  u.username actor_username
+FROM house_events he
+  LEFT JOIN users u ON he.actor_id = u.id
+WHERE he.house_id = $1
+  AND (
+    $2::bigint = 0
+    OR he.id < $2::bigint
+  )
+ORDER BY he.id DESC
+LIMIT $3
+`
+
+type SelectHouseEventsParams struct {
+	HouseID  pgtype.UUID `json:"house_id"`
+	BeforeID int64       `json:"before_id"`
+	PageSize int32       `json:"page_size"`
+}
+
+type SelectHouseEventsRow struct {
+	ID            int64              `json:"id"`
+	EventType     string             `json:"event_type"`
+	Payload       []byte             `json:"payload"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ActorUsername *string            `json:"actor_username"`
+}
+
+func (q *Queries) SelectHouseEvents(ctx context.Context, arg SelectHouseEventsParams) ([]SelectHouseEventsRow, error) {
+	rows, err := q.db.Query(ctx, selectHouseEvents, arg.HouseID, arg.BeforeID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectHouseEventsRow
+	for rows.Next() {
+		var i SelectHouseEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.Payload,
+			&i.CreatedAt,
+			&i.ActorUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectHouseNotesPage = `-- name: SelectHouseNotesPage :many
+SELECT hn.id note_id,
+  hn.title,
+  hn.content,
+  hn.maker_id,
+  h.id house_id,
+  h.name house_name,
+  hn.created_at,
+  hn.updated_at
+FROM house_notes hn
+  INNER JOIN houses h ON hn.house_id = h.id
+WHERE hn.house_id = $1
+  AND (
+    $2::text = ''
+    OR hn.title ILIKE '%' || $2::text || '%' ESCAPE '\'
+  )
+  AND (
+    $3::uuid IS NULL
+    OR hn.maker_id = $3::uuid
+  )
+  AND (
+    $4::int = 0
+    OR CASE
+      $5::text
+      WHEN 'title' THEN (hn.title, hn.id) > ($6::text, $4::int)
+      WHEN '-title' THEN (hn.title, hn.id) < ($6::text, $4::int)
+      WHEN 'updated_at' THEN (hn.updated_at, hn.id) > ($7::timestamptz, $4::int)
+      ELSE (hn.updated_at, hn.id) < ($7::timestamptz, $4::int)
+    END
+  )
+ORDER BY CASE
+    WHEN $5::text = 'title' THEN hn.title
+  END,
+  CASE
+    WHEN $5::text = '-title' THEN hn.title
+  END DESC,
+  CASE
+    WHEN $5::text = 'updated_at' THEN hn.updated_at
+  END,
+  CASE
+    WHEN $5::text = '-updated_at' THEN hn.updated_at
+  END DESC,
+  CASE
+    WHEN $5::text LIKE '-%' THEN hn.id
+  END DESC,
+  hn.id
+LIMIT $8
+`
+
+type SelectHouseNotesPageParams struct {
+	HouseID       pgtype.UUID        `json:"house_id"`
+	TitleFilter   string             `json:"title_filter"`
+	MakerIDFilter pgtype.UUID        `json:"maker_id_filter"`
+	AfterID       int32              `json:"after_id"`
+	Sort          string             `json:"sort"`
+	AfterTitle    string             `json:"after_title"`
+	AfterTime     pgtype.Timestamptz `json:"after_time"`
+	PageSize      int32              `json:"page_size"`
+}
+
+type SelectHouseNotesPageRow struct {
+	NoteID    int32              `json:"note_id"`
+	Title     string             `json:"title"`
+	Content   string             `json:"content"`
+	MakerID   pgtype.UUID        `json:"maker_id"`
+	HouseID   pgtype.UUID        `json:"house_id"`
+	HouseName string             `json:"house_name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+// after_* are from the last note of the previous page, sort decides which of them are used
+func (q *Queries) SelectHouseNotesPage(ctx context.Context, arg SelectHouseNotesPageParams) ([]SelectHouseNotesPageRow, error) {
+	rows, err := q.db.Query(ctx, selectHouseNotesPage,
+		arg.HouseID,
+		arg.TitleFilter,
+		arg.MakerIDFilter,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterTitle,
+		arg.AfterTime,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectHouseNotesPageRow
+	for rows.Next() {
+		var i SelectHouseNotesPageRow
+		if err := rows.Scan(
+			&i.NoteID,
+			&i.Title,
+			&i.Content,
+			&i.MakerID,
+			&i.HouseID,
+			&i.HouseName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectHouseReminders = `-- name: SelectHouseReminders :many
+SELECT hr.id,
+  hr.content,
+  hr.maker_id,
+  hr.created_at,
+  hr.due_date,
+  hr.recurrence,
+  hr.assignee_id,
+  u.username assignee_username
+FROM house_reminders hr
+  LEFT JOIN users u ON u.id = hr.assignee_id
+WHERE hr.house_id = $1
+  AND hr.reminder_status = 'in-progress'
+ORDER BY hr.created_at DESC
+`
+
+type SelectHouseRemindersRow struct {
+	ID               int32              `json:"id"`
+	Content          []byte             `json:"content"`
+	MakerID          pgtype.UUID        `json:"maker_id"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	DueDate          pgtype.Date        `json:"due_date"`
+	Recurrence       ReminderRecurrence `json:"recurrence"`
+	AssigneeID       pgtype.UUID        `json:"assignee_id"`
+	AssigneeUsername *string            `json:"assignee_username"`
+}
+
+func (q *Queries) SelectHouseReminders(ctx context.Context, houseID pgtype.UUID) ([]SelectHouseRemindersRow, error) {
+	rows, err := q.db.Query(ctx, selectHouseReminders, houseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectHouseRemindersRow
+	for rows.Next() {
+		var i SelectHouseRemindersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.MakerID,
+			&i.CreatedAt,
+			&i.DueDate,
+			&i.Recurrence,
+			&i.AssigneeID,
+			&i.AssigneeUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectHouseRoommates = `-- name: SelectHouseRoommates :many
+SELECT u.id,
+  u.username
+FROM users u
+WHERE u.id IN (
+    SELECT user_id
+    FROM user_houses
+    WHERE house_id = $1
+  )
+ORDER BY u.username
+`
+
+type SelectHouseRoommatesRow struct {
+	ID       pgtype.UUID `json:"id"`
+	Username string      `json:"username"`
+}
+
+func (q *Queries) SelectHouseRoommates(ctx context.Context, houseID pgtype.UUID) ([]SelectHouseRoommatesRow, error) {
+	rows, err := q.db.Query(ctx, selectHouseRoommates, houseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectHouseRoommatesRow
+	for rows.Next() {
+		var i SelectHouseRoommatesRow
+		if err := rows.Scan(&i.ID, &i.Username); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectHouseWebhooks = `-- name: SelectHouseWebhooks :many
+SELECT id,
+  url,
+  event_types,
+  failure_count,
+  disabled_at,
+  created_at
+FROM house_webhooks
+WHERE house_id = $1
+ORDER BY created_at DESC
+`
+
+type SelectHouseWebhooksRow struct {
+	ID           pgtype.UUID        `json:"id"`
+	Url          string             `json:"url"`
+	EventTypes   []string           `json:"event_types"`
+	FailureCount int32              `json:"failure_count"`
+	DisabledAt   pgtype.Timestamptz `json:"disabled_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) SelectHouseWebhooks(ctx context.Context, houseID pgtype.UUID) ([]SelectHouseWebhooksRow, error) {
+	rows, err := q.db.Query(ctx, selectHouseWebhooks, houseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectHouseWebhooksRow
+	for rows.Next() {
+		var i SelectHouseWebhooksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.EventTypes,
+			&i.FailureCount,
+			&i.DisabledAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectIdentityUser = `-- name: SelectIdentityUser :one
+SELECT u.id,
+  u.username
+FROM user_identities i
+  JOIN users u ON u.id = i.user_id
+WHERE i.provider = $1
+  AND i.subject = $2
+`
+
+type SelectIdentityUserParams struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+}
+
+type SelectIdentityUserRow struct {
+	ID       pgtype.UUID `json:"id"`
+	Username string      `json:"username"`
+}
+
+func (q *Queries) SelectIdentityUser(ctx context.Context, arg SelectIdentityUserParams) (SelectIdentityUserRow, error) {
+	row := q.db.QueryRow(ctx, selectIdentityUser, arg.Provider, arg.Subject)
+	var i SelectIdentityUserRow
+	err := row.Scan(&i.ID, &i.Username)
+	return i, err
+}
+
+const selectNote = `-- name: SelectNote :one
+SELECT hn.id note_id,
+  hn.title,
+  hn.content,
+  hn.maker_id,
+  h.id house_id,
+  h.name house_name,
+  hn.updated_at
+FROM house_notes hn
+  INNER JOIN houses h ON hn.house_id = h.id
+WHERE hn.id = $1
+ORDER BY hn.updated_at
+`
+
+type SelectNoteRow struct {
+	NoteID    int32              `json:"note_id"`
+	Title     string             `json:"title"`
+	Content   string             `json:"content"`
+	MakerID   pgtype.UUID        `json:"maker_id"`
+	HouseID   pgtype.UUID        `json:"house_id"`
+	HouseName string             `json:"house_name"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) SelectNote(ctx context.Context, id int32) (SelectNoteRow, error) {
+	row := q.db.QueryRow(ctx, selectNote, id)
+	var i SelectNoteRow
+	err := row.Scan(
+		&i.NoteID,
+		&i.Title,
+		&i.Content,
+		&i.MakerID,
+		&i.HouseID,
+		&i.HouseName,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const selectNotificationPreferences = `-- name: SelectNotificationPreferences :many
+SELECT kind,
+  enabled
+FROM notification_preferences
+WHERE user_id = $1
+`
+
+type SelectNotificationPreferencesRow struct {
+	Kind    string `json:"kind"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (q *Queries) SelectNotificationPreferences(ctx context.Context, userID pgtype.UUID) ([]SelectNotificationPreferencesRow, error) {
+	rows, err := q.db.Query(ctx, selectNotificationPreferences, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectNotificationPreferencesRow
+	for rows.Next() {
+		var i SelectNotificationPreferencesRow
+		if err := rows.Scan(&i.Kind, &i.Enabled); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectOutboxBatch = `-- name: SelectOutboxBatch :many
+SELECT id,
+  topic,
+  message_key,
+  payload
+FROM outbox
+ORDER BY id
+LIMIT $1 FOR
+UPDATE SKIP LOCKED
+`
+
+type SelectOutboxBatchRow struct {
+	ID         int64  `json:"id"`
+	Topic      string `json:"topic"`
+	MessageKey string `json:"message_key"`
+	Payload    []byte `json:"payload"`
+}
+
+func (q *Queries) SelectOutboxBatch(ctx context.Context, limit int32) ([]SelectOutboxBatchRow, error) {
+	rows, err := q.db.Query(ctx, selectOutboxBatch, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectOutboxBatchRow
+	for rows.Next() {
+		var i SelectOutboxBatchRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Topic,
+			&i.MessageKey,
+			&i.Payload,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectPaymentPayerIDs = `-- name: SelectPaymentPayerIDs :many
+SELECT payer_id
+FROM house_payment_payers
+WHERE payment_id = $1
+`
+
+func (q *Queries) SelectPaymentPayerIDs(ctx context.Context, paymentID pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, selectPaymentPayerIDs, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var payer_id pgtype.UUID
+		if err := rows.Scan(&payer_id); err != nil {
+			return nil, err
+		}
+		items = append(items, payer_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectReminderAssignee = `-- name: SelectReminderAssignee :one
+SELECT assignee_id
+FROM house_reminders
+WHERE id = $1
+`
+
+func (q *Queries) SelectReminderAssignee(ctx context.Context, id int32) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, selectReminderAssignee, id)
+	var assignee_id pgtype.UUID
+	err := row.Scan(&assignee_id)
+	return assignee_id, err
+}
+
+const selectShoppingItems = `-- name: SelectShoppingItems :many
+SELECT si.id,
+  si.item_name,
+  si.quantity,
+  si.category,
+  a.username added_by_username,
+  si.bought_by,
+  b.username bought_by_username,
+  si.updated_at
+FROM shopping_items si
+  LEFT JOIN users a ON a.id = si.added_by
+  LEFT JOIN users b ON b.id = si.bought_by
+WHERE si.house_id = $1
+  AND si.settled_at IS NULL
+ORDER BY si.category,
+  si.id
+`
+
+type SelectShoppingItemsRow struct {
+	ID               int64              `json:"id"`
+	ItemName         string             `json:"item_name"`
+	Quantity         string             `json:"quantity"`
+	Category         string             `json:"category"`
+	AddedByUsername  *string            `json:"added_by_username"`
+	BoughtBy         pgtype.UUID        `json:"bought_by"`
+	BoughtByUsername *string            `json:"bought_by_username"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+// items still on the list of the house
+func (q *Queries) SelectShoppingItems(ctx context.Context, houseID pgtype.UUID) ([]SelectShoppingItemsRow, error) {
+	rows, err := q.db.Query(ctx, selectShoppingItems, houseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectShoppingItemsRow
+	for rows.Next() {
+		var i SelectShoppingItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemName,
+			&i.Quantity,
+			&i.Category,
+			&i.AddedByUsername,
+			&i.BoughtBy,
+			&i.BoughtByUsername,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUnemailedNotification = `-- name: SelectUnemailedNotification :one
+SELECT id
+FROM notifications
+WHERE event_id = $1
+  AND user_id = $2
+  AND emailed_at IS NULL
+`
+
+type SelectUnemailedNotificationParams struct {
+	EventID *int64      `json:"event_id"`
+	UserID  pgtype.UUID `json:"user_id"`
+}
+
+// the notification made from the event, when its email has not been sent yet
+func (q *Queries) SelectUnemailedNotification(ctx context.Context, arg SelectUnemailedNotificationParams) (int64, error) {
+	row := q.db.QueryRow(ctx, selectUnemailedNotification, arg.EventID, arg.UserID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const selectUserAccessTokens = `-- name: SelectUserAccessTokens :many
+SELECT id,
+  name,
+  scopes,
+  last_used_at,
+  created_at
+FROM access_tokens
+WHERE user_id = $1
+ORDER BY id DESC
+`
+
+type SelectUserAccessTokensRow struct {
+	ID         int64              `json:"id"`
+	Name       string             `json:"name"`
+	Scopes     []string           `json:"scopes"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) SelectUserAccessTokens(ctx context.Context, userID pgtype.UUID) ([]SelectUserAccessTokensRow, error) {
+	rows, err := q.db.Query(ctx, selectUserAccessTokens, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserAccessTokensRow
+	for rows.Next() {
+		var i SelectUserAccessTokensRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Scopes,
+			&i.LastUsedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserAvatarKey = `-- name: SelectUserAvatarKey :one
+SELECT avatar_key
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) SelectUserAvatarKey(ctx context.Context, id pgtype.UUID) (*string, error) {
+	row := q.db.QueryRow(ctx, selectUserAvatarKey, id)
+	var avatar_key *string
+	err := row.Scan(&avatar_key)
+	return avatar_key, err
+}
+
+const selectUserBlocks = `-- name: SelectUserBlocks :many
+SELECT u.id,
+  u.username,
+  b.created_at
+FROM user_blocks b
+  INNER JOIN users u ON u.id = b.blocked_id
+WHERE b.user_id = $1
+ORDER BY u.username
+`
+
+type SelectUserBlocksRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	Username  string             `json:"username"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) SelectUserBlocks(ctx context.Context, userID pgtype.UUID) ([]SelectUserBlocksRow, error) {
+	rows, err := q.db.Query(ctx, selectUserBlocks, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserBlocksRow
+	for rows.Next() {
+		var i SelectUserBlocksRow
+		if err := rows.Scan(&i.ID, &i.Username, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserByEmail = `-- name: SelectUserByEmail :one
+SELECT id,
+  username,
+  email_validated
+FROM users
+WHERE email = $1
+`
+
+type SelectUserByEmailRow struct {
+	ID             pgtype.UUID `json:"id"`
+	Username       string      `json:"username"`
+	EmailValidated bool        `json:"email_validated"`
+}
+
+func (q *Queries) SelectUserByEmail(ctx context.Context, email string) (SelectUserByEmailRow, error) {
+	row := q.db.QueryRow(ctx, selectUserByEmail, email)
+	var i SelectUserByEmailRow
+	err := row.Scan(&i.ID, &i.Username, &i.EmailValidated)
+	return i, err
+}
+
+const selectUserCalendarFeeds = `-- name: SelectUserCalendarFeeds :many
+SELECT f.id,
+  f.house_id,
+  h.name house_name,
+  f.last_used_at,
+  f.created_at
+FROM calendar_feeds f
+  LEFT JOIN houses h ON h.id = f.house_id
+WHERE f.user_id = $1
+ORDER BY f.id DESC
+`
+
+type SelectUserCalendarFeedsRow struct {
+	ID         int64              `json:"id"`
+	HouseID    pgtype.UUID        `json:"house_id"`
+	HouseName  *string            `json:"house_name"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) SelectUserCalendarFeeds(ctx context.Context, userID pgtype.UUID) ([]SelectUserCalendarFeedsRow, error) {
+	rows, err := q.db.Query(ctx, selectUserCalendarFeeds, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserCalendarFeedsRow
+	for rows.Next() {
+		var i SelectUserCalendarFeedsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.HouseID,
+			&i.HouseName,
+			&i.LastUsedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserDiscoverability = `-- name: SelectUserDiscoverability :one
+SELECT discoverability
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) SelectUserDiscoverability(ctx context.Context, id pgtype.UUID) (UserDiscoverability, error) {
+	row := q.db.QueryRow(ctx, selectUserDiscoverability, id)
+	var discoverability UserDiscoverability
+	err := row.Scan(&discoverability)
+	return discoverability, err
+}
+
+const selectUserEmail = `-- name: SelectUserEmail :one
+SELECT email,
+  username,
+  locale
+FROM users
+WHERE id = $1
+`
+
+type SelectUserEmailRow struct {
+	Email    string  `json:"email"`
+	Username string  `json:"username"`
+	Locale   *string `json:"locale"`
+}
+
+func (q *Queries) SelectUserEmail(ctx context.Context, id pgtype.UUID) (SelectUserEmailRow, error) {
+	row := q.db.QueryRow(ctx, selectUserEmail, id)
+	var i SelectUserEmailRow
+	err := row.Scan(&i.Email, &i.Username, &i.Locale)
+	return i, err
+}
+
+const selectUserHousesPage = `-- name: SelectUserHousesPage :many
+SELECT h.id,
+  h.name,
+  h.maker_id,
+  h.picture_key,
+  h.created_at,
+  h.updated_at
+FROM houses h
+WHERE (
+    h.id IN (
+      SELECT house_id
+      FROM user_houses uh
+      WHERE uh.user_id = $1
+    )
+    OR h.maker_id = $1
+  )
+  AND (
+    $2::text = ''
+    OR h.name ILIKE '%' || $2::text || '%' ESCAPE '\'
+  )
+  AND (
+    $3::uuid IS NULL
+    OR CASE
+      $4::text
+      WHEN 'name' THEN (h.name, h.id) > ($5::text, $3::uuid)
+      WHEN '-name' THEN (h.name, h.id) < ($5::text, $3::uuid)
+      WHEN 'created_at' THEN (h.created_at, h.id) > ($6::timestamptz, $3::uuid)
+      ELSE (h.created_at, h.id) < ($6::timestamptz, $3::uuid)
+    END
+  )
+ORDER BY CASE
+    WHEN $4::text = 'name' THEN h.name
+  END,
+  CASE
+    WHEN $4::text = '-name' THEN h.name
+  END DESC,
+  CASE
+    WHEN $4::text = 'created_at' THEN h.created_at
+  END,
+  CASE
+    WHEN $4::text = '-created_at' THEN h.created_at
+  END DESC,
+  CASE
+    WHEN $4::text LIKE '-%' THEN h.id
+  END DESC,
+  h.id
+LIMIT $7
+`
+
+type SelectUserHousesPageParams struct {
+	UserID     pgtype.UUID        `json:"user_id"`
+	NameFilter string             `json:"name_filter"`
+	AfterID    pgtype.UUID        `json:"after_id"`
+	Sort       string             `json:"sort"`
+	AfterName  string             `json:"after_name"`
+	AfterTime  pgtype.Timestamptz `json:"after_time"`
+	PageSize   int32              `json:"page_size"`
+}
+
+type SelectUserHousesPageRow struct {
+	ID         pgtype.UUID        `json:"id"`
+	Name       string             `json:"name"`
+	MakerID    pgtype.UUID        `json:"maker_id"`
+	PictureKey *string            `json:"picture_key"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+// after_* are from the last house of the previous page, sort decides which of them are used
+func (q *Queries) SelectUserHousesPage(ctx context.Context, arg SelectUserHousesPageParams) ([]SelectUserHousesPageRow, error) {
+	rows, err := q.db.Query(ctx, selectUserHousesPage,
+		arg.UserID,
+		arg.NameFilter,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterName,
+		arg.AfterTime,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserHousesPageRow
+	for rows.Next() {
+		var i SelectUserHousesPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.MakerID,
+			&i.PictureKey,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserHousesWithNotes = `-- name: SelectUserHousesWithNotes :many
+SELECT h.id house_id,
+  h.name house_name,
+  COUNT(hn.id)::int note_count
+FROM houses h
+  LEFT JOIN house_notes hn ON h.id = hn.house_id
+WHERE h.id IN (
+    SELECT house_id
+    FROM user_houses uh
+    WHERE uh.user_id = $1
+  )
+GROUP BY h.id
+ORDER BY h.name
+`
+
+type SelectUserHousesWithNotesRow struct {
+	HouseID   pgtype.UUID `json:"house_id"`
+	HouseName string      `json:"house_name"`
+	NoteCount int32       `json:"note_count"`
+}
+
+func (q *Queries) SelectUserHousesWithNotes(ctx context.Context, userID pgtype.UUID) ([]SelectUserHousesWithNotesRow, error) {
+	rows, err := q.db.Query(ctx, selectUserHousesWithNotes, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserHousesWithNotesRow
+	for rows.Next() {
+		var i SelectUserHousesWithNotesRow
+		if err := rows.Scan(&i.HouseID, &i.HouseName, &i.NoteCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserIdentities = `-- name: SelectUserIdentities :many
+SELECT provider,
+  email,
+  created_at
+FROM user_identities
+WHERE user_id = $1
+`
+
+type SelectUserIdentitiesRow struct {
+	Provider  string             `json:"provider"`
+	Email     *string            `json:"email"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) SelectUserIdentities(ctx context.Context, userID pgtype.UUID) ([]SelectUserIdentitiesRow, error) {
+	rows, err := q.db.Query(ctx, selectUserIdentities, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserIdentitiesRow
+	for rows.Next() {
+		var i SelectUserIdentitiesRow
+		if err := rows.Scan(&i.Provider, &i.Email, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserNotifications = `-- name: SelectUserNotifications :many
+SELECT n.id,
+  n.kind,
+  n.subject,
+  n.link,
+  n.read_at,
+  n.created_at,
+  h.name house_name,
+  u.username actor_username
+FROM notifications n
+  LEFT JOIN houses h ON h.id = n.house_id
+  LEFT JOIN users u ON u.id = n.actor_id
+WHERE n.user_id = $1
+ORDER BY n.id DESC
+LIMIT $2
+`
+
+type SelectUserNotificationsParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	PageSize int32       `json:"page_size"`
+}
+
+type SelectUserNotificationsRow struct {
+	ID            int64              `json:"id"`
+	Kind          string             `json:"kind"`
+	Subject       string             `json:"subject"`
+	Link          string             `json:"link"`
+	ReadAt        pgtype.Timestamptz `json:"read_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	HouseName     *string            `json:"house_name"`
+	ActorUsername *string            `json:"actor_username"`
+}
+
+func (q *Queries) SelectUserNotifications(ctx context.Context, arg SelectUserNotificationsParams) ([]SelectUserNotificationsRow, error) {
+	rows, err := q.db.Query(ctx, selectUserNotifications, arg.UserID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserNotificationsRow
+	for rows.Next() {
+		var i SelectUserNotificationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Subject,
+			&i.Link,
+			&i.ReadAt,
+			&i.CreatedAt,
+			&i.HouseName,
+			&i.ActorUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserPassword = `-- name: SelectUserPassword :one
+SELECT password
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) SelectUserPassword(ctx context.Context, id pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, selectUserPassword, id)
+	var password string
+	err := row.Scan(&password)
+	return password, err
+}
+
+const selectUserPayment = `-- name: SelectUserPayment :one
+SELECT hp.id,
+  hp.payment_name,
+  COALESCE(hp.amount, 0)::text amount,
+  hp.requester_id,
+  h.id house_id,
+  h.name house_name,
+  hpp.payment_status,
+  hp.created_at,
+  hp.updated_at,
+  hp.due_date
+FROM house_payments hp
+  LEFT JOIN houses h ON hp.house_id = h.id
+  LEFT JOIN house_payment_payers hpp ON hp.id = hpp.payment_id
+  AND hpp.payer_id = $1
+WHERE hp.id = $2
+  AND (
+    hp.requester_id = $1
+    OR hpp.payer_id = $1
+  )
+`
+
+type SelectUserPaymentParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	PaymentID pgtype.UUID `json:"payment_id"`
+}
+
+type SelectUserPaymentRow struct {
+	ID            pgtype.UUID            `json:"id"`
+	PaymentName   string                 `json:"payment_name"`
+	Amount        string                 `json:"amount"`
+	RequesterID   pgtype.UUID            `json:"requester_id"`
+	HouseID       pgtype.UUID            `json:"house_id"`
+	HouseName     *string                `json:"house_name"`
+	PaymentStatus NullHousePaymentStatus `json:"payment_status"`
+	CreatedAt     pgtype.Timestamptz     `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz     `json:"updated_at"`
+	DueDate       pgtype.Date            `json:"due_date"`
+}
+
+func (q *Queries) SelectUserPayment(ctx context.Context, arg SelectUserPaymentParams) (SelectUserPaymentRow, error) {
+	row := q.db.QueryRow(ctx, selectUserPayment, arg.UserID, arg.PaymentID)
+	var i SelectUserPaymentRow
+	err := row.Scan(
+		&i.ID,
+		&i.PaymentName,
+		&i.Amount,
+		&i.RequesterID,
+		&i.HouseID,
+		&i.HouseName,
+		&i.PaymentStatus,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DueDate,
+	)
+	return i, err
+}
+
+const selectUserPayments = `-- name: SelectUserPayments :many
+SELECT hp.id,
+  hp.payment_name,
+  COALESCE(hp.amount, 0)::text amount,
+  hp.requester_id,
+  h.id house_id,
+  h.name house_name,
+  hpp.payment_status
+FROM house_payments hp
+  LEFT JOIN houses h ON hp.house_id = h.id
+  LEFT JOIN house_payment_payers hpp ON hp.id = hpp.payment_id
+  AND hpp.payer_id = $1
+WHERE hp.requester_id = $1
+  OR hpp.payer_id = $1
+ORDER BY hp.created_at DESC
+`
+
+type SelectUserPaymentsRow struct {
+	ID            pgtype.UUID            `json:"id"`
+	PaymentName   string                 `json:"payment_name"`
+	Amount        string                 `json:"amount"`
+	RequesterID   pgtype.UUID            `json:"requester_id"`
+	HouseID       pgtype.UUID            `json:"house_id"`
+	HouseName     *string                `json:"house_name"`
+	PaymentStatus NullHousePaymentStatus `json:"payment_status"`
+}
+
+func (q *Queries) SelectUserPayments(ctx context.Context, userID pgtype.UUID) ([]SelectUserPaymentsRow, error) {
+	rows, err := q.db.Query(ctx, selectUserPayments, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserPaymentsRow
+	for rows.Next() {
+		var i SelectUserPaymentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PaymentName,
+			&i.Amount,
+			&i.RequesterID,
+			&i.HouseID,
+			&i.HouseName,
+			&i.PaymentStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserPaymentsPage = `-- name: SelectUserPaymentsPage :many
+SELECT hp.id,
+  hp.payment_name,
+  COALESCE(hp.amount, 0)::text amount,
+  hp.requester_id,
+  h.id house_id,
+  h.name house_name,
+  hpp.payment_status,
+  hp.created_at,
+  hp.updated_at,
+  hp.due_date
+FROM house_payments hp
+  LEFT JOIN houses h ON hp.house_id = h.id
+  LEFT JOIN house_payment_payers hpp ON hp.id = hpp.payment_id
+  AND hpp.payer_id = $1
+WHERE (
+    hp.requester_id = $1
+    OR hpp.payer_id = $1
+  )
+  AND (
+    $2::uuid IS NULL
+    OR hp.house_id = $2::uuid
+  )
+  AND (
+    $3::text = ''
+    OR hpp.payment_status::text = $3::text
+  )
+  AND (
+    $4::uuid IS NULL
+    OR CASE
+      $5::text
+      WHEN 'name' THEN (hp.payment_name, hp.id) > ($6::text, $4::uuid)
+      WHEN '-name' THEN (hp.payment_name, hp.id) < ($6::text, $4::uuid)
+      WHEN 'created_at' THEN (hp.created_at, hp.id) > ($7::timestamptz, $4::uuid)
+      ELSE (hp.created_at, hp.id) < ($7::timestamptz, $4::uuid)
+    END
+  )
+ORDER BY CASE
+    WHEN $5::text = 'name' THEN hp.payment_name
+  END,
+  CASE
+    WHEN $5::text = '-name' THEN hp.payment_name
+  END DESC,
+  CASE
+    WHEN $5::text = 'created_at' THEN hp.created_at
+  END,
+  CASE
+    WHEN $5::text = '-created_at' THEN hp.created_at
+  END DESC,
+  CASE
+    WHEN $5::text LIKE '-%' THEN hp.id
+  END DESC,
+  hp.id
+LIMIT $8
+`
+
+type SelectUserPaymentsPageParams struct {
+	UserID        pgtype.UUID        `json:"user_id"`
+	HouseIDFilter pgtype.UUID        `json:"house_id_filter"`
+	StatusFilter  string             `json:"status_filter"`
+	AfterID       pgtype.UUID        `json:"after_id"`
+	Sort          string             `json:"sort"`
+	AfterName     string             `json:"after_name"`
+	AfterTime     pgtype.Timestamptz `json:"after_time"`
+	PageSize      int32              `json:"page_size"`
+}
+
+type SelectUserPaymentsPageRow struct {
+	ID            pgtype.UUID            `json:"id"`
+	PaymentName   string                 `json:"payment_name"`
+	Amount        string                 `json:"amount"`
+	RequesterID   pgtype.UUID            `json:"requester_id"`
+	HouseID       pgtype.UUID            `json:"house_id"`
+	HouseName     *string                `json:"house_name"`
+	PaymentStatus NullHousePaymentStatus `json:"payment_status"`
+	CreatedAt     pgtype.Timestamptz     `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz     `json:"updated_at"`
+	DueDate       pgtype.Date            `json:"due_date"`
+}
+
+// after_* are from the last payment of the previous page, sort decides which of them are used
+func (q *Queries) SelectUserPaymentsPage(ctx context.Context, arg SelectUserPaymentsPageParams) ([]SelectUserPaymentsPageRow, error) {
+	rows, err := q.db.Query(ctx, selectUserPaymentsPage,
+		arg.UserID,
+		arg.HouseIDFilter,
+		arg.StatusFilter,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterName,
+		arg.AfterTime,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectUserPaymentsPageRow
+	for rows.Next() {
+		var i SelectUserPaymentsPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PaymentName,
+			&i.Amount,
+			&i.RequesterID,
+			&i.HouseID,
+			&i.HouseName,
+			&i.PaymentStatus,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DueDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUserProfile = `-- name: SelectUserProfile :one
+SELECT u.id,
+  u.username,
+  u.full_name,
+  u.is_full_name_public,
+  u.created_at,
+  uci.contact_information
+FROM users u
+  LEFT JOIN user_contact_information uci ON uci.user_id = u.id
+WHERE u.id = $1
+`
+
+type SelectUserProfileRow struct {
+	ID                 pgtype.UUID        `json:"id"`
+	Username           string             `json:"username"`
+	FullName           *string            `json:"full_name"`
+	IsFullNamePublic   bool               `json:"is_full_name_public"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	ContactInformation []byte             `json:"contact_information"`
+}
+
+func (q *Queries) SelectUserProfile(ctx context.Context, id pgtype.UUID) (SelectUserProfileRow, error) {
+	row := q.db.QueryRow(ctx, selectUserProfile, id)
+	var i SelectUserProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FullName,
+		&i.IsFullNamePublic,
+		&i.CreatedAt,
+		&i.ContactInformation,
+	)
+	return i, err
+}
+
+const selectUserTOTP = `-- name: SelectUserTOTP :one
+SELECT secret,
+  last_used_step,
+  confirmed_at
+FROM user_totp
+WHERE user_id = $1
+`
+
+type SelectUserTOTPRow struct {
+	Secret       string             `json:"secret"`
+	LastUsedStep int64              `json:"last_used_step"`
+	ConfirmedAt  pgtype.Timestamptz `json:"confirmed_at"`
+}
+
+func (q *Queries) SelectUserTOTP(ctx context.Context, userID pgtype.UUID) (SelectUserTOTPRow, error) {
+	row := q.db.QueryRow(ctx, selectUserTOTP, userID)
+	var i SelectUserTOTPRow
+	err := row.Scan(&i.Secret, &i.LastUsedStep, &i.ConfirmedAt)
+	return i, err
+}
+
+const selectUsersBlockingUser = `-- name: SelectUsersBlockingUser :many
+SELECT user_id
+FROM user_blocks
+WHERE blocked_id = $1
+  AND user_id = ANY($2::uuid [])
+ORDER BY user_id
+`
+
+type SelectUsersBlockingUserParams struct {
+	BlockedID pgtype.UUID   `json:"blocked_id"`
+	UserIds   []pgtype.UUID `json:"user_ids"`
+}
+
+// which of the users have blocked the user
+func (q *Queries) SelectUsersBlockingUser(ctx context.Context, arg SelectUsersBlockingUserParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, selectUsersBlockingUser, arg.BlockedID, arg.UserIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var user_id pgtype.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectUsersDueForDeletion = `-- name: SelectUsersDueForDeletion :many
+SELECT id,
+  username
+FROM users
+WHERE deletion_scheduled_at <= CURRENT_TIMESTAMP
+ORDER BY deletion_scheduled_at
+LIMIT $1
 `
 
-type SelectHouseRoommatesRow struct {
+type SelectUsersDueForDeletionRow struct {
 	ID       pgtype.UUID `json:"id"`
 	Username string      `json:"username"`
 }
 
-func (q *Queries) SelectHouseRoommates(ctx context.Context, houseID pgtype.UUID) ([]SelectHouseRoommatesRow, error) {
-	rows, err := q.db.Query(ctx, selectHouseRoommates, houseID)
+func (q *Queries) SelectUsersDueForDeletion(ctx context.Context, limit int32) ([]SelectUsersDueForDeletionRow, error) {
+	rows, err := q.db.Query(ctx, selectUsersDueForDeletion, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []SelectHouseRoommatesRow
+	var items []SelectUsersDueForDeletionRow
 	for rows.Next() {
-		var i SelectHouseRoommatesRow
+		var i SelectUsersDueForDeletionRow
 		if err := rows.Scan(&i.ID, &i.Username); err != nil {
 			return nil, err
 		}
@@ -262,81 +3252,193 @@ func (q *Queries) SelectHouseRoommates(ctx context.Context, houseID pgtype.UUID)
 	return items, nil
 }
 
-const selectNote = `-- name: SelectNote :one
-SELECT hn.id note_id,
-  hn.title,
-  hn.content,
-  hn.maker_id,
-  h.id house_id,
-  h.name house_name
-FROM house_notes hn
-  INNER JOIN houses h ON hn.house_id = h.id
-WHERE hn.id = $1
-ORDER BY hn.updated_at
+const selectWebhookDeliveries = `-- name: SelectWebhookDeliveries :many
+SELECT d.id,
+  d.event_id,
+  d.event_type,
+  d.attempt,
+  d.status_code,
+  d.error,
+  d.duration_ms,
+  d.created_at
+FROM webhook_deliveries d
+  JOIN house_webhooks w ON w.id = d.webhook_id
+WHERE d.webhook_id = $1
+  AND w.house_id = $2
+ORDER BY d.id DESC
+LIMIT $3
 `
 
-type SelectNoteRow struct {
-	NoteID    int32       `json:"note_id"`
-	Title     string      `json:"title"`
-	Content   string      `json:"content"`
-	MakerID   pgtype.UUID `json:"maker_id"`
+type SelectWebhookDeliveriesParams struct {
+	WebhookID pgtype.UUID `json:"webhook_id"`
 	HouseID   pgtype.UUID `json:"house_id"`
-	HouseName string      `json:"house_name"`
+	Limit     int32       `json:"limit"`
 }
 
-func (q *Queries) SelectNote(ctx context.Context, id int32) (SelectNoteRow, error) {
-	row := q.db.QueryRow(ctx, selectNote, id)
-	var i SelectNoteRow
-	err := row.Scan(
-		&i.NoteID,
-		&i.Title,
-		&i.Content,
-		&i.MakerID,
-		&i.HouseID,
-		&i.HouseName,
+type SelectWebhookDeliveriesRow struct {
+	ID         int64              `json:"id"`
+	EventID    int64              `json:"event_id"`
+	EventType  string             `json:"event_type"`
+	Attempt    int32              `json:"attempt"`
+	StatusCode *int32             `json:"status_code"`
+	Error      string             `json:"error"`
+	DurationMs int32              `json:"duration_ms"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+// last deliveries of the webhook, newest first
+func (q *Queries) SelectWebhookDeliveries(ctx context.Context, arg SelectWebhookDeliveriesParams) ([]SelectWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, selectWebhookDeliveries, arg.WebhookID, arg.HouseID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectWebhookDeliveriesRow
+	for rows.Next() {
+		var i SelectWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.EventType,
+			&i.Attempt,
+			&i.StatusCode,
+			&i.Error,
+			&i.DurationMs,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setEmailValidated = `-- name: SetEmailValidated :execrows
+UPDATE users
+SET email_validated = TRUE
+WHERE id = $1
+  AND email = $2
+`
+
+type SetEmailValidatedParams struct {
+	ID    pgtype.UUID `json:"id"`
+	Email string      `json:"email"`
+}
+
+func (q *Queries) SetEmailValidated(ctx context.Context, arg SetEmailValidatedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setEmailValidated, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setShoppingItemBought = `-- name: SetShoppingItemBought :one
+UPDATE shopping_items
+SET bought_by = CASE
+    WHEN $1::boolean THEN $2::uuid
+  END,
+  bought_at = CASE
+    WHEN $1::boolean THEN CURRENT_TIMESTAMP
+  END
+WHERE id = $3
+  AND house_id = $4
+  AND settled_at IS NULL
+  AND (
+    bought_by IS NULL
+    OR bought_by = $2::uuid
+  )
+RETURNING item_name
+`
+
+type SetShoppingItemBoughtParams struct {
+	Bought  bool        `json:"bought"`
+	UserID  pgtype.UUID `json:"user_id"`
+	ID      int64       `json:"id"`
+	HouseID pgtype.UUID `json:"house_id"`
+}
+
+// an item bought by someone else is not taken over, the list shows who bought it
+func (q *Queries) SetShoppingItemBought(ctx context.Context, arg SetShoppingItemBoughtParams) (string, error) {
+	row := q.db.QueryRow(ctx, setShoppingItemBought,
+		arg.Bought,
+		arg.UserID,
+		arg.ID,
+		arg.HouseID,
 	)
+	var item_name string
+	err := row.Scan(&item_name)
+	return item_name, err
+}
+
+const settlePaymentShare = `-- name: SettlePaymentShare :one
+UPDATE house_payment_payers hpp
+SET payment_status = 'done'
+FROM house_payments hp
+WHERE hpp.payment_id = hp.id
+  AND hpp.payment_id = $1
+  AND hpp.payer_id = $2
+RETURNING hp.house_id,
+  hp.payment_name
+`
+
+type SettlePaymentShareParams struct {
+	PaymentID pgtype.UUID `json:"payment_id"`
+	PayerID   pgtype.UUID `json:"payer_id"`
+}
+
+type SettlePaymentShareRow struct {
+	HouseID     pgtype.UUID `json:"house_id"`
+	PaymentName string      `json:"payment_name"`
+}
+
+func (q *Queries) SettlePaymentShare(ctx context.Context, arg SettlePaymentShareParams) (SettlePaymentShareRow, error) {
+	row := q.db.QueryRow(ctx, settlePaymentShare, arg.PaymentID, arg.PayerID)
+	var i SettlePaymentShareRow
+	err := row.Scan(&i.HouseID, &i.PaymentName)
 	return i, err
 }
 
-const selectUserHousesWithNotes = `-- name: SelectUserHousesWithNotes :many
-SELECT h.id house_id,
-  h.name house_name,
-  COALESCE(
-    ARRAY_AGG(hn.id) FILTER (
-      WHERE hn.id IS NOT NULL
-    ),
-    '{}'
-  )::int [] note_ids
-FROM houses h
-  LEFT JOIN house_notes hn ON h.id = hn.house_id
-WHERE h.id IN (
-    SELECT house_id
-    FROM user_houses uh
-    WHERE uh.user_id = $1
-  )
-GROUP BY h.id
-ORDER BY h.name
+const settleShoppingItems = `-- name: SettleShoppingItems :many
+UPDATE shopping_items
+SET settled_at = CURRENT_TIMESTAMP,
+  payment_id = $1
+WHERE house_id = $2
+  AND bought_by = $3
+  AND settled_at IS NULL
+  AND id = ANY($4::bigint [])
+RETURNING item_name
 `
 
-type SelectUserHousesWithNotesRow struct {
+type SettleShoppingItemsParams struct {
+	PaymentID pgtype.UUID `json:"payment_id"`
 	HouseID   pgtype.UUID `json:"house_id"`
-	HouseName string      `json:"house_name"`
-	NoteIds   []int32     `json:"note_ids"`
+	UserID    pgtype.UUID `json:"user_id"`
+	ItemIds   []int64     `json:"item_ids"`
 }
 
-func (q *Queries) SelectUserHousesWithNotes(ctx context.Context, userID pgtype.UUID) ([]SelectUserHousesWithNotesRow, error) {
-	rows, err := q.db.Query(ctx, selectUserHousesWithNotes, userID)
+// takes the items the user bought off the list
+func (q *Queries) SettleShoppingItems(ctx context.Context, arg SettleShoppingItemsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, settleShoppingItems,
+		arg.PaymentID,
+		arg.HouseID,
+		arg.UserID,
+		arg.ItemIds,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []SelectUserHousesWithNotesRow
+	var items []string
 	for rows.Next() {
-		var i SelectUserHousesWithNotesRow
-		if err := rows.Scan(&i.HouseID, &i.HouseName, &i.NoteIds); err != nil {
+		var item_name string
+		if err := rows.Scan(&item_name); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, item_name)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -344,6 +3446,73 @@ func (q *Queries) SelectUserHousesWithNotes(ctx context.Context, userID pgtype.U
 	return items, nil
 }
 
+const touchAccessToken = `-- name: TouchAccessToken :exec
+UPDATE access_tokens
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) TouchAccessToken(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, touchAccessToken, id)
+	return err
+}
+
+const touchCalendarFeed = `-- name: TouchCalendarFeed :exec
+UPDATE calendar_feeds
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) TouchCalendarFeed(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, touchCalendarFeed, id)
+	return err
+}
+
+const transferUserHouses = `-- name: TransferUserHouses :exec
+UPDATE houses h
+SET maker_id = (
+    SELECT uh.user_id
+    FROM user_houses uh
+    WHERE uh.house_id = h.id
+      AND uh.user_id <> $1
+    LIMIT 1
+  )
+WHERE h.maker_id = $1
+`
+
+// the longest living resident can not be known, any resident will do
+func (q *Queries) TransferUserHouses(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, transferUserHouses, userID)
+	return err
+}
+
+const updateConversationImageKey = `-- name: UpdateConversationImageKey :one
+UPDATE conversations c
+SET image_key = $2
+FROM (
+    SELECT id,
+      image_key
+    FROM conversations
+    WHERE id = $1 FOR
+    UPDATE
+  ) previous
+WHERE c.id = previous.id
+RETURNING previous.image_key
+`
+
+type UpdateConversationImageKeyParams struct {
+	ID       pgtype.UUID `json:"id"`
+	ImageKey *string     `json:"image_key"`
+}
+
+// returns the previous key so that its files can be deleted
+func (q *Queries) UpdateConversationImageKey(ctx context.Context, arg UpdateConversationImageKeyParams) (*string, error) {
+	row := q.db.QueryRow(ctx, updateConversationImageKey, arg.ID, arg.ImageKey)
+	var image_key *string
+	err := row.Scan(&image_key)
+	return image_key, err
+}
+
 const updateHouse = `-- name: UpdateHouse :exec
 UPDATE houses
 SET name = $1
@@ -360,6 +3529,33 @@ func (q *Queries) UpdateHouse(ctx context.Context, arg UpdateHouseParams) error
 	return err
 }
 
+const updateHousePictureKey = `-- name: UpdateHousePictureKey :one
+UPDATE houses h
+SET picture_key = $2
+FROM (
+    SELECT id,
+      picture_key
+    FROM houses
+    WHERE id = $1 FOR
+    UPDATE
+  ) previous
+WHERE h.id = previous.id
+RETURNING previous.picture_key
+`
+
+type UpdateHousePictureKeyParams struct {
+	ID         pgtype.UUID `json:"id"`
+	PictureKey *string     `json:"picture_key"`
+}
+
+// returns the previous key so that its files can be deleted
+func (q *Queries) UpdateHousePictureKey(ctx context.Context, arg UpdateHousePictureKeyParams) (*string, error) {
+	row := q.db.QueryRow(ctx, updateHousePictureKey, arg.ID, arg.PictureKey)
+	var picture_key *string
+	err := row.Scan(&picture_key)
+	return picture_key, err
+}
+
 const updateNote = `-- name: UpdateNote :exec
 UPDATE house_notes
 SET title = $2,
@@ -378,10 +3574,216 @@ func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) error {
 	return err
 }
 
+const updateUserAvatarKey = `-- name: UpdateUserAvatarKey :one
+UPDATE users u
+SET avatar_key = $2
+FROM (
+    SELECT id,
+      avatar_key
+    FROM users
+    WHERE id = $1 FOR
+    UPDATE
+  ) previous
+WHERE u.id = previous.id
+RETURNING previous.avatar_key
+`
+
+type UpdateUserAvatarKeyParams struct {
+	ID        pgtype.UUID `json:"id"`
+	AvatarKey *string     `json:"avatar_key"`
+}
+
+// returns the previous key so that its files can be deleted
+func (q *Queries) UpdateUserAvatarKey(ctx context.Context, arg UpdateUserAvatarKeyParams) (*string, error) {
+	row := q.db.QueryRow(ctx, updateUserAvatarKey, arg.ID, arg.AvatarKey)
+	var avatar_key *string
+	err := row.Scan(&avatar_key)
+	return avatar_key, err
+}
+
+const updateUserDiscoverability = `-- name: UpdateUserDiscoverability :exec
+UPDATE users
+SET discoverability = $2
+WHERE id = $1
+`
+
+type UpdateUserDiscoverabilityParams struct {
+	ID              pgtype.UUID         `json:"id"`
+	Discoverability UserDiscoverability `json:"discoverability"`
+}
+
+func (q *Queries) UpdateUserDiscoverability(ctx context.Context, arg UpdateUserDiscoverabilityParams) error {
+	_, err := q.db.Exec(ctx, updateUserDiscoverability, arg.ID, arg.Discoverability)
+	return err
+}
+
+const updateUserLocale = `-- name: UpdateUserLocale :exec
+UPDATE users
+SET locale = $1
+WHERE id = $2
+  AND locale IS DISTINCT FROM $1
+`
+
+type UpdateUserLocaleParams struct {
+	Locale *string     `json:"locale"`
+	ID     pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateUserLocale(ctx context.Context, arg UpdateUserLocaleParams) error {
+	_, err := q.db.Exec(ctx, updateUserLocale, arg.Locale, arg.ID)
+	return err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :execrows
+UPDATE users
+SET password = $2,
+  password_set = TRUE
+WHERE id = $1
+`
+
+type UpdateUserPasswordParams struct {
+	ID       pgtype.UUID `json:"id"`
+	Password string      `json:"password"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserPassword, arg.ID, arg.Password)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :exec
+UPDATE users
+SET username = $2,
+  full_name = $3,
+  is_full_name_public = $4
+WHERE id = $1
+`
+
+type UpdateUserProfileParams struct {
+	ID               pgtype.UUID `json:"id"`
+	Username         string      `json:"username"`
+	FullName         *string     `json:"full_name"`
+	IsFullNamePublic bool        `json:"is_full_name_public"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
+	_, err := q.db.Exec(ctx, updateUserProfile,
+		arg.ID,
+		arg.Username,
+		arg.FullName,
+		arg.IsFullNamePublic,
+	)
+	return err
+}
+
+const upsertNotificationPreference = `-- name: UpsertNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, kind, enabled)
+VALUES ($1, $2, $3) ON CONFLICT (user_id, kind) DO
+UPDATE
+SET enabled = EXCLUDED.enabled
+`
+
+type UpsertNotificationPreferenceParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Kind    string      `json:"kind"`
+	Enabled bool        `json:"enabled"`
+}
+
+func (q *Queries) UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) error {
+	_, err := q.db.Exec(ctx, upsertNotificationPreference, arg.UserID, arg.Kind, arg.Enabled)
+	return err
+}
+
+const upsertUserContactInformation = `-- name: UpsertUserContactInformation :exec
+INSERT INTO user_contact_information (user_id, contact_information)
+VALUES ($1, $2) ON CONFLICT (user_id) DO
+UPDATE
+SET contact_information = EXCLUDED.contact_information
+`
+
+type UpsertUserContactInformationParams struct {
+	UserID             pgtype.UUID `json:"user_id"`
+	ContactInformation []byte      `json:"contact_information"`
+}
+
+func (q *Queries) UpsertUserContactInformation(ctx context.Context, arg UpsertUserContactInformationParams) error {
+	_, err := q.db.Exec(ctx, upsertUserContactInformation, arg.UserID, arg.ContactInformation)
+	return err
+}
+
+const upsertUserTOTP = `-- name: UpsertUserTOTP :execrows
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2) ON CONFLICT (user_id) DO
+UPDATE
+SET secret = EXCLUDED.secret,
+  last_used_step = 0,
+  created_at = CURRENT_TIMESTAMP
+WHERE user_totp.confirmed_at IS NULL
+`
+
+type UpsertUserTOTPParams struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Secret string      `json:"secret"`
+}
+
+// confirmed secret is never replaced, two-factor has to be disabled first
+func (q *Queries) UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) (int64, error) {
+	result, err := q.db.Exec(ctx, upsertUserTOTP, arg.UserID, arg.Secret)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+  AND code_hash = $2
+  AND used_at IS NULL
+`
+
+type UseRecoveryCodeParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	CodeHash string      `json:"code_hash"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, useRecoveryCode, arg.UserID, arg.CodeHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const useTOTPStep = `-- name: UseTOTPStep :execrows
+UPDATE user_totp
+SET last_used_step = $1
+WHERE user_id = $2
+  AND last_used_step < $1
+`
+
+type UseTOTPStepParams struct {
+	Step   int64       `json:"step"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error) {
+	result, err := q.db.Exec(ctx, useTOTPStep, arg.Step, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const userHouses = `-- name: UserHouses :many
 SELECT h.id,
   h.name,
-  h.maker_id
+  h.maker_id,
+  h.picture_key
 FROM houses h
 WHERE h.id IN (
     SELECT house_id
@@ -393,9 +3795,10 @@ ORDER BY h.name
 `
 
 type UserHousesRow struct {
-	ID      pgtype.UUID `json:"id"`
-	Name    string      `json:"name"`
-	MakerID pgtype.UUID `json:"maker_id"`
+	ID         pgtype.UUID `json:"id"`
+	Name       string      `json:"name"`
+	MakerID    pgtype.UUID `json:"maker_id"`
+	PictureKey *string     `json:"picture_key"`
 }
 
 func (q *Queries) UserHouses(ctx context.Context, userID pgtype.UUID) ([]UserHousesRow, error) {
@@ -407,48 +3810,12 @@ func (q *Queries) UserHouses(ctx context.Context, userID pgtype.UUID) ([]UserHou
 	var items []UserHousesRow
 	for rows.Next() {
 		var i UserHousesRow
-		if err := rows.Scan(&i.ID, &i.Name, &i.MakerID); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const usersLikeExcludingExisting = `-- name: UsersLikeExcludingExisting :many
-SELECT id,
-  username
-FROM users
-WHERE username ILIKE $1::text || '%'
-  AND username NOT IN (
-    SELECT UNNEST($2::text [])
-  )
-LIMIT 10
-`
-
-type UsersLikeExcludingExistingParams struct {
-	Username      string   `json:"username"`
-	ExistingUsers []string `json:"existing_users"`
-}
-
-type UsersLikeExcludingExistingRow struct {
-	ID       pgtype.UUID `json:"id"`
-	Username string      `json:"username"`
-}
-
-func (q *Queries) UsersLikeExcludingExisting(ctx context.Context, arg UsersLikeExcludingExistingParams) ([]UsersLikeExcludingExistingRow, error) {
-	rows, err := q.db.Query(ctx, usersLikeExcludingExisting, arg.Username, arg.ExistingUsers)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []UsersLikeExcludingExistingRow
-	for rows.Next() {
-		var i UsersLikeExcludingExistingRow
-		if err := rows.Scan(&i.ID, &i.Username); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.MakerID,
+			&i.PictureKey,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
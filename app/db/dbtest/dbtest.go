@@ -0,0 +1,82 @@
+// database for tests, every test gets its own schema with all of the migrations applied
+//
+// tests using it are skipped when TEST_DATABASE_URL is not set
+package dbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"roommates/db"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const envURL = "TEST_DATABASE_URL"
+
+// pool limited to a new schema which is dropped after the test
+func Pool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	url := getEnv(t)
+	ctx := context.Background()
+
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		t.Fatalf("could not connect to test database: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	schema := "test_" + strings.ToLower(rand.Text())
+	// extensions exist once per database, the migrations would put them in the first test schema
+	_, err = conn.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS moddatetime SCHEMA public")
+	if err != nil {
+		t.Fatalf("could not create extension: %v", err)
+	}
+	if _, err = conn.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("could not create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		conn, err := pgx.Connect(ctx, url)
+		if err != nil {
+			t.Errorf("could not connect to test database: %v", err)
+			return
+		}
+		defer conn.Close(ctx)
+		if _, err = conn.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("could not drop schema %s: %v", schema, err)
+		}
+	})
+
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", envURL, err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("could not create pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	db.MigrateToLatest(pool, migrationDir())
+	return pool
+}
+
+func getEnv(t testing.TB) string {
+	url := strings.TrimSpace(os.Getenv(envURL))
+	if url == "" {
+		t.Skipf("%s not set, skipping database test", envURL)
+	}
+	return url
+}
+
+// db/migrations, found from the location of this file so tests can run from any package
+func migrationDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "migrations")
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idxh_house_payments_house_id;
+ALTER TABLE house_payments DROP COLUMN IF EXISTS house_id;
+--
+DROP INDEX IF EXISTS idx_house_events_house_id;
+DROP TABLE IF EXISTS house_events;
@@ -0,0 +1,32 @@
+-- --- house activity ---
+CREATE TABLE house_events (
+  id BIGSERIAL PRIMARY KEY,
+  event_type TEXT NOT NULL,
+  payload JSONB NOT NULL DEFAULT '{}',
+  house_id UUID NOT NULL REFERENCES houses(id) ON DELETE CASCADE,
+  actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- feed is read newest first per house
+CREATE INDEX idx_house_events_house_id ON house_events (house_id, id);
+-- --- payments ---
+-- payments have to belong somewhere for the activity feed to make sense
+ALTER TABLE house_payments
+ADD COLUMN house_id UUID REFERENCES houses(id) ON DELETE CASCADE;
+-- existing payments get the house of the requester where most of the payers live.
+-- the ones without such a house (requester moved out or was deleted) are kept without one,
+-- their payers still see them but they are in no house feed
+UPDATE house_payments hp
+SET house_id = (
+    SELECT uh.house_id
+    FROM user_houses uh
+      LEFT JOIN house_payment_payers pp ON pp.payment_id = hp.id
+      LEFT JOIN user_houses puh ON puh.user_id = pp.payer_id
+      AND puh.house_id = uh.house_id
+    WHERE uh.user_id = hp.requester_id
+    GROUP BY uh.house_id
+    ORDER BY count(puh.user_id) DESC,
+      uh.house_id
+    LIMIT 1
+  );
+CREATE INDEX idxh_house_payments_house_id ON house_payments USING HASH (house_id);
@@ -0,0 +1,10 @@
+-- --- transactional outbox ---
+-- written in the same transaction as the change itself,
+-- relay publishes the rows to the broker and deletes them afterwards
+CREATE TABLE outbox (
+  id BIGSERIAL PRIMARY KEY,
+  topic TEXT NOT NULL,
+  message_key TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
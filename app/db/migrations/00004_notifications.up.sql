@@ -0,0 +1,27 @@
+-- --- notifications ---
+CREATE TABLE notifications (
+  id BIGSERIAL PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL,
+  subject TEXT NOT NULL DEFAULT '',
+  link TEXT NOT NULL DEFAULT '',
+  house_id UUID REFERENCES houses(id) ON DELETE CASCADE,
+  actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  -- house event the notification was made from, events can be delivered more than once
+  event_id BIGINT,
+  read_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (event_id, user_id)
+);
+CREATE INDEX idx_notifications_user_id ON notifications (user_id, id);
+-- for the unread count in the navbar
+CREATE INDEX idx_notifications_unread ON notifications (user_id)
+WHERE read_at IS NULL;
+--
+-- kinds without a row are enabled
+CREATE TABLE notification_preferences (
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL,
+  PRIMARY KEY (user_id, kind)
+);
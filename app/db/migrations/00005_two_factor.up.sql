@@ -0,0 +1,19 @@
+-- --- two-factor authentication ---
+-- row without confirmed_at is an enrollment that has not been finished
+CREATE TABLE user_totp (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  secret TEXT NOT NULL,
+  -- time step of the last accepted code, the same code can not be used twice
+  last_used_step BIGINT NOT NULL DEFAULT 0,
+  confirmed_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+--
+CREATE TABLE recovery_codes (
+  id BIGSERIAL PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  -- sha256 of the code, codes are random enough to not need a slow hash
+  code_hash TEXT NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE,
+  UNIQUE (user_id, code_hash)
+);
@@ -0,0 +1,14 @@
+-- --- personal access tokens ---
+-- long-lived credentials for the JSON API, scopes limit what they can be used for
+CREATE TABLE access_tokens (
+  id BIGSERIAL PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  -- sha256 of the token, the token itself is shown only once
+  token_hash TEXT NOT NULL UNIQUE,
+  scopes TEXT [] NOT NULL,
+  last_used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+--
+CREATE INDEX idxh_access_tokens_user_id ON access_tokens USING HASH (user_id);
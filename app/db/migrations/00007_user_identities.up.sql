@@ -0,0 +1,14 @@
+-- --- external identities ---
+-- accounts of OpenID Connect providers that can be used to sign in
+CREATE TABLE user_identities (
+  id BIGSERIAL PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  -- name of the provider in the configuration
+  provider VARCHAR(64) NOT NULL,
+  -- "sub" claim, unique per provider
+  subject TEXT NOT NULL,
+  email TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (provider, subject),
+  UNIQUE (user_id, provider)
+);
@@ -0,0 +1,9 @@
+-- messages of deleted users can not be kept with NOT NULL
+DELETE FROM messages
+WHERE sender_id IS NULL;
+ALTER TABLE messages
+ALTER COLUMN sender_id
+SET NOT NULL;
+--
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
@@ -0,0 +1,10 @@
+-- --- account deletion ---
+-- deletion is requested by the user and done after a grace period
+ALTER TABLE users
+ADD COLUMN deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_users_deletion_scheduled_at ON users (deletion_scheduled_at)
+WHERE deletion_scheduled_at IS NOT NULL;
+-- ON DELETE SET NULL could never work with NOT NULL,
+-- messages of deleted users are kept and shown as from a deleted user
+ALTER TABLE messages
+ALTER COLUMN sender_id DROP NOT NULL;
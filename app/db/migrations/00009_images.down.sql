@@ -0,0 +1,5 @@
+ALTER TABLE conversations DROP COLUMN IF EXISTS image_key;
+ALTER TABLE conversations
+ADD COLUMN conversation_image BYTEA;
+ALTER TABLE houses DROP COLUMN IF EXISTS picture_key;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_key;
@@ -0,0 +1,10 @@
+-- --- images ---
+-- images are stored as files outside of the database, see package images,
+-- columns hold the key the files are found by
+ALTER TABLE users
+ADD COLUMN avatar_key TEXT;
+ALTER TABLE houses
+ADD COLUMN picture_key TEXT;
+ALTER TABLE conversations DROP COLUMN conversation_image;
+ALTER TABLE conversations
+ADD COLUMN image_key TEXT;
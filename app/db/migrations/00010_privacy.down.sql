@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_blocks;
+ALTER TABLE users DROP COLUMN IF EXISTS discoverability;
+DROP TYPE IF EXISTS user_discoverability;
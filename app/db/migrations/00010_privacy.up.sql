@@ -0,0 +1,16 @@
+-- --- privacy ---
+-- who can find the user when adding roommates, everyone can find them by the exact email
+CREATE TYPE user_discoverability AS ENUM ('everyone', 'housemates', 'email');
+-- everyone keeps search working the way it did before
+ALTER TABLE users
+ADD COLUMN discoverability user_discoverability NOT NULL DEFAULT 'everyone';
+--
+-- blocked users can not find, add or message the user who blocked them
+CREATE TABLE user_blocks (
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, blocked_id),
+  CHECK (user_id <> blocked_id)
+);
+CREATE INDEX idx_user_blocks_blocked_id ON user_blocks (blocked_id);
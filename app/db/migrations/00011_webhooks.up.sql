@@ -0,0 +1,35 @@
+-- --- house webhooks ---
+-- house events are posted to the url of every enabled webhook subscribed to the event type
+CREATE TABLE house_webhooks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  house_id UUID NOT NULL REFERENCES houses(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  -- key of the HMAC-SHA256 signatures, needed as is to sign, shown only once
+  secret TEXT NOT NULL,
+  event_types TEXT [] NOT NULL,
+  -- events which could not be delivered in a row, a delivered one resets it
+  failure_count INT NOT NULL DEFAULT 0,
+  -- set after too many failures, deliveries stop until the webhook is enabled again
+  disabled_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idxh_house_webhooks_house_id ON house_webhooks USING HASH (house_id);
+CREATE TRIGGER mdt_house_webhooks BEFORE
+UPDATE ON house_webhooks FOR EACH ROW EXECUTE PROCEDURE moddatetime (updated_at);
+--
+-- every attempt of delivering an event
+CREATE TABLE webhook_deliveries (
+  id BIGSERIAL PRIMARY KEY,
+  webhook_id UUID NOT NULL REFERENCES house_webhooks(id) ON DELETE CASCADE,
+  -- house event that was delivered
+  event_id BIGINT NOT NULL,
+  event_type TEXT NOT NULL,
+  attempt INT NOT NULL,
+  -- NULL when there was no response
+  status_code INT,
+  error TEXT NOT NULL DEFAULT '',
+  duration_ms INT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS calendar_feeds;
+ALTER TABLE house_payments DROP COLUMN IF EXISTS due_date;
+ALTER TABLE house_reminders DROP COLUMN IF EXISTS assignee_id;
+ALTER TABLE house_reminders DROP COLUMN IF EXISTS recurrence;
+ALTER TABLE house_reminders DROP COLUMN IF EXISTS due_date;
+DROP TYPE IF EXISTS reminder_recurrence;
@@ -0,0 +1,24 @@
+-- --- calendar ---
+-- reminders and payments can have a due date, the dates are shown in the calendar feeds
+CREATE TYPE reminder_recurrence AS ENUM ('none', 'daily', 'weekly', 'monthly');
+-- a recurring reminder moves to its next due date when it is completed
+ALTER TABLE house_reminders
+ADD COLUMN due_date DATE,
+  ADD COLUMN recurrence reminder_recurrence NOT NULL DEFAULT 'none',
+  -- chore of the resident, NULL when it is for the whole house
+  ADD COLUMN assignee_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE house_payments
+ADD COLUMN due_date DATE;
+--
+-- secret links of the calendar feeds, revoking one deletes it
+CREATE TABLE calendar_feeds (
+  id BIGSERIAL PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  -- NULL for the feed of all houses of the user
+  house_id UUID REFERENCES houses(id) ON DELETE CASCADE,
+  -- sha256 of the token, the token itself is shown only once
+  token_hash TEXT NOT NULL UNIQUE,
+  last_used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idxh_calendar_feeds_user_id ON calendar_feeds USING HASH (user_id);
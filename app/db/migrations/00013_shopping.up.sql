@@ -0,0 +1,24 @@
+-- --- shopping list ---
+-- things the house needs, checked off by the resident who bought them
+CREATE TABLE shopping_items (
+  id BIGSERIAL PRIMARY KEY,
+  house_id UUID NOT NULL REFERENCES houses(id) ON DELETE CASCADE,
+  item_name TEXT NOT NULL,
+  -- free text, "2 kg", empty when it does not matter
+  quantity TEXT NOT NULL DEFAULT '',
+  category TEXT NOT NULL DEFAULT 'other',
+  added_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  -- NULL while the item is still to be bought
+  bought_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  bought_at TIMESTAMP WITH TIME ZONE,
+  -- set when the buyer is done with the shopping, the item leaves the list
+  settled_at TIMESTAMP WITH TIME ZONE,
+  -- payment the item was bought with, NULL when it was not paid for by the house
+  payment_id UUID REFERENCES house_payments(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_shopping_items_house_id ON shopping_items (house_id)
+WHERE settled_at IS NULL;
+CREATE TRIGGER mdt_shopping_items BEFORE
+UPDATE ON shopping_items FOR EACH ROW EXECUTE PROCEDURE moddatetime (updated_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_house_reminders_due_date;
+ALTER TABLE house_reminders DROP COLUMN IF EXISTS due_event_date;
@@ -0,0 +1,11 @@
+-- --- due reminders ---
+-- due date the reminder-due event was recorded for, the event is recorded once per due date
+-- and again after a recurring reminder has moved to its next one
+ALTER TABLE house_reminders
+ADD COLUMN due_event_date DATE;
+-- reminders already past their due date would all get an event at once
+UPDATE house_reminders
+SET due_event_date = due_date
+WHERE due_date < CURRENT_DATE;
+CREATE INDEX idx_house_reminders_due_date ON house_reminders (due_date)
+WHERE reminder_status = 'in-progress';
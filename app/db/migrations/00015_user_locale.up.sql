@@ -0,0 +1,4 @@
+-- --- user locale ---
+-- language the user last signed in with, emails are written in it. NULL is the default language
+ALTER TABLE users
+ADD COLUMN locale TEXT;
@@ -0,0 +1,14 @@
+-- --- user password set ---
+-- users made from an identity get a random password, they confirm account deletion with an emailed link
+ALTER TABLE users
+ADD COLUMN password_set BOOLEAN NOT NULL DEFAULT TRUE;
+-- the user and the identity were inserted in the same transaction, so they have the same created_at.
+-- users who have since reset the password also get the link, which is as safe as asking for it
+UPDATE users u
+SET password_set = FALSE
+WHERE EXISTS (
+    SELECT 1
+    FROM user_identities i
+    WHERE i.user_id = u.id
+      AND i.created_at = u.created_at
+  );
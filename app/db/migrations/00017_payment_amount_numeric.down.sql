@@ -0,0 +1,2 @@
+ALTER TABLE house_payments
+ALTER COLUMN amount TYPE MONEY USING amount::money;
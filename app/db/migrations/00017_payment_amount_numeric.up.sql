@@ -0,0 +1,4 @@
+-- --- payment amount ---
+-- pgx has no codec for MONEY, NUMERIC can be scanned without issues
+ALTER TABLE house_payments
+ALTER COLUMN amount TYPE NUMERIC(12, 2) USING amount::numeric;
@@ -0,0 +1,7 @@
+-- --- notification emails ---
+-- a notification stays unemailed until its email is sent, redelivered events send it then
+ALTER TABLE notifications
+ADD COLUMN emailed_at TIMESTAMP WITH TIME ZONE;
+-- existing ones were emailed or their email was lost, sending them now would be late
+UPDATE notifications
+SET emailed_at = created_at;
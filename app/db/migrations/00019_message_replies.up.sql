@@ -0,0 +1,4 @@
+-- --- chat command replies ---
+-- answer of a chat command to the message it was in, answers have no sender
+ALTER TABLE messages
+ADD COLUMN reply_to_id UUID REFERENCES messages(id) ON DELETE CASCADE;
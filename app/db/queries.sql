@@ -22,7 +22,8 @@ RETURNING id;
 -- name: UserHouses :many
 SELECT h.id,
   h.name,
-  h.maker_id
+  h.maker_id,
+  h.picture_key
 FROM houses h
 WHERE h.id IN (
     SELECT house_id
@@ -31,14 +32,95 @@ WHERE h.id IN (
   )
   OR h.maker_id = $1
 ORDER BY h.name;
--- name: UsersLikeExcludingExisting :many
-SELECT id,
-  username
-FROM users
-WHERE username ILIKE @username::text || '%'
-  AND username NOT IN (
+-- name: SelectUserHousesPage :many
+-- after_* are from the last house of the previous page, sort decides which of them are used
+SELECT h.id,
+  h.name,
+  h.maker_id,
+  h.picture_key,
+  h.created_at,
+  h.updated_at
+FROM houses h
+WHERE (
+    h.id IN (
+      SELECT house_id
+      FROM user_houses uh
+      WHERE uh.user_id = @user_id
+    )
+    OR h.maker_id = @user_id
+  )
+  AND (
+    @name_filter::text = ''
+    OR h.name ILIKE '%' || @name_filter::text || '%' ESCAPE '\'
+  )
+  AND (
+    @after_id::uuid IS NULL
+    OR CASE
+      @sort::text
+      WHEN 'name' THEN (h.name, h.id) > (@after_name::text, @after_id::uuid)
+      WHEN '-name' THEN (h.name, h.id) < (@after_name::text, @after_id::uuid)
+      WHEN 'created_at' THEN (h.created_at, h.id) > (@after_time::timestamptz, @after_id::uuid)
+      ELSE (h.created_at, h.id) < (@after_time::timestamptz, @after_id::uuid)
+    END
+  )
+ORDER BY CASE
+    WHEN @sort::text = 'name' THEN h.name
+  END,
+  CASE
+    WHEN @sort::text = '-name' THEN h.name
+  END DESC,
+  CASE
+    WHEN @sort::text = 'created_at' THEN h.created_at
+  END,
+  CASE
+    WHEN @sort::text = '-created_at' THEN h.created_at
+  END DESC,
+  CASE
+    WHEN @sort::text LIKE '-%' THEN h.id
+  END DESC,
+  h.id
+LIMIT @page_size;
+-- name: SearchUsersExcludingExisting :many
+-- users can be found by the exact email, username search respects their discoverability
+SELECT u.id,
+  u.username
+FROM users u
+WHERE u.id <> @searcher_id
+  AND u.username NOT IN (
     SELECT UNNEST(@existing_users::text [])
   )
+  AND NOT EXISTS (
+    SELECT 1
+    FROM user_blocks b
+    WHERE (
+        b.user_id = u.id
+        AND b.blocked_id = @searcher_id
+      )
+      OR (
+        b.user_id = @searcher_id
+        AND b.blocked_id = u.id
+      )
+  )
+  AND (
+    LOWER(u.email) = LOWER(@query::text)
+    OR (
+      STARTS_WITH(LOWER(u.username), LOWER(@query::text))
+      AND (
+        u.discoverability = 'everyone'
+        OR (
+          u.discoverability = 'housemates'
+          AND EXISTS (
+            SELECT 1
+            FROM user_houses a
+              INNER JOIN user_houses b ON a.house_id = b.house_id
+            WHERE a.user_id = u.id
+              AND b.user_id = @searcher_id
+          )
+        )
+      )
+    )
+  )
+ORDER BY u.username
 LIMIT 10;
 -- name: InsertHouse :one
 INSERT INTO houses (name, maker_id)
@@ -51,9 +133,10 @@ WHERE id = $2;
 -- name: InsertUserIntoHouse :exec
 INSERT INTO user_houses (user_id, house_id)
 VALUES ($1, $2) ON CONFLICT DO NOTHING;
--- name: DeleteHouse :exec
+-- name: DeleteHouse :one
 DELETE FROM houses
-WHERE id = $1;
+WHERE id = $1
+RETURNING picture_key;
 -- name: DeleteHouseUsers :exec
 DELETE FROM user_houses
 WHERE house_id = $1;
@@ -67,19 +150,24 @@ WHERE u.id IN (
     WHERE house_id = $1
   )
 ORDER BY u.username;
+-- name: DeleteHouseUser :execrows
+DELETE FROM user_houses
+WHERE house_id = $1
+  AND user_id = $2;
 -- name: SelectHouse :one
 SELECT *
 FROM houses
 WHERE id = $1;
+-- name: LockHouse :one
+-- held until the end of the transaction, members are changed under the same lock
+SELECT *
+FROM houses
+WHERE id = $1 FOR
+UPDATE;
 -- name: SelectUserHousesWithNotes :many
 SELECT h.id house_id,
   h.name house_name,
-  COALESCE(
-    ARRAY_AGG(hn.id) FILTER (
-      WHERE hn.id IS NOT NULL
-    ),
-    '{}'
-  )::int [] note_ids
+  COUNT(hn.id)::int note_count
 FROM houses h
   LEFT JOIN house_notes hn ON h.id = hn.house_id
 WHERE h.id IN (
@@ -95,11 +183,60 @@ SELECT hn.id note_id,
   hn.content,
   hn.maker_id,
   h.id house_id,
-  h.name house_name
+  h.name house_name,
+  hn.updated_at
 FROM house_notes hn
   INNER JOIN houses h ON hn.house_id = h.id
 WHERE hn.id = $1
 ORDER BY hn.updated_at;
+-- name: SelectHouseNotesPage :many
+-- after_* are from the last note of the previous page, sort decides which of them are used
+SELECT hn.id note_id,
+  hn.title,
+  hn.content,
+  hn.maker_id,
+  h.id house_id,
+  h.name house_name,
+  hn.created_at,
+  hn.updated_at
+FROM house_notes hn
+  INNER JOIN houses h ON hn.house_id = h.id
+WHERE hn.house_id = @house_id
+  AND (
+    @title_filter::text = ''
+    OR hn.title ILIKE '%' || @title_filter::text || '%' ESCAPE '\'
+  )
+  AND (
+    @maker_id_filter::uuid IS NULL
+    OR hn.maker_id = @maker_id_filter::uuid
+  )
+  AND (
+    @after_id::int = 0
+    OR CASE
+      @sort::text
+      WHEN 'title' THEN (hn.title, hn.id) > (@after_title::text, @after_id::int)
+      WHEN '-title' THEN (hn.title, hn.id) < (@after_title::text, @after_id::int)
+      WHEN 'updated_at' THEN (hn.updated_at, hn.id) > (@after_time::timestamptz, @after_id::int)
+      ELSE (hn.updated_at, hn.id) < (@after_time::timestamptz, @after_id::int)
+    END
+  )
+ORDER BY CASE
+    WHEN @sort::text = 'title' THEN hn.title
+  END,
+  CASE
+    WHEN @sort::text = '-title' THEN hn.title
+  END DESC,
+  CASE
+    WHEN @sort::text = 'updated_at' THEN hn.updated_at
+  END,
+  CASE
+    WHEN @sort::text = '-updated_at' THEN hn.updated_at
+  END DESC,
+  CASE
+    WHEN @sort::text LIKE '-%' THEN hn.id
+  END DESC,
+  hn.id
+LIMIT @page_size;
 -- name: InsertNote :one
 INSERT INTO house_notes (title, content, house_id, maker_id)
 VALUES ($1, $2, $3, $4)
@@ -112,6 +249,11 @@ WHERE id = $1;
 -- name: DeleteNote :exec
 DELETE FROM house_notes
 WHERE id = $1;
+-- name: LockNote :one
+SELECT updated_at
+FROM house_notes
+WHERE id = $1 FOR
+UPDATE;
 -- name: IsUserHouseMaker :one
 SELECT EXISTS (
     SELECT 1
@@ -125,4 +267,919 @@ SELECT EXISTS (
     FROM house_notes
     WHERE id = @note_id
       AND maker_id = @user_id
-  );
\ No newline at end of file
+  );
+-- name: IsUserInHouse :one
+SELECT EXISTS (
+    SELECT 1
+    FROM user_houses
+    WHERE house_id = @house_id
+      AND user_id = @user_id
+  );
+-- name: InsertHouseEvent :one
+INSERT INTO house_events (event_type, payload, house_id, actor_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id,
+  created_at;
+-- name: SelectHouseEvents :many
+SELECT he.id,
+  he.event_type,
+  he.payload,
+  he.created_at,
+  u.username actor_username
+FROM house_events he
+  LEFT JOIN users u ON he.actor_id = u.id
+WHERE he.house_id = @house_id
+  AND (
+    @before_id::bigint = 0
+    OR he.id < @before_id::bigint
+  )
+ORDER BY he.id DESC
+LIMIT @page_size;
+-- name: InsertPayment :one
+INSERT INTO house_payments (
+    payment_name,
+    amount,
+    requester_id,
+    house_id,
+    due_date
+  )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id;
+-- name: InsertPaymentPayer :exec
+INSERT INTO house_payment_payers (payment_id, payer_id)
+VALUES ($1, $2) ON CONFLICT DO NOTHING;
+-- name: SelectUserPayments :many
+SELECT hp.id,
+  hp.payment_name,
+  COALESCE(hp.amount, 0)::text amount,
+  hp.requester_id,
+  h.id house_id,
+  h.name house_name,
+  hpp.payment_status
+FROM house_payments hp
+  LEFT JOIN houses h ON hp.house_id = h.id
+  LEFT JOIN house_payment_payers hpp ON hp.id = hpp.payment_id
+  AND hpp.payer_id = @user_id
+WHERE hp.requester_id = @user_id
+  OR hpp.payer_id = @user_id
+ORDER BY hp.created_at DESC;
+-- name: SelectUserPayment :one
+SELECT hp.id,
+  hp.payment_name,
+  COALESCE(hp.amount, 0)::text amount,
+  hp.requester_id,
+  h.id house_id,
+  h.name house_name,
+  hpp.payment_status,
+  hp.created_at,
+  hp.updated_at,
+  hp.due_date
+FROM house_payments hp
+  LEFT JOIN houses h ON hp.house_id = h.id
+  LEFT JOIN house_payment_payers hpp ON hp.id = hpp.payment_id
+  AND hpp.payer_id = @user_id
+WHERE hp.id = @payment_id
+  AND (
+    hp.requester_id = @user_id
+    OR hpp.payer_id = @user_id
+  );
+-- name: SelectUserPaymentsPage :many
+-- after_* are from the last payment of the previous page, sort decides which of them are used
+SELECT hp.id,
+  hp.payment_name,
+  COALESCE(hp.amount, 0)::text amount,
+  hp.requester_id,
+  h.id house_id,
+  h.name house_name,
+  hpp.payment_status,
+  hp.created_at,
+  hp.updated_at,
+  hp.due_date
+FROM house_payments hp
+  LEFT JOIN houses h ON hp.house_id = h.id
+  LEFT JOIN house_payment_payers hpp ON hp.id = hpp.payment_id
+  AND hpp.payer_id = @user_id
+WHERE (
+    hp.requester_id = @user_id
+    OR hpp.payer_id = @user_id
+  )
+  AND (
+    @house_id_filter::uuid IS NULL
+    OR hp.house_id = @house_id_filter::uuid
+  )
+  AND (
+    @status_filter::text = ''
+    OR hpp.payment_status::text = @status_filter::text
+  )
+  AND (
+    @after_id::uuid IS NULL
+    OR CASE
+      @sort::text
+      WHEN 'name' THEN (hp.payment_name, hp.id) > (@after_name::text, @after_id::uuid)
+      WHEN '-name' THEN (hp.payment_name, hp.id) < (@after_name::text, @after_id::uuid)
+      WHEN 'created_at' THEN (hp.created_at, hp.id) > (@after_time::timestamptz, @after_id::uuid)
+      ELSE (hp.created_at, hp.id) < (@after_time::timestamptz, @after_id::uuid)
+    END
+  )
+ORDER BY CASE
+    WHEN @sort::text = 'name' THEN hp.payment_name
+  END,
+  CASE
+    WHEN @sort::text = '-name' THEN hp.payment_name
+  END DESC,
+  CASE
+    WHEN @sort::text = 'created_at' THEN hp.created_at
+  END,
+  CASE
+    WHEN @sort::text = '-created_at' THEN hp.created_at
+  END DESC,
+  CASE
+    WHEN @sort::text LIKE '-%' THEN hp.id
+  END DESC,
+  hp.id
+LIMIT @page_size;
+-- name: SettlePaymentShare :one
+UPDATE house_payment_payers hpp
+SET payment_status = 'done'
+FROM house_payments hp
+WHERE hpp.payment_id = hp.id
+  AND hpp.payment_id = @payment_id
+  AND hpp.payer_id = @payer_id
+RETURNING hp.house_id,
+  hp.payment_name;
+-- name: InsertReminder :one
+INSERT INTO house_reminders (
+    content,
+    house_id,
+    maker_id,
+    due_date,
+    recurrence,
+    assignee_id
+  )
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
+-- name: SelectHouseReminders :many
+SELECT hr.id,
+  hr.content,
+  hr.maker_id,
+  hr.created_at,
+  hr.due_date,
+  hr.recurrence,
+  hr.assignee_id,
+  u.username assignee_username
+FROM house_reminders hr
+  LEFT JOIN users u ON u.id = hr.assignee_id
+WHERE hr.house_id = $1
+  AND hr.reminder_status = 'in-progress'
+ORDER BY hr.created_at DESC;
+-- name: CompleteReminder :one
+-- a recurring reminder with a due date moves to its next due date instead of being completed
+UPDATE house_reminders hr
+SET reminder_status = CASE
+    WHEN hr.recurrence = 'none'
+    OR hr.due_date IS NULL THEN 'complete'
+    ELSE hr.reminder_status
+  END,
+  due_date = CASE
+    hr.recurrence
+    WHEN 'daily' THEN hr.due_date + 1
+    WHEN 'weekly' THEN hr.due_date + 7
+    WHEN 'monthly' THEN (hr.due_date + INTERVAL '1 month')::date
+    ELSE hr.due_date
+  END
+WHERE hr.id = @reminder_id
+  AND hr.house_id IN (
+    SELECT house_id
+    FROM user_houses
+    WHERE user_id = @user_id
+  )
+RETURNING hr.house_id,
+  hr.content,
+  hr.reminder_status;
+-- name: MarkRemindersDue :many
+-- reminders whose due date has come and have not had their event for it yet
+UPDATE house_reminders hr
+SET due_event_date = hr.due_date
+WHERE hr.id IN (
+    SELECT id
+    FROM house_reminders
+    WHERE reminder_status = 'in-progress'
+      AND due_date <= CURRENT_DATE
+      AND due_event_date IS DISTINCT FROM due_date
+    ORDER BY due_date,
+      id
+    LIMIT $1 FOR
+    UPDATE SKIP LOCKED
+  )
+RETURNING hr.id,
+  hr.house_id,
+  hr.content;
+-- name: SelectReminderAssignee :one
+SELECT assignee_id
+FROM house_reminders
+WHERE id = $1;
+-- name: InsertOutboxMessage :exec
+INSERT INTO outbox (topic, message_key, payload)
+VALUES ($1, $2, $3);
+-- name: SelectOutboxBatch :many
+SELECT id,
+  topic,
+  message_key,
+  payload
+FROM outbox
+ORDER BY id
+LIMIT $1 FOR
+UPDATE SKIP LOCKED;
+-- name: DeleteOutboxMessages :exec
+DELETE FROM outbox
+WHERE id = ANY(@ids::bigint []);
+-- name: SelectPaymentPayerIDs :many
+SELECT payer_id
+FROM house_payment_payers
+WHERE payment_id = $1;
+-- name: InsertNotification :execrows
+INSERT INTO notifications (
+    user_id,
+    kind,
+    subject,
+    link,
+    house_id,
+    actor_id,
+    event_id
+  )
+SELECT @user_id::uuid,
+  @kind::text,
+  @subject::text,
+  @link::text,
+  sqlc.narg(house_id)::uuid,
+  sqlc.narg(actor_id)::uuid,
+  sqlc.narg(event_id)::bigint
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM notification_preferences
+    WHERE user_id = @user_id::uuid
+      AND kind = @kind::text
+      AND NOT enabled
+  ) ON CONFLICT DO NOTHING;
+-- name: SelectUnemailedNotification :one
+-- the notification made from the event, when its email has not been sent yet
+SELECT id
+FROM notifications
+WHERE event_id = @event_id
+  AND user_id = @user_id
+  AND emailed_at IS NULL;
+-- name: MarkNotificationEmailed :exec
+UPDATE notifications
+SET emailed_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+-- name: SelectUserNotifications :many
+SELECT n.id,
+  n.kind,
+  n.subject,
+  n.link,
+  n.read_at,
+  n.created_at,
+  h.name house_name,
+  u.username actor_username
+FROM notifications n
+  LEFT JOIN houses h ON h.id = n.house_id
+  LEFT JOIN users u ON u.id = n.actor_id
+WHERE n.user_id = $1
+ORDER BY n.id DESC
+LIMIT @page_size;
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = $1
+  AND read_at IS NULL;
+-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+WHERE id = $1
+  AND user_id = $2
+RETURNING link;
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+SET read_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+  AND read_at IS NULL;
+-- name: SelectNotificationPreferences :many
+SELECT kind,
+  enabled
+FROM notification_preferences
+WHERE user_id = $1;
+-- name: UpsertNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, kind, enabled)
+VALUES ($1, $2, $3) ON CONFLICT (user_id, kind) DO
+UPDATE
+SET enabled = EXCLUDED.enabled;
+-- name: SelectUserEmail :one
+SELECT email,
+  username,
+  locale
+FROM users
+WHERE id = $1;
+-- name: UpdateUserLocale :exec
+UPDATE users
+SET locale = @locale
+WHERE id = @id
+  AND locale IS DISTINCT FROM @locale;
+-- name: IsEmailValidated :one
+SELECT email_validated
+FROM users
+WHERE id = $1;
+-- name: SetEmailValidated :execrows
+UPDATE users
+SET email_validated = TRUE
+WHERE id = $1
+  AND email = $2;
+-- name: SelectUserPassword :one
+SELECT password
+FROM users
+WHERE id = $1;
+-- name: UpdateUserPassword :execrows
+UPDATE users
+SET password = $2,
+  password_set = TRUE
+WHERE id = $1;
+-- name: IsUserPasswordSet :one
+SELECT password_set
+FROM users
+WHERE id = $1;
+-- name: SelectUserTOTP :one
+SELECT secret,
+  last_used_step,
+  confirmed_at
+FROM user_totp
+WHERE user_id = $1;
+-- name: IsTwoFactorEnabled :one
+SELECT EXISTS (
+    SELECT 1
+    FROM user_totp
+    WHERE user_id = $1
+      AND confirmed_at IS NOT NULL
+  );
+-- name: UpsertUserTOTP :execrows
+-- confirmed secret is never replaced, two-factor has to be disabled first
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2) ON CONFLICT (user_id) DO
+UPDATE
+SET secret = EXCLUDED.secret,
+  last_used_step = 0,
+  created_at = CURRENT_TIMESTAMP
+WHERE user_totp.confirmed_at IS NULL;
+-- name: ConfirmUserTOTP :execrows
+UPDATE user_totp
+SET confirmed_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+  AND confirmed_at IS NULL;
+-- name: UseTOTPStep :execrows
+UPDATE user_totp
+SET last_used_step = @step
+WHERE user_id = @user_id
+  AND last_used_step < @step;
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp
+WHERE user_id = $1;
+-- name: InsertRecoveryCodes :exec
+INSERT INTO recovery_codes (user_id, code_hash)
+SELECT @user_id::uuid,
+  UNNEST(@code_hashes::text []);
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+  AND code_hash = $2
+  AND used_at IS NULL;
+-- name: CountRecoveryCodes :one
+SELECT COUNT(*)
+FROM recovery_codes
+WHERE user_id = $1
+  AND used_at IS NULL;
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
+-- name: InsertAccessToken :one
+INSERT INTO access_tokens (user_id, name, token_hash, scopes)
+VALUES ($1, $2, $3, $4)
+RETURNING id;
+-- name: SelectAccessToken :one
+-- user of the token, used to authenticate API requests
+SELECT t.id,
+  t.user_id,
+  u.username,
+  t.scopes,
+  t.last_used_at
+FROM access_tokens t
+  JOIN users u ON u.id = t.user_id
+WHERE t.token_hash = $1;
+-- name: SelectUserAccessTokens :many
+SELECT id,
+  name,
+  scopes,
+  last_used_at,
+  created_at
+FROM access_tokens
+WHERE user_id = $1
+ORDER BY id DESC;
+-- name: TouchAccessToken :exec
+UPDATE access_tokens
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+-- name: DeleteAccessToken :execrows
+DELETE FROM access_tokens
+WHERE id = $1
+  AND user_id = $2;
+-- name: SelectUserByEmail :one
+SELECT id,
+  username,
+  email_validated
+FROM users
+WHERE email = $1;
+-- name: InsertVerifiedUser :one
+-- user made from an identity, the provider has verified the email
+INSERT INTO users (email, email_validated, username, password, password_set)
+VALUES ($1, TRUE, $2, $3, FALSE)
+RETURNING id;
+-- name: SelectIdentityUser :one
+SELECT u.id,
+  u.username
+FROM user_identities i
+  JOIN users u ON u.id = i.user_id
+WHERE i.provider = $1
+  AND i.subject = $2;
+-- name: SelectUserIdentities :many
+SELECT provider,
+  email,
+  created_at
+FROM user_identities
+WHERE user_id = $1;
+-- name: InsertUserIdentity :exec
+INSERT INTO user_identities (user_id, provider, subject, email)
+VALUES ($1, $2, $3, $4);
+-- name: DeleteUserIdentity :execrows
+DELETE FROM user_identities
+WHERE user_id = $1
+  AND provider = $2;
+-- name: SelectDeletionScheduledAt :one
+SELECT deletion_scheduled_at
+FROM users
+WHERE id = $1;
+-- name: ScheduleUserDeletion :exec
+UPDATE users
+SET deletion_scheduled_at = @scheduled_at
+WHERE id = @user_id;
+-- name: CancelUserDeletion :exec
+UPDATE users
+SET deletion_scheduled_at = NULL
+WHERE id = $1;
+-- name: SelectUsersDueForDeletion :many
+SELECT id,
+  username
+FROM users
+WHERE deletion_scheduled_at <= CURRENT_TIMESTAMP
+ORDER BY deletion_scheduled_at
+LIMIT $1;
+-- name: DeleteUserSoleHouses :many
+-- houses nobody else lives in would be left without anyone to see them
+DELETE FROM houses h
+WHERE h.maker_id = @user_id
+  AND NOT EXISTS (
+    SELECT 1
+    FROM user_houses uh
+    WHERE uh.house_id = h.id
+      AND uh.user_id <> @user_id
+  )
+RETURNING h.picture_key;
+-- name: TransferUserHouses :exec
+-- the longest living resident can not be known, any resident will do
+UPDATE houses h
+SET maker_id = (
+    SELECT uh.user_id
+    FROM user_houses uh
+    WHERE uh.house_id = h.id
+      AND uh.user_id <> @user_id
+    LIMIT 1
+  )
+WHERE h.maker_id = @user_id;
+-- name: DeleteUser :one
+DELETE FROM users
+WHERE id = $1
+RETURNING avatar_key;
+-- name: ExportUserProfile :one
+SELECT u.email,
+  u.email_validated,
+  u.username,
+  u.full_name,
+  u.is_full_name_public,
+  u.created_at,
+  u.updated_at,
+  uci.contact_information
+FROM users u
+  LEFT JOIN user_contact_information uci ON uci.user_id = u.id
+WHERE u.id = $1;
+-- name: ExportUserNotes :many
+SELECT hn.id,
+  hn.title,
+  hn.content,
+  h.name house_name,
+  hn.created_at,
+  hn.updated_at
+FROM house_notes hn
+  INNER JOIN houses h ON h.id = hn.house_id
+WHERE hn.maker_id = $1
+ORDER BY hn.id;
+-- name: ExportUserMessages :many
+SELECT id,
+  conversation_id,
+  content,
+  created_at,
+  updated_at
+FROM messages
+WHERE sender_id = $1
+ORDER BY created_at;
+-- name: SelectUserProfile :one
+SELECT u.id,
+  u.username,
+  u.full_name,
+  u.is_full_name_public,
+  u.created_at,
+  uci.contact_information
+FROM users u
+  LEFT JOIN user_contact_information uci ON uci.user_id = u.id
+WHERE u.id = $1;
+-- name: UpdateUserProfile :exec
+UPDATE users
+SET username = $2,
+  full_name = $3,
+  is_full_name_public = $4
+WHERE id = $1;
+-- name: UpsertUserContactInformation :exec
+INSERT INTO user_contact_information (user_id, contact_information)
+VALUES ($1, $2) ON CONFLICT (user_id) DO
+UPDATE
+SET contact_information = EXCLUDED.contact_information;
+-- name: AreUsersHousemates :one
+SELECT EXISTS (
+    SELECT 1
+    FROM user_houses a
+      INNER JOIN user_houses b ON a.house_id = b.house_id
+    WHERE a.user_id = @user_id
+      AND b.user_id = @other_user_id
+  );
+-- name: SelectUserAvatarKey :one
+SELECT avatar_key
+FROM users
+WHERE id = $1;
+-- name: UpdateUserAvatarKey :one
+-- returns the previous key so that its files can be deleted
+UPDATE users u
+SET avatar_key = $2
+FROM (
+    SELECT id,
+      avatar_key
+    FROM users
+    WHERE id = $1 FOR
+    UPDATE
+  ) previous
+WHERE u.id = previous.id
+RETURNING previous.avatar_key;
+-- name: UpdateHousePictureKey :one
+-- returns the previous key so that its files can be deleted
+UPDATE houses h
+SET picture_key = $2
+FROM (
+    SELECT id,
+      picture_key
+    FROM houses
+    WHERE id = $1 FOR
+    UPDATE
+  ) previous
+WHERE h.id = previous.id
+RETURNING previous.picture_key;
+-- name: SelectConversation :one
+SELECT id,
+  name,
+  recipient_ids,
+  recipient_type,
+  image_key
+FROM conversations
+WHERE id = $1;
+-- name: UpdateConversationImageKey :one
+-- returns the previous key so that its files can be deleted
+UPDATE conversations c
+SET image_key = $2
+FROM (
+    SELECT id,
+      image_key
+    FROM conversations
+    WHERE id = $1 FOR
+    UPDATE
+  ) previous
+WHERE c.id = previous.id
+RETURNING previous.image_key;
+-- name: SelectUserDiscoverability :one
+SELECT discoverability
+FROM users
+WHERE id = $1;
+-- name: UpdateUserDiscoverability :exec
+UPDATE users
+SET discoverability = $2
+WHERE id = $1;
+-- name: SelectUserBlocks :many
+SELECT u.id,
+  u.username,
+  b.created_at
+FROM user_blocks b
+  INNER JOIN users u ON u.id = b.blocked_id
+WHERE b.user_id = $1
+ORDER BY u.username;
+-- name: IsUserBlocked :one
+SELECT EXISTS (
+    SELECT 1
+    FROM user_blocks
+    WHERE user_id = $1
+      AND blocked_id = $2
+  );
+-- name: InsertUserBlock :exec
+INSERT INTO user_blocks (user_id, blocked_id)
+VALUES ($1, $2) ON CONFLICT DO NOTHING;
+-- name: DeleteUserBlock :execrows
+DELETE FROM user_blocks
+WHERE user_id = $1
+  AND blocked_id = $2;
+-- name: SelectUsersBlockingUser :many
+-- which of the users have blocked the user
+SELECT user_id
+FROM user_blocks
+WHERE blocked_id = @blocked_id
+  AND user_id = ANY(@user_ids::uuid [])
+ORDER BY user_id;
+-- name: InsertMessage :one
+INSERT INTO messages (content, conversation_id, sender_id, reply_to_id)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+-- name: SelectConversationMessagesPage :many
+-- after_* are from the last message of the previous page
+SELECT m.id,
+  m.content,
+  m.sender_id,
+  u.username sender_username,
+  m.reply_to_id,
+  m.created_at,
+  m.updated_at
+FROM messages m
+  LEFT JOIN users u ON m.sender_id = u.id
+WHERE m.conversation_id = @conversation_id
+  AND (
+    @sender_id_filter::uuid IS NULL
+    OR m.sender_id = @sender_id_filter::uuid
+  )
+  AND (
+    @after_id::uuid IS NULL
+    OR CASE
+      @sort::text
+      WHEN 'created_at' THEN (m.created_at, m.id) > (@after_time::timestamptz, @after_id::uuid)
+      ELSE (m.created_at, m.id) < (@after_time::timestamptz, @after_id::uuid)
+    END
+  )
+ORDER BY CASE
+    WHEN @sort::text = 'created_at' THEN m.created_at
+  END,
+  CASE
+    WHEN @sort::text = '-created_at' THEN m.created_at
+  END DESC,
+  CASE
+    WHEN @sort::text LIKE '-%' THEN m.id
+  END DESC,
+  m.id
+LIMIT @page_size;
+-- name: InsertHouseWebhook :one
+INSERT INTO house_webhooks (house_id, url, secret, event_types)
+VALUES ($1, $2, $3, $4)
+RETURNING id;
+-- name: SelectHouseWebhooks :many
+SELECT id,
+  url,
+  event_types,
+  failure_count,
+  disabled_at,
+  created_at
+FROM house_webhooks
+WHERE house_id = $1
+ORDER BY created_at DESC;
+-- name: SelectEventWebhooks :many
+-- enabled webhooks of the house subscribed to the event type
+SELECT id,
+  url,
+  secret
+FROM house_webhooks
+WHERE house_id = @house_id
+  AND disabled_at IS NULL
+  AND @event_type::text = ANY(event_types);
+-- name: DeleteHouseWebhook :execrows
+DELETE FROM house_webhooks
+WHERE id = $1
+  AND house_id = $2;
+-- name: EnableHouseWebhook :execrows
+UPDATE house_webhooks
+SET failure_count = 0,
+  disabled_at = NULL
+WHERE id = $1
+  AND house_id = $2;
+-- name: ResetWebhookFailures :exec
+UPDATE house_webhooks
+SET failure_count = 0
+WHERE id = $1
+  AND failure_count <> 0;
+-- name: IncrementWebhookFailures :one
+-- disables the webhook when the failures reach disable_after
+UPDATE house_webhooks
+SET failure_count = failure_count + 1,
+  disabled_at = CASE
+    WHEN failure_count + 1 >= @disable_after::int THEN COALESCE(disabled_at, CURRENT_TIMESTAMP)
+    ELSE disabled_at
+  END
+WHERE id = @id
+RETURNING disabled_at IS NOT NULL AS disabled;
+-- name: InsertWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+    webhook_id,
+    event_id,
+    event_type,
+    attempt,
+    status_code,
+    error,
+    duration_ms
+  )
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+-- name: SelectWebhookDeliveries :many
+-- last deliveries of the webhook, newest first
+SELECT d.id,
+  d.event_id,
+  d.event_type,
+  d.attempt,
+  d.status_code,
+  d.error,
+  d.duration_ms,
+  d.created_at
+FROM webhook_deliveries d
+  JOIN house_webhooks w ON w.id = d.webhook_id
+WHERE d.webhook_id = $1
+  AND w.house_id = $2
+ORDER BY d.id DESC
+LIMIT $3;
+-- name: DeleteOldWebhookDeliveries :exec
+-- keeps only the newest `keep` deliveries of the webhook
+DELETE FROM webhook_deliveries
+WHERE webhook_id = @webhook_id
+  AND id <= (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE webhook_id = @webhook_id
+    ORDER BY id DESC
+    OFFSET @keep::int
+    LIMIT 1
+  );
+-- name: InsertCalendarFeed :one
+INSERT INTO calendar_feeds (user_id, house_id, token_hash)
+VALUES ($1, $2, $3)
+RETURNING id;
+-- name: SelectCalendarFeed :one
+SELECT id,
+  user_id,
+  house_id,
+  last_used_at
+FROM calendar_feeds
+WHERE token_hash = $1;
+-- name: SelectUserCalendarFeeds :many
+SELECT f.id,
+  f.house_id,
+  h.name house_name,
+  f.last_used_at,
+  f.created_at
+FROM calendar_feeds f
+  LEFT JOIN houses h ON h.id = f.house_id
+WHERE f.user_id = $1
+ORDER BY f.id DESC;
+-- name: TouchCalendarFeed :exec
+UPDATE calendar_feeds
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+-- name: DeleteCalendarFeed :execrows
+DELETE FROM calendar_feeds
+WHERE id = $1
+  AND user_id = $2;
+-- name: SelectCalendarReminders :many
+-- reminders with a due date in the houses of the user, only in the given house when it is not NULL
+SELECT hr.id,
+  hr.content,
+  hr.due_date,
+  hr.recurrence,
+  hr.updated_at,
+  h.id house_id,
+  h.name house_name,
+  u.username assignee_username
+FROM house_reminders hr
+  INNER JOIN houses h ON h.id = hr.house_id
+  INNER JOIN user_houses uh ON uh.house_id = hr.house_id
+  AND uh.user_id = @user_id
+  LEFT JOIN users u ON u.id = hr.assignee_id
+WHERE hr.reminder_status = 'in-progress'
+  AND hr.due_date IS NOT NULL
+  AND (
+    @house_id::uuid IS NULL
+    OR hr.house_id = @house_id::uuid
+  )
+ORDER BY hr.due_date,
+  hr.id;
+-- name: SelectCalendarPayments :many
+-- unpaid payments with a due date in the houses of the user, without a house only the ones the user pays or requested
+SELECT hp.id,
+  hp.payment_name,
+  COALESCE(hp.amount, 0)::text amount,
+  hp.due_date,
+  hp.updated_at,
+  h.id house_id,
+  h.name house_name
+FROM house_payments hp
+  INNER JOIN houses h ON h.id = hp.house_id
+  INNER JOIN user_houses uh ON uh.house_id = hp.house_id
+  AND uh.user_id = @user_id
+WHERE hp.due_date IS NOT NULL
+  AND (
+    @house_id::uuid IS NULL
+    OR hp.house_id = @house_id::uuid
+  )
+  AND EXISTS (
+    SELECT 1
+    FROM house_payment_payers hpp
+    WHERE hpp.payment_id = hp.id
+      AND hpp.payment_status = 'incomplete'
+      AND (
+        @house_id::uuid IS NOT NULL
+        OR hpp.payer_id = @user_id
+        OR hp.requester_id = @user_id
+      )
+  )
+ORDER BY hp.due_date,
+  hp.id;
+-- name: InsertShoppingItem :one
+INSERT INTO shopping_items (
+    house_id,
+    item_name,
+    quantity,
+    category,
+    added_by
+  )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id;
+-- name: SelectShoppingItems :many
+-- items still on the list of the house
+SELECT si.id,
+  si.item_name,
+  si.quantity,
+  si.category,
+  a.username added_by_username,
+  si.bought_by,
+  b.username bought_by_username,
+  si.updated_at
+FROM shopping_items si
+  LEFT JOIN users a ON a.id = si.added_by
+  LEFT JOIN users b ON b.id = si.bought_by
+WHERE si.house_id = $1
+  AND si.settled_at IS NULL
+ORDER BY si.category,
+  si.id;
+-- name: SetShoppingItemBought :one
+-- an item bought by someone else is not taken over, the list shows who bought it
+UPDATE shopping_items
+SET bought_by = CASE
+    WHEN @bought::boolean THEN @user_id::uuid
+  END,
+  bought_at = CASE
+    WHEN @bought::boolean THEN CURRENT_TIMESTAMP
+  END
+WHERE id = @id
+  AND house_id = @house_id
+  AND settled_at IS NULL
+  AND (
+    bought_by IS NULL
+    OR bought_by = @user_id::uuid
+  )
+RETURNING item_name;
+-- name: DeleteShoppingItem :execrows
+DELETE FROM shopping_items
+WHERE id = $1
+  AND house_id = $2
+  AND settled_at IS NULL;
+-- name: SettleShoppingItems :many
+-- takes the items the user bought off the list
+UPDATE shopping_items
+SET settled_at = CURRENT_TIMESTAMP,
+  payment_id = @payment_id
+WHERE house_id = @house_id
+  AND bought_by = @user_id
+  AND settled_at IS NULL
+  AND id = ANY(@item_ids::bigint [])
+RETURNING item_name;
@@ -0,0 +1,70 @@
+// strong entity tags (RFC 9110) and the conditional request headers using them
+//
+// tags are derived from what a representation is made of, usually the id and
+// updated_at of the rows, so they change whenever the representation does
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const weakPrefix = "W/"
+
+// tag from the parts, the same parts always give the same tag
+func New(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		// separator, ("ab", "c") and ("a", "bc") must not give the same tag
+		h.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// version of a row from its updated_at
+func Version(updatedAt time.Time) string {
+	return strconv.FormatInt(updatedAt.UnixMicro(), 10)
+}
+
+// tags listed in If-Match or If-None-Match
+func parseList(header string) []string {
+	var tags []string
+	for tag := range strings.SplitSeq(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// If-None-Match uses the weak comparison
+//
+//	bool == true -- client already has the representation with the tag
+func NoneMatch(header, tag string) bool {
+	tag = strings.TrimPrefix(tag, weakPrefix)
+	for _, t := range parseList(header) {
+		if t == "*" || strings.TrimPrefix(t, weakPrefix) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// If-Match uses the strong comparison, weak tags never match
+//
+//	bool == true -- client has seen the current representation
+func Match(header, tag string) bool {
+	if strings.HasPrefix(tag, weakPrefix) {
+		return false
+	}
+	for _, t := range parseList(header) {
+		if t == "*" || t == tag {
+			return true
+		}
+	}
+	return false
+}
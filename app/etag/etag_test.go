@@ -0,0 +1,88 @@
+package etag
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tag := New("note", "1")
+	if tag != New("note", "1") {
+		t.Error("the same parts gave different tags")
+	}
+	if tag == New("not", "e1") {
+		t.Error("parts split differently gave the same tag")
+	}
+	if len(tag) != 34 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		t.Errorf("New = %s, want a quoted strong tag", tag)
+	}
+
+	updated := time.Date(2025, 1, 31, 12, 0, 0, 1000, time.UTC)
+	if New("1", Version(updated)) == New("1", Version(updated.Add(time.Microsecond))) {
+		t.Error("a newer updated_at gave the same tag")
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	tag := `"abc"`
+	tests := []struct {
+		name   string
+		header string
+		tag    string
+		want   bool
+	}{
+		{name: "no header", header: "", tag: tag, want: false},
+		{name: "same tag", header: `"abc"`, tag: tag, want: true},
+		{name: "other tag", header: `"abd"`, tag: tag, want: false},
+		{name: "weak header matches weakly", header: `W/"abc"`, tag: tag, want: true},
+		{name: "weak tag matches weakly", header: `"abc"`, tag: `W/"abc"`, want: true},
+		{name: "any", header: "*", tag: tag, want: true},
+		{name: "in a list", header: `"x", W/"y", "abc"`, tag: tag, want: true},
+		{name: "list without spaces", header: `"x","abc"`, tag: tag, want: true},
+		{name: "not in a list", header: `"x", "y"`, tag: tag, want: false},
+		{name: "empty list items", header: ` , ,"abc",`, tag: tag, want: true},
+		{name: "unquoted", header: "abc", tag: tag, want: false},
+		{name: "missing quote", header: `"abc`, tag: tag, want: false},
+		{name: "only the weak prefix", header: "W/", tag: tag, want: false},
+		{name: "lowercase weak prefix", header: `w/"abc"`, tag: tag, want: false},
+		{name: "case matters", header: `"ABC"`, tag: tag, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NoneMatch(tt.header, tt.tag); got != tt.want {
+				t.Errorf("NoneMatch(%q, %q) = %v, want %v", tt.header, tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tag := `"abc"`
+	tests := []struct {
+		name   string
+		header string
+		tag    string
+		want   bool
+	}{
+		{name: "no header", header: "", tag: tag, want: false},
+		{name: "same tag", header: `"abc"`, tag: tag, want: true},
+		{name: "other tag", header: `"abd"`, tag: tag, want: false},
+		{name: "weak header never matches", header: `W/"abc"`, tag: tag, want: false},
+		{name: "weak tag never matches", header: `W/"abc"`, tag: `W/"abc"`, want: false},
+		{name: "weak tag not even with any", header: "*", tag: `W/"abc"`, want: false},
+		{name: "any", header: "*", tag: tag, want: true},
+		{name: "in a list", header: `"x", "abc"`, tag: tag, want: true},
+		{name: "weak in a list", header: `"x", W/"abc"`, tag: tag, want: false},
+		{name: "not in a list", header: `"x", "y"`, tag: tag, want: false},
+		{name: "unquoted", header: "abc", tag: tag, want: false},
+		{name: "missing quote", header: `abc"`, tag: tag, want: false},
+		{name: "garbage", header: `;;;`, tag: tag, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Match(tt.header, tt.tag); got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.header, tt.tag, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"roommates/broker"
+	"sync"
+	"time"
+)
+
+const maxBackoff = 30 * time.Second
+
+// handles a single event, returning an error retries it
+type Handler func(ctx context.Context, message Message) error
+
+// group of handlers consuming events under the same consumer group
+type Consumer struct {
+	// consumer group, has to be unique per consumer
+	Group string
+	// workers per topic, messages from the same partition always go to the same worker
+	Concurrency int
+	// attempts before the message is sent to the dead-letter topic
+	MaxAttempts int
+	// wait before the first retry, doubled on every following retry
+	Backoff time.Duration
+
+	handlers map[Type]Handler
+}
+
+func NewConsumer(group string) *Consumer {
+	return &Consumer{
+		Group:       group,
+		Concurrency: 1,
+		MaxAttempts: 5,
+		Backoff:     500 * time.Millisecond,
+		handlers:    map[Type]Handler{},
+	}
+}
+
+// registers the handler for the event type, replaces the previous one
+func (c *Consumer) On(t Type, handler Handler) *Consumer {
+	c.handlers[t] = handler
+	return c
+}
+
+// topics of all the event types the consumer has handlers for
+func (c *Consumer) topics() []string {
+	seen := map[string]bool{}
+	var topics []string
+	for t := range c.handlers {
+		if topic := t.Topic(); !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// dead-letter topic of the topic, messages that could not be handled end up there
+func DeadLetterTopic(topic string) string {
+	return topic + ".dlt"
+}
+
+// runs consumers against the broker
+type Runner struct {
+	broker    broker.Broker
+	consumers []*Consumer
+}
+
+func NewRunner(b broker.Broker, consumers ...*Consumer) *Runner {
+	return &Runner{
+		broker:    b,
+		consumers: consumers,
+	}
+}
+
+// blocks until ctx is done and every in-flight message has been handled
+//
+// messages that were fetched but not handled are not committed,
+// they will be delivered again on the next start
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, c := range r.consumers {
+		for _, topic := range c.topics() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.consume(ctx, c, topic)
+			}()
+		}
+	}
+	log.Info().Int("consumers", len(r.consumers)).Msg("event consumers started")
+	wg.Wait()
+	log.Info().Msg("event consumers stopped")
+}
+
+func (r *Runner) consume(ctx context.Context, c *Consumer, topic string) {
+	sub := r.broker.Subscribe(topic, c.Group)
+	defer sub.Close()
+
+	var wg sync.WaitGroup
+	workers := make([]chan broker.Message, max(c.Concurrency, 1))
+	for i := range workers {
+		workers[i] = make(chan broker.Message)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for message := range workers[i] {
+				r.handle(ctx, c, sub, message)
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer func() {
+		for _, worker := range workers {
+			close(worker)
+		}
+	}()
+
+	for {
+		message, err := sub.Fetch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Str("topic", topic).Str("group", c.Group).Msg("error fetching message")
+			if !sleep(ctx, c.Backoff) {
+				return
+			}
+			continue
+		}
+
+		select {
+		case workers[message.Partition%len(workers)] <- message:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handles the message with retries and commits it,
+// after the last attempt the message is sent to the dead-letter topic
+//
+// the handler itself is not canceled on shutdown, only the retries are
+func (r *Runner) handle(ctx context.Context, c *Consumer, sub broker.Subscription, message broker.Message) {
+	handlerCtx := context.WithoutCancel(ctx)
+	logger := log.With().
+		Str("topic", message.Topic).
+		Str("group", c.Group).
+		Int("partition", message.Partition).
+		Int64("offset", message.Offset).
+		Logger()
+
+	var event Message
+	err := json.Unmarshal(message.Value, &event)
+	if err == nil {
+		handler, ok := c.handlers[event.Type]
+		if !ok {
+			r.commit(handlerCtx, sub, message)
+			return
+		}
+
+		backoff := c.Backoff
+		for attempt := 1; ; attempt++ {
+			if err = handler(handlerCtx, event); err == nil {
+				r.commit(handlerCtx, sub, message)
+				return
+			}
+			if attempt >= c.MaxAttempts {
+				break
+			}
+
+			logger.Warn().Err(err).Int("attempt", attempt).Msg("error handling event, retrying")
+			if !sleep(ctx, backoff) {
+				// not committed, will be delivered again
+				return
+			}
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}
+
+	logger.Error().Err(err).Bytes("value", message.Value).Msg("giving up on message, sending to dead-letter topic")
+	err = r.broker.Publish(handlerCtx, broker.Message{
+		Topic: DeadLetterTopic(message.Topic),
+		Key:   message.Key,
+		Value: message.Value,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("error publishing to dead-letter topic")
+	}
+	r.commit(handlerCtx, sub, message)
+}
+
+func (r *Runner) commit(ctx context.Context, sub broker.Subscription, message broker.Message) {
+	if err := sub.Commit(ctx, message); err != nil {
+		log.Error().Err(err).
+			Str("topic", message.Topic).
+			Int("partition", message.Partition).
+			Int64("offset", message.Offset).
+			Msg("error committing message")
+	}
+}
+
+// returns false when ctx was done before the duration passed
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,183 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"roommates/broker"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testGroup = "test"
+
+// runs the consumer until the test ends
+func runConsumer(t *testing.T, b broker.Broker, c *Consumer) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewRunner(b, c).Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func publishEvent(t *testing.T, b broker.Broker, message Message) {
+	t.Helper()
+	value, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.Publish(context.Background(), broker.Message{Topic: message.Type.Topic(), Value: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waits for the condition, fails the test after a second
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// handler which fails the first failures calls, remembers when it was called
+type flakyHandler struct {
+	mu       sync.Mutex
+	failures int
+	calls    []time.Time
+}
+
+func (h *flakyHandler) handle(ctx context.Context, message Message) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = append(h.calls, time.Now())
+	if len(h.calls) <= h.failures {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func (h *flakyHandler) callTimes() []time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]time.Time(nil), h.calls...)
+}
+
+func TestRunnerRetriesWithBackoff(t *testing.T) {
+	b := broker.NewMemory(1)
+	handler := &flakyHandler{failures: 2}
+	c := NewConsumer(testGroup).On(NoteCreated, handler.handle)
+	c.Backoff = 20 * time.Millisecond
+	c.MaxAttempts = 5
+	runConsumer(t, b, c)
+
+	publishEvent(t, b, Message{ID: 1, Type: NoteCreated})
+	topic := NoteCreated.Topic()
+	eventually(t, "commit", func() bool { return b.Lag(topic, testGroup) == 0 })
+
+	calls := handler.callTimes()
+	if len(calls) != 3 {
+		t.Fatalf("handler called %d times, want 3", len(calls))
+	}
+	// the wait is doubled after every failure
+	if gap := calls[1].Sub(calls[0]); gap < c.Backoff {
+		t.Errorf("first retry after %v, want at least %v", gap, c.Backoff)
+	}
+	if gap := calls[2].Sub(calls[1]); gap < 2*c.Backoff {
+		t.Errorf("second retry after %v, want at least %v", gap, 2*c.Backoff)
+	}
+	if dead := b.Messages(DeadLetterTopic(topic)); len(dead) != 0 {
+		t.Errorf("%d messages in the dead-letter topic, want 0", len(dead))
+	}
+}
+
+func TestRunnerSendsToDeadLetterTopic(t *testing.T) {
+	b := broker.NewMemory(1)
+	handler := &flakyHandler{failures: 100}
+	c := NewConsumer(testGroup).On(NoteCreated, handler.handle)
+	c.Backoff = time.Millisecond
+	c.MaxAttempts = 3
+	runConsumer(t, b, c)
+
+	publishEvent(t, b, Message{ID: 7, Type: NoteCreated})
+	topic := NoteCreated.Topic()
+	eventually(t, "dead-letter message", func() bool { return len(b.Messages(DeadLetterTopic(topic))) == 1 })
+	eventually(t, "commit", func() bool { return b.Lag(topic, testGroup) == 0 })
+
+	if calls := len(handler.callTimes()); calls != c.MaxAttempts {
+		t.Errorf("handler called %d times, want %d", calls, c.MaxAttempts)
+	}
+	var dead Message
+	if err := json.Unmarshal(b.Messages(DeadLetterTopic(topic))[0].Value, &dead); err != nil || dead.ID != 7 {
+		t.Errorf("dead-letter message is not the original one: %+v, %v", dead, err)
+	}
+}
+
+func TestRunnerDeadLettersMalformedMessages(t *testing.T) {
+	b := broker.NewMemory(1)
+	handler := &flakyHandler{}
+	c := NewConsumer(testGroup).On(NoteCreated, handler.handle)
+	runConsumer(t, b, c)
+
+	topic := NoteCreated.Topic()
+	b.Publish(context.Background(), broker.Message{Topic: topic, Value: []byte("{not json")})
+	eventually(t, "dead-letter message", func() bool { return len(b.Messages(DeadLetterTopic(topic))) == 1 })
+
+	if calls := len(handler.callTimes()); calls != 0 {
+		t.Errorf("handler called %d times for a malformed message", calls)
+	}
+}
+
+func TestRunnerSkipsTypesWithoutHandler(t *testing.T) {
+	b := broker.NewMemory(1)
+	handler := &flakyHandler{}
+	c := NewConsumer(testGroup).On(NoteCreated, handler.handle)
+	runConsumer(t, b, c)
+
+	// same topic, no handler
+	publishEvent(t, b, Message{ID: 1, Type: NoteDeleted})
+	topic := NoteDeleted.Topic()
+	eventually(t, "commit", func() bool { return b.Lag(topic, testGroup) == 0 })
+
+	if calls := len(handler.callTimes()); calls != 0 {
+		t.Errorf("handler called %d times", calls)
+	}
+	if dead := b.Messages(DeadLetterTopic(topic)); len(dead) != 0 {
+		t.Errorf("%d messages in the dead-letter topic, want 0", len(dead))
+	}
+}
+
+// a message still being retried on shutdown is delivered again on the next start
+func TestRunnerDoesNotCommitOnShutdown(t *testing.T) {
+	b := broker.NewMemory(1)
+	handler := &flakyHandler{failures: 100}
+	c := NewConsumer(testGroup).On(NoteCreated, handler.handle)
+	c.Backoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewRunner(b, c).Run(ctx)
+		close(done)
+	}()
+
+	publishEvent(t, b, Message{ID: 1, Type: NoteCreated})
+	eventually(t, "first attempt", func() bool { return len(handler.callTimes()) == 1 })
+	cancel()
+	<-done
+
+	if lag := b.Lag(NoteCreated.Topic(), testGroup); lag != 1 {
+		t.Errorf("lag = %d after shutdown, want 1", lag)
+	}
+}
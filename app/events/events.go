@@ -0,0 +1,140 @@
+// things that happen inside of a house, recorded for the activity feed
+// and published to the broker through the outbox
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"roommates/db/dbqueries"
+	"roommates/logger"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var log = logger.EventsLoggger
+
+// Type of the event, stored as text in the database
+type Type string
+
+const (
+	MemberAdded       Type = "member-added"
+	MemberRemoved     Type = "member-removed"
+	MemberDeleted     Type = "member-deleted" // account was deleted, the actor is gone by the time it is read
+	MessageMentioned  Type = "message-mentioned"
+	NoteCreated       Type = "note-created"
+	NoteEdited        Type = "note-edited"
+	NoteDeleted       Type = "note-deleted"
+	PaymentCreated    Type = "payment-created"
+	PaymentSettled    Type = "payment-settled"
+	ReminderCompleted Type = "reminder-completed"
+	ReminderDue       Type = "reminder-due" // recorded by the app when the due date comes, there is no actor
+	ShoppingAdded     Type = "shopping-added"
+	ShoppingBought    Type = "shopping-bought"
+	ShoppingSettled   Type = "shopping-settled"
+)
+
+var AllTypes = []Type{
+	MemberAdded,
+	MemberRemoved,
+	MemberDeleted,
+	MessageMentioned,
+	NoteCreated,
+	NoteEdited,
+	NoteDeleted,
+	PaymentCreated,
+	PaymentSettled,
+	ReminderCompleted,
+	ReminderDue,
+	ShoppingAdded,
+	ShoppingBought,
+	ShoppingSettled,
+}
+
+// topic is decided by the first part of the type, "note-created" -> "house.note"
+func (t Type) Topic() string {
+	kind, _, _ := strings.Cut(string(t), "-")
+	return "house." + kind
+}
+
+// stored as JSON alongside the event
+//
+// subject is copied at the time of the event so that the feed still makes sense
+// after the thing the event was about has been deleted or renamed
+type Payload struct {
+	// id of the note, payment, reminder, shopping item or user the event is about,
+	// for mentions the user who was mentioned
+	SubjectID string `json:"subject_id,omitempty"`
+	// human readable name of the subject
+	Subject string `json:"subject,omitempty"`
+	// only used by payment events
+	Amount string `json:"amount,omitempty"`
+}
+
+type Event struct {
+	Type    Type
+	HouseID pgtype.UUID
+	// user who caused the event
+	ActorID pgtype.UUID
+	Payload Payload
+}
+
+// what gets published to the broker
+type Message struct {
+	// id of the house event, consumers can use it for deduplication
+	ID        int64       `json:"id"`
+	Type      Type        `json:"type"`
+	HouseID   pgtype.UUID `json:"house_id"`
+	ActorID   pgtype.UUID `json:"actor_id"`
+	Payload   Payload     `json:"payload"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// records the event into the house activity feed and the outbox
+//
+// meant to be used with the same transaction (dbqueries.Queries.WithTx) as the change itself,
+// that way the event is published only when the change is committed
+func Record(ctx context.Context, q *dbqueries.Queries, e Event) error {
+	payload, err := json.Marshal(e.Payload)
+	if err != nil {
+		return err
+	}
+
+	row, err := q.InsertHouseEvent(ctx, dbqueries.InsertHouseEventParams{
+		EventType: string(e.Type),
+		Payload:   payload,
+		HouseID:   e.HouseID,
+		ActorID:   e.ActorID,
+	})
+	if err != nil {
+		return err
+	}
+
+	message, err := json.Marshal(Message{
+		ID:        row.ID,
+		Type:      e.Type,
+		HouseID:   e.HouseID,
+		ActorID:   e.ActorID,
+		Payload:   e.Payload,
+		CreatedAt: row.CreatedAt.Time,
+	})
+	if err != nil {
+		return err
+	}
+
+	return q.InsertOutboxMessage(ctx, dbqueries.InsertOutboxMessageParams{
+		Topic:      e.Type.Topic(),
+		MessageKey: e.HouseID.String(),
+		Payload:    message,
+	})
+}
+
+// will only log the error, payload of the event is not important enough to fail on
+func ParsePayload(data []byte) Payload {
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Error().Err(err).Caller().Bytes("payload", data).Msg("error parsing event payload")
+	}
+	return payload
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"roommates/broker"
+	"roommates/db/dbqueries"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	relayBatchSize = 100
+	relayInterval  = time.Second
+)
+
+// publishes outbox rows to the broker
+//
+// rows are deleted only after the broker has acknowledged them,
+// so a message can be published more than once (at-least-once delivery)
+type Relay struct {
+	pool   *pgxpool.Pool
+	db     *dbqueries.Queries
+	broker broker.Broker
+}
+
+func NewRelay(pool *pgxpool.Pool, b broker.Broker) *Relay {
+	return &Relay{
+		pool:   pool,
+		db:     dbqueries.New(pool),
+		broker: b,
+	}
+}
+
+// blocks until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	log.Info().Msg("outbox relay started")
+	ticker := time.NewTicker(relayInterval)
+	defer ticker.Stop()
+
+	for {
+		// keep going while there are full batches, otherwise wait for the next tick
+		published, err := r.PublishBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("error publishing outbox batch")
+		}
+		if published == relayBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishes a single batch, returns the amount of published messages
+//
+// rows stay locked (SKIP LOCKED) until the transaction ends,
+// which lets multiple app instances run the relay at the same time
+func (r *Relay) PublishBatch(ctx context.Context) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.db.WithTx(tx)
+
+	rows, err := qtx.SelectOutboxBatch(ctx, relayBatchSize)
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	messages := make([]broker.Message, len(rows))
+	ids := make([]int64, len(rows))
+	for i, row := range rows {
+		messages[i] = broker.Message{
+			Topic: row.Topic,
+			Key:   []byte(row.MessageKey),
+			Value: row.Payload,
+		}
+		ids[i] = row.ID
+	}
+
+	if err = r.broker.Publish(ctx, messages...); err != nil {
+		return 0, err
+	}
+	if err = qtx.DeleteOutboxMessages(ctx, ids); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
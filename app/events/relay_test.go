@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"roommates/broker"
+	"roommates/db/dbqueries"
+	"roommates/db/dbtest"
+	"testing"
+)
+
+// broker which refuses every publish
+type failingBroker struct {
+	broker.Broker
+}
+
+func (failingBroker) Publish(ctx context.Context, messages ...broker.Message) error {
+	return errors.New("broker is down")
+}
+
+func insertOutbox(t *testing.T, db *dbqueries.Queries, count int) {
+	t.Helper()
+	for i := range count {
+		err := db.InsertOutboxMessage(context.Background(), dbqueries.InsertOutboxMessageParams{
+			Topic:      "house.note",
+			MessageKey: fmt.Sprintf("key-%d", i),
+			Payload:    fmt.Appendf(nil, `{"id":%d}`, i),
+		})
+		if err != nil {
+			t.Fatalf("could not insert outbox message: %v", err)
+		}
+	}
+}
+
+func outboxCount(t *testing.T, relay *Relay) int {
+	t.Helper()
+	var count int
+	if err := relay.pool.QueryRow(context.Background(), "SELECT count(*) FROM outbox").Scan(&count); err != nil {
+		t.Fatalf("could not count outbox: %v", err)
+	}
+	return count
+}
+
+func TestRelayPublishesAndDeletes(t *testing.T) {
+	ctx := context.Background()
+	b := broker.NewMemory(1)
+	relay := NewRelay(dbtest.Pool(t), b)
+	insertOutbox(t, relay.db, 3)
+
+	published, err := relay.PublishBatch(ctx)
+	if err != nil {
+		t.Fatalf("PublishBatch: %v", err)
+	}
+	if published != 3 {
+		t.Fatalf("published %d messages, want 3", published)
+	}
+
+	messages := b.Messages("house.note")
+	if len(messages) != 3 {
+		t.Fatalf("broker has %d messages, want 3", len(messages))
+	}
+	for i, message := range messages {
+		if key := fmt.Sprintf("key-%d", i); string(message.Key) != key {
+			t.Errorf("message %d has key %q, want %q", i, message.Key, key)
+		}
+	}
+	if count := outboxCount(t, relay); count != 0 {
+		t.Errorf("outbox has %d rows after publishing, want 0", count)
+	}
+
+	published, err = relay.PublishBatch(ctx)
+	if err != nil || published != 0 {
+		t.Errorf("second PublishBatch = %d, %v, want 0, nil", published, err)
+	}
+}
+
+func TestRelayKeepsRowsWhenPublishFails(t *testing.T) {
+	relay := NewRelay(dbtest.Pool(t), failingBroker{})
+	insertOutbox(t, relay.db, 2)
+
+	if _, err := relay.PublishBatch(context.Background()); err == nil {
+		t.Fatal("PublishBatch succeeded with a failing broker")
+	}
+	if count := outboxCount(t, relay); count != 2 {
+		t.Errorf("outbox has %d rows, want 2", count)
+	}
+}
+
+// rows locked by another relay are left for it, the rest are published
+func TestRelaySkipsLockedRows(t *testing.T) {
+	ctx := context.Background()
+	b := broker.NewMemory(1)
+	relay := NewRelay(dbtest.Pool(t), b)
+	insertOutbox(t, relay.db, 3)
+
+	// another instance in the middle of publishing the first row
+	tx, err := relay.pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("could not begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	locked, err := relay.db.WithTx(tx).SelectOutboxBatch(ctx, 1)
+	if err != nil || len(locked) != 1 {
+		t.Fatalf("could not lock a row: %v", err)
+	}
+
+	published, err := relay.PublishBatch(ctx)
+	if err != nil {
+		t.Fatalf("PublishBatch: %v", err)
+	}
+	if published != 2 {
+		t.Fatalf("published %d messages while a row was locked, want 2", published)
+	}
+	for _, message := range b.Messages("house.note") {
+		if string(message.Key) == locked[0].MessageKey {
+			t.Errorf("locked row %q was published", locked[0].MessageKey)
+		}
+	}
+
+	// the other instance gave up, the row is published by the next batch
+	if err = tx.Rollback(ctx); err != nil {
+		t.Fatalf("could not roll back: %v", err)
+	}
+	published, err = relay.PublishBatch(ctx)
+	if err != nil || published != 1 {
+		t.Errorf("PublishBatch after unlock = %d, %v, want 1, nil", published, err)
+	}
+	if count := outboxCount(t, relay); count != 0 {
+		t.Errorf("outbox has %d rows, want 0", count)
+	}
+}
@@ -7,6 +7,10 @@ type CookieKey string
 
 const (
 	CSessionToken CookieKey = "session_token"
+	// sign-in waiting for the two-factor code
+	CTwoFactorToken CookieKey = "two_factor_token"
+	// state of a sign-in with an OpenID Connect provider, ties the callback to the browser
+	COIDCState CookieKey = "oidc_state"
 )
 
 // Key for http header
@@ -16,9 +20,28 @@ type HttpHeader string
 // if there becomes a need to have more, could be nice to copy from
 // https://pkg.go.dev/github.com/go-http-utils/headers#pkg-constants
 const (
-	HAuthorization HttpHeader = "Authorization"
-	HHXRequest     HttpHeader = "HX-Request"
-	HHXRedirect    HttpHeader = "HX-Redirect"
+	HAuthorization      HttpHeader = "Authorization"
+	HCacheControl       HttpHeader = "Cache-Control"
+	HContentDisposition HttpHeader = "Content-Disposition"
+	HContentType        HttpHeader = "Content-Type"
+	HETag               HttpHeader = "ETag"
+	HHXRequest          HttpHeader = "HX-Request"
+	HHXRedirect         HttpHeader = "HX-Redirect"
+	HHXTrigger          HttpHeader = "HX-Trigger"
+	HIdempotencyKey     HttpHeader = "Idempotency-Key"
+	HIdempotentReplayed HttpHeader = "Idempotent-Replayed"
+	HIfMatch            HttpHeader = "If-Match"
+	HIfNoneMatch        HttpHeader = "If-None-Match"
+	HRetryAfter         HttpHeader = "Retry-After"
+	HXCSRFToken         HttpHeader = "X-CSRF-Token"
+)
+
+// htmx events triggered with HHXTrigger
+const (
+	// unread count of notifications might have changed
+	EvNotificationsChanged = "notifications-changed"
+	// items were added to or taken off the shopping list
+	EvShoppingChanged = "shopping-changed"
 )
 
 const Csrf = "_csrf"
@@ -34,34 +57,125 @@ const (
 
 // constants for routes, see routes.go
 const (
-	RHouses    = "/houses"
-	RLogin     = "/login"
-	RMessaging = "/messaging"
-	RNotes     = "/notes"
-	RPayments  = "/payments"
-	RProfile   = "/profile"
-	RRegister  = "/register"
-	RUser      = "/user"
-
-	RHouseID = RHouses + "/:id"
-	RUserID  = RUser + "/:id"
-	RNoteID  = RNotes + "/:id"
+	RForgotPassword = "/forgot-password"
+	RHouses         = "/houses"
+	RLogin          = "/login"
+	RMessaging      = "/messaging"
+	RNotes          = "/notes"
+	RNotifications  = "/notifications"
+	RPayments       = "/payments"
+	RProfile        = "/profile"
+	RRegister       = "/register"
+	RResetPassword  = "/reset-password/:token"
+	RReminders      = "/reminders"
+	RUnsubscribe    = "/unsubscribe/:token"
+	RVerifyEmail    = "/verify-email"
+	RUser           = "/user"
+	RImage          = "/images/:key/:size"
+	RCalendar       = "/calendar"
+
+	RLoginTwoFactor    = RLogin + "/two-factor"
+	RLoginOIDC         = RLogin + "/oidc/:provider"
+	RLoginOIDCCallback = RLoginOIDC + "/callback"
+
+	RHouseID    = RHouses + "/:id"
+	RUserID     = RUser + "/:id"
+	RNoteID     = RNotes + "/:id"
+	RPaymentID  = RPayments + "/:id"
+	RReminderID = RReminders + "/:id"
+
+	RConversationID = RMessaging + "/:id"
+
+	RNotificationID = RNotifications + "/:id"
+
+	RVerifyEmailToken = RVerifyEmail + "/:token"
+
+	// the token may end with .ics, some calendar apps want it
+	RCalendarFeed = RCalendar + "/:token"
 
 	RHxRoomateSearch = RHouses + "/roomate-search"
 	RHxHouseForm     = RHouses + "/house-form"
+	RHxHousesList    = RHouses + "/list"
+	RHxPaymentsList  = RPayments + "/list"
 
 	RHxHouseResidentsBadge = RHouseID + "/residents-badge"
+	RHxHouseActivity       = RHouseID + "/activity"
 	RHxNoteForm            = RHouseID + "/note-form"
+	RHxPaymentForm         = RHouseID + "/payment-form"
+	RHxReminderForm        = RHouseID + "/reminder-form"
+	RHxHousePicture        = RHouseID + "/picture"
+	RHxHouseNotes          = RHouseID + "/notes"
+
+	RHxShopping           = RHouseID + "/shopping"
+	RHxShoppingItemID     = RHxShopping + "/:item_id"
+	RHxShoppingItemBought = RHxShoppingItemID + "/bought"
+	RHxShoppingPurchase   = RHxShopping + "/purchase"
+
+	RHxHouseWebhooks          = RHouseID + "/webhooks"
+	RHxHouseWebhookID         = RHxHouseWebhooks + "/:webhook_id"
+	RHxHouseWebhookEnable     = RHxHouseWebhookID + "/enable"
+	RHxHouseWebhookDeliveries = RHxHouseWebhookID + "/deliveries"
+
+	RHxConversationImage = RConversationID + "/image"
+
+	RHxUserBlock = RUserID + "/block"
+
+	RHxPaymentSettle    = RPaymentID + "/settle"
+	RHxReminderComplete = RReminderID + "/complete"
+
+	RHxNotificationsBell        = RNotifications + "/bell"
+	RHxNotificationsReadAll     = RNotifications + "/read-all"
+	RHxNotificationsPreferences = RNotifications + "/preferences"
+	RHxNotificationRead         = RNotificationID + "/read"
+
+	RHxProfileDetails   = RProfile + "/details"
+	RHxProfileAvatar    = RProfile + "/avatar"
+	RHxProfilePassword  = RProfile + "/password"
+	RHxProfileSessions  = RProfile + "/sessions"
+	RHxProfileSessionID = RHxProfileSessions + "/:id"
+	RHxTwoFactor        = RProfile + "/two-factor"
+	RHxTwoFactorConfirm = RHxTwoFactor + "/confirm"
+	RHxTwoFactorDisable = RHxTwoFactor + "/disable"
+	RHxAccessTokens     = RProfile + "/access-tokens"
+	RHxAccessTokenID    = RHxAccessTokens + "/:id"
+	RHxCalendarFeeds    = RProfile + "/calendar-feeds"
+	RHxCalendarFeedID   = RHxCalendarFeeds + "/:id"
+	RHxIdentities       = RProfile + "/identities"
+	RHxIdentity         = RHxIdentities + "/:provider"
+	RIdentityConnect    = RHxIdentity + "/connect"
+	RHxAccountDeletion  = RProfile + "/deletion"
+	RAccountDeletion    = RHxAccountDeletion + "/:token"
+	RProfileExport      = RProfile + "/export"
+	RHxProfilePrivacy   = RProfile + "/privacy"
+	RHxProfileBlocks    = RProfile + "/blocks"
+	RHxProfileBlockID   = RHxProfileBlocks + "/:id"
 
-	RHxNoteInHouseAccordion = RNoteID + "/view-house-accordion"
+	RHxVerifyEmailBanner = RVerifyEmail + "/banner"
+	RHxVerifyEmailResend = RVerifyEmail + "/resend"
 )
 
 // -----------------------------------------------------------------------------
 
 var (
-	ErrorInvalidCredential    = errors.New("invalid credentials")
-	ErrorAccountAlreadyExists = errors.New("account already exists")
-	ErrorHxRequired           = errors.New("htmx required")
-	ErrorNotAllowedToModify   = errors.New("not allowed to modify")
-	ErrorInvalidID            = errors.New("invalid id")
+	ErrorInvalidCredential        = errors.New("invalid credentials")
+	ErrorAccountAlreadyExists     = errors.New("account already exists")
+	ErrorHxRequired               = errors.New("htmx required")
+	ErrorNotAllowedToModify       = errors.New("not allowed to modify")
+	ErrorInvalidID                = errors.New("invalid id")
+	ErrorNotHouseResident         = errors.New("not a resident of the house")
+	ErrorNotConversationRecipient = errors.New("not a recipient of the conversation")
+	ErrorEmailNotVerified         = errors.New("email not verified")
+	ErrorWrongPassword            = errors.New("wrong password")
+	ErrorLockedOut                = errors.New("too many failed attempts")
+	ErrorInvalidTwoFactorCode     = errors.New("invalid two-factor code")
+	ErrorIdentityTaken            = errors.New("account is already connected to another user")
+	ErrorLinkUnverified           = errors.New("user with the email has not verified it")
+	ErrorUserNotFound             = errors.New("user not found")
+	ErrorNotFound                 = errors.New("not found")
+	ErrorUserBlocked              = errors.New("user does not allow being added by you")
+	ErrorPreconditionFailed       = errors.New("resource has changed since it was fetched")
+	ErrorPayerNotResident         = errors.New("payers have to live in the house")
+	ErrorInvalidIdempotencyKey    = errors.New("invalid idempotency key")
+	ErrorIdempotencyKeyReused     = errors.New("idempotency key was used for a different request")
+	ErrorIdempotencyKeyInProgress = errors.New("request with the idempotency key is still in progress")
 )
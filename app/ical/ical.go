@@ -0,0 +1,127 @@
+// iCalendar (RFC 5545) files for calendar apps, only the parts the calendar feeds need:
+// whole day events which may repeat
+package ical
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+	// longest line in octets without the line break, longer ones are folded
+	maxLineLength = 75
+)
+
+// how often the event repeats, RRULE FREQ
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+type Event struct {
+	// stays the same for the event across downloads of the calendar
+	UID string
+	// day of the event, the time of the day is ignored
+	Date    time.Time
+	Summary string
+	// optional
+	Description string
+	// optional, absolute
+	URL string
+	// "" when the event does not repeat
+	Repeat Frequency
+	// last change of the event
+	Modified time.Time
+}
+
+type Calendar struct {
+	// who made the calendar, "-//Roommates//Calendar//ET"
+	ProductID string
+	// shown by calendar apps which support X-WR-CALNAME
+	Name   string
+	Events []Event
+}
+
+// writes the calendar with CRLF line breaks and folded lines
+func (c Calendar) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	line := func(name, value string) {
+		fold(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", c.ProductID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", Escape(c.Name))
+	}
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", Escape(e.UID))
+		line("DTSTAMP", e.Modified.UTC().Format(dateTimeLayout))
+		line("LAST-MODIFIED", e.Modified.UTC().Format(dateTimeLayout))
+		line("DTSTART;VALUE=DATE", e.Date.Format(dateLayout))
+		line("DTEND;VALUE=DATE", e.Date.AddDate(0, 0, 1).Format(dateLayout))
+		if e.Repeat != "" {
+			line("RRULE", "FREQ="+string(e.Repeat))
+		}
+		line("SUMMARY", Escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", Escape(e.Description))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		// whole day events do not block the day in the calendar
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	return b.WriteTo(w)
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// text value with the special characters escaped
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
+
+// writes the content line, lines longer than 75 octets continue on the next line after a space.
+// lines are only broken between runes so multi-byte characters stay whole
+func fold(b *bytes.Buffer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// the space in front of the continuation counts into the length
+		limit = maxLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
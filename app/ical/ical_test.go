@@ -0,0 +1,161 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// content lines of the output with the folding undone (RFC 5545 3.1)
+func unfold(t *testing.T, out string) []string {
+	t.Helper()
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Fatalf("output does not end with CRLF: %q", out)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if rest, ok := strings.CutPrefix(line, " "); ok && len(lines) > 0 {
+			lines[len(lines)-1] += rest
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "short", line: "SUMMARY:Prügi välja"},
+		{name: "exactly 75 octets", line: "SUMMARY:" + strings.Repeat("a", 67)},
+		{name: "76 octets", line: "SUMMARY:" + strings.Repeat("a", 68)},
+		{name: "long ascii", line: "DESCRIPTION:" + strings.Repeat("abcdefghij", 30)},
+		// ä is 2 octets, the rune would be split at octet 75 and at every later break
+		{name: "two-octet runes", line: "SUMMARY:" + strings.Repeat("ä", 100)},
+		{name: "two-octet runes shifted by one", line: "SUMMARY:x" + strings.Repeat("ä", 100)},
+		{name: "three-octet runes", line: "SUMMARY:" + strings.Repeat("€", 60)},
+		{name: "four-octet runes", line: "SUMMARY:" + strings.Repeat("🧹", 50)},
+		{name: "mixed runes", line: "SUMMARY:" + strings.Repeat("a€🧹ä", 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			fold(&b, tt.line)
+			out := b.String()
+
+			for i, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+				if len(line) > maxLineLength {
+					t.Errorf("line %d is %d octets, want at most %d", i, len(line), maxLineLength)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a rune: %q", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+			}
+			if got := unfold(t, out); len(got) != 1 || got[0] != tt.line {
+				t.Errorf("unfolded = %q, want %q", got, tt.line)
+			}
+			if len(tt.line) <= maxLineLength && out != tt.line+"\r\n" {
+				t.Errorf("short line was folded: %q", out)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Prügi", "Prügi"},
+		{"milk, bread", `milk\, bread`},
+		{"a;b", `a\;b`},
+		{`C:\temp`, `C:\\temp`},
+		{"first\nsecond", `first\nsecond`},
+		{"first\r\nsecond", `first\nsecond`},
+		{"first\rsecond", `first\nsecond`},
+		// escaped once, the backslashes added for the others are not escaped again
+		{`\,;` + "\n", `\\\,\;\n`},
+		// colons and quotes need no escaping in text values
+		{`time: "now"`, `time: "now"`},
+	}
+	for _, tt := range tests {
+		if got := Escape(tt.in); got != tt.want {
+			t.Errorf("Escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarWriteTo(t *testing.T) {
+	modified := time.Date(2025, 1, 31, 12, 30, 0, 0, time.FixedZone("EET", 2*60*60))
+	calendar := Calendar{
+		ProductID: "-//Roommates//Calendar//ET",
+		Name:      "Kodu; köök",
+		Events: []Event{{
+			UID:         "reminder-1@roommates",
+			Date:        time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+			Summary:     "Prügi, paber",
+			Description: "Kodu\nköök",
+			URL:         "https://roommates.test/houses/1",
+			Repeat:      Weekly,
+			Modified:    modified,
+		}, {
+			UID:      "payment-2@roommates",
+			Date:     time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			Summary:  "Internet",
+			Modified: modified,
+		}},
+	}
+
+	var b bytes.Buffer
+	if _, err := calendar.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(strings.ReplaceAll(b.String(), "\r\n", ""), "\n") {
+		t.Error("output has a line break without CR")
+	}
+	got := unfold(t, b.String())
+	want := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Roommates//Calendar//ET",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		`X-WR-CALNAME:Kodu\; köök`,
+		"BEGIN:VEVENT",
+		"UID:reminder-1@roommates",
+		"DTSTAMP:20250131T103000Z",
+		"LAST-MODIFIED:20250131T103000Z",
+		"DTSTART;VALUE=DATE:20250228",
+		"DTEND;VALUE=DATE:20250301",
+		"RRULE:FREQ=WEEKLY",
+		`SUMMARY:Prügi\, paber`,
+		`DESCRIPTION:Kodu\nköök`,
+		"URL:https://roommates.test/houses/1",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:payment-2@roommates",
+		"DTSTAMP:20250131T103000Z",
+		"LAST-MODIFIED:20250131T103000Z",
+		"DTSTART;VALUE=DATE:20251231",
+		"DTEND;VALUE=DATE:20260101",
+		"SUMMARY:Internet",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package images
+
+import (
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"roommates/logger"
+	"roommates/utils"
+)
+
+var log = logger.ImagesLoggger
+
+// keeps the images as files in a directory, `<key>-<size>.jpg`
+//
+// a new upload always gets a new key, the files never change and can be cached forever
+type Store struct {
+	dir string
+}
+
+// uses IMAGES_DIR, ./data/images by default
+func New() *Store {
+	return NewStore(utils.GetEnv("IMAGES_DIR", "./data/images"))
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// keys come from rand.Text, anything else is refused before touching the file system
+func IsKey(key string) bool {
+	if len(key) != 26 {
+		return false
+	}
+	for _, r := range key {
+		if (r < 'A' || r > 'Z') && (r < '2' || r > '7') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Store) path(key string, size Size) string {
+	return filepath.Join(s.dir, key+"-"+string(size)+".jpg")
+}
+
+// writes every size of the image, returns the key the image is found by
+func (s *Store) Save(variants map[Size][]byte) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", err
+	}
+
+	key := rand.Text()
+	for size, data := range variants {
+		// written next to the final file and renamed, a half written image is never served
+		tmp, err := os.CreateTemp(s.dir, ".upload-*")
+		if err != nil {
+			s.Delete(key)
+			return "", err
+		}
+		_, err = tmp.Write(data)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), s.path(key, size))
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			s.Delete(key)
+			return "", err
+		}
+	}
+	return key, nil
+}
+
+// path of the file, false when the key or size is not valid
+//
+// the file itself might not exist
+func (s *Store) Path(key, size string) (string, bool) {
+	if !IsKey(key) || !IsSize(size) {
+		return "", false
+	}
+	return s.path(key, Size(size)), true
+}
+
+// removes every size of the image, errors are only logged as a leftover file harms nobody
+func (s *Store) Delete(key string) {
+	if !IsKey(key) {
+		return
+	}
+	for _, size := range []Size{SizeThumbnail, SizeFull} {
+		if err := os.Remove(s.path(key, size)); err != nil && !os.IsNotExist(err) {
+			log.Error().Err(err).Str("key", key).Msg("could not delete image")
+		}
+	}
+}
+
+// same as Delete but for the nullable key columns
+func (s *Store) DeleteKey(key *string) {
+	if key != nil && *key != "" {
+		s.Delete(*key)
+	}
+}
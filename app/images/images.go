@@ -0,0 +1,150 @@
+// validation and processing of uploaded images, files are kept outside of the database in a Store
+//
+// every upload is decoded and encoded again as JPEG, so nothing but the pixels survives,
+// EXIF (location, camera) included
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// largest accepted upload in bytes
+const MaxUploadSize = 10 << 20
+
+// decoding allocates by the size in the header, bigger images are refused before that
+const maxPixels = 40_000_000
+
+const jpegQuality = 85
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image is too large")
+)
+
+// variant of an image
+type Size string
+
+const (
+	SizeThumbnail Size = "thumb"
+	SizeFull      Size = "full"
+)
+
+func IsSize(s string) bool {
+	return s == string(SizeThumbnail) || s == string(SizeFull)
+}
+
+// what the image is used for, decides how it is resized
+type Kind struct {
+	// longest side in pixels of every size
+	Sizes map[Size]int
+	// cropped to a square from the center
+	Square bool
+}
+
+var (
+	Avatar = Kind{
+		Sizes:  map[Size]int{SizeThumbnail: 64, SizeFull: 256},
+		Square: true,
+	}
+	HousePicture = Kind{
+		Sizes: map[Size]int{SizeThumbnail: 320, SizeFull: 1280},
+	}
+	ConversationImage = Kind{
+		Sizes:  map[Size]int{SizeThumbnail: 64, SizeFull: 256},
+		Square: true,
+	}
+)
+
+// https://en.wikipedia.org/wiki/List_of_file_signatures
+func isSupported(data []byte) bool {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return true
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return true
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return true
+	case len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return true
+	}
+	return false
+}
+
+// validates the upload by its magic bytes and makes JPEGs of every size of the kind
+//
+// animated GIFs keep only their first frame
+func Process(r io.Reader, kind Kind) (map[Size][]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxUploadSize {
+		return nil, ErrTooLarge
+	}
+	if !isSupported(data) {
+		return nil, ErrUnsupportedFormat
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	orientation := exifOrientation(data)
+
+	variants := make(map[Size][]byte, len(kind.Sizes))
+	for size, longest := range kind.Sizes {
+		img := orient(resize(src, longest, kind.Square), orientation)
+
+		var buf bytes.Buffer
+		if err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		variants[size] = buf.Bytes()
+	}
+	return variants, nil
+}
+
+// fits the image into `longest` x `longest`, smaller images are not enlarged
+//
+// transparency is drawn over white as JPEG has none
+func resize(src image.Image, longest int, square bool) image.Image {
+	srcRect := src.Bounds()
+	if square {
+		side := min(srcRect.Dx(), srcRect.Dy())
+		x := srcRect.Min.X + (srcRect.Dx()-side)/2
+		y := srcRect.Min.Y + (srcRect.Dy()-side)/2
+		srcRect = image.Rect(x, y, x+side, y+side)
+	}
+
+	w, h := srcRect.Dx(), srcRect.Dy()
+	if w > longest || h > longest {
+		if w >= h {
+			w, h = longest, max(1, h*longest/w)
+		} else {
+			w, h = max(1, w*longest/h), longest
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, draw.Over, nil)
+	return dst
+}
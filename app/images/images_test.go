@@ -0,0 +1,335 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// JPEG of w x h with the left half black and the right half white
+func halfBlackJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			if x >= w/2 {
+				img.SetGray(x, y, color.Gray{Y: 0xFF})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TIFF with one IFD holding only the orientation tag
+func orientationTIFF(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	// SHORT, one value
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+	return tiff
+}
+
+// marker segment with the length of `payload`
+func segment(marker byte, payload []byte) []byte {
+	s := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(s[2:], uint16(2+len(payload)))
+	return append(s, payload...)
+}
+
+func exifSegment(tiff []byte) []byte {
+	return segment(0xE1, append([]byte("Exif\x00\x00"), tiff...))
+}
+
+// `segments` put right after the start of image
+func withSegments(jpg []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, jpg[:2]...)
+	for _, s := range segments {
+		out = append(out, s...)
+	}
+	return append(out, jpg[2:]...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	jpg := halfBlackJPEG(t, 8, 8)
+	for orientation := range uint16(8) {
+		orientation++
+		for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+			data := withSegments(jpg, exifSegment(orientationTIFF(order, orientation)))
+			if got := exifOrientation(data); got != int(orientation) {
+				t.Errorf("exifOrientation %d %s = %d", orientation, order, got)
+			}
+		}
+	}
+
+	jfif := segment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	if got := exifOrientation(withSegments(jpg, jfif, exifSegment(orientationTIFF(binary.BigEndian, 6)))); got != 6 {
+		t.Errorf("exifOrientation after APP0 = %d, want 6", got)
+	}
+}
+
+// nothing in the upload can make it panic, anything it does not understand is 1
+func TestExifOrientationMalformed(t *testing.T) {
+	jpg := halfBlackJPEG(t, 8, 8)
+	valid := orientationTIFF(binary.BigEndian, 6)
+
+	withIFD := func(offset uint32) []byte {
+		tiff := append([]byte{}, valid...)
+		binary.BigEndian.PutUint32(tiff[4:], offset)
+		return tiff
+	}
+	withEntries := func(count uint16) []byte {
+		tiff := append([]byte{}, valid...)
+		binary.BigEndian.PutUint16(tiff[8:], count)
+		return tiff[:len(tiff)-4-6]
+	}
+	sos := []byte{0xFF, 0xDA, 0x00, 0x02}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "not a JPEG", data: append([]byte("GIF89a"), exifSegment(valid)...)},
+		{name: "only the start of image", data: []byte{0xFF, 0xD8}},
+		{name: "segment header cut off", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x01}},
+		{name: "length past the end", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 'E', 'x', 'i', 'f'}},
+		{name: "length below 2", data: withSegments(jpg, []byte{0xFF, 0xE1, 0x00, 0x01})},
+		{name: "zero length", data: withSegments(jpg, []byte{0xFF, 0xE1, 0x00, 0x00})},
+		{name: "no Exif header", data: withSegments(jpg, segment(0xE1, valid))},
+		{name: "Exif header only", data: withSegments(jpg, exifSegment(nil))},
+		{name: "TIFF header cut off", data: withSegments(jpg, exifSegment(valid[:7]))},
+		{name: "unknown byte order", data: withSegments(jpg, exifSegment(append([]byte("XX"), valid[2:]...)))},
+		{name: "IFD past the end", data: withSegments(jpg, exifSegment(withIFD(uint32(len(valid)))))},
+		{name: "IFD offset with the top bit set", data: withSegments(jpg, exifSegment(withIFD(0xFFFFFFFF)))},
+		{name: "IFD pointing at its count", data: withSegments(jpg, exifSegment(withIFD(uint32(len(valid)-2))))},
+		{name: "more entries than there are", data: withSegments(jpg, exifSegment(withEntries(0xFFFF)))},
+		{name: "orientation 0", data: withSegments(jpg, exifSegment(orientationTIFF(binary.BigEndian, 0)))},
+		{name: "orientation 9", data: withSegments(jpg, exifSegment(orientationTIFF(binary.BigEndian, 9)))},
+		{name: "orientation 0xFFFF", data: withSegments(jpg, exifSegment(orientationTIFF(binary.BigEndian, 0xFFFF)))},
+		{name: "Exif after the start of scan", data: withSegments(jpg, sos, exifSegment(valid))},
+		{name: "garbage between segments", data: withSegments(jpg, []byte{0x00, 0x00}, exifSegment(valid))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exifOrientation(tt.data); got != 1 {
+				t.Errorf("exifOrientation = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// a b c
+	// d e f
+	src := image.NewGray(image.Rect(0, 0, 3, 2))
+	for i, c := range "abcdef" {
+		src.Pix[i] = byte(c)
+	}
+	tests := []struct {
+		orientation int
+		want        string
+	}{
+		{1, "abc/def"},
+		{2, "cba/fed"},
+		{3, "fed/cba"},
+		{4, "def/abc"},
+		{5, "ad/be/cf"},
+		{6, "da/eb/fc"},
+		{7, "fc/eb/da"},
+		{8, "cf/be/ad"},
+		{0, "abc/def"},
+		{9, "abc/def"},
+	}
+	for _, tt := range tests {
+		img := orient(src, tt.orientation)
+		var rows []string
+		for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+			var row strings.Builder
+			for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+				row.WriteByte(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			}
+			rows = append(rows, row.String())
+		}
+		if got := strings.Join(rows, "/"); got != tt.want {
+			t.Errorf("orient %d = %s, want %s", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+// the variants are upright, the black half of the upload ends up where the orientation says
+func TestProcessOrientation(t *testing.T) {
+	jpg := halfBlackJPEG(t, 40, 20)
+	tests := []struct {
+		orientation int
+		w, h        int
+		// side the black half is on
+		black string
+	}{
+		{1, 40, 20, "left"},
+		{2, 40, 20, "right"},
+		{3, 40, 20, "right"},
+		{4, 40, 20, "left"},
+		{5, 20, 40, "top"},
+		{6, 20, 40, "top"},
+		{7, 20, 40, "bottom"},
+		{8, 20, 40, "bottom"},
+	}
+	for _, tt := range tests {
+		data := withSegments(jpg, exifSegment(orientationTIFF(binary.BigEndian, uint16(tt.orientation))))
+		variants, err := Process(bytes.NewReader(data), HousePicture)
+		if err != nil {
+			t.Fatalf("Process with orientation %d: %v", tt.orientation, err)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(variants[SizeFull]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds().Dx() != tt.w || img.Bounds().Dy() != tt.h {
+			t.Errorf("orientation %d size = %v, want %dx%d", tt.orientation, img.Bounds().Size(), tt.w, tt.h)
+			continue
+		}
+
+		w, h := tt.w, tt.h
+		points := map[string]image.Point{
+			"left": {w / 4, h / 2}, "right": {w * 3 / 4, h / 2},
+			"top": {w / 2, h / 4}, "bottom": {w / 2, h * 3 / 4},
+		}
+		opposite := map[string]string{"left": "right", "right": "left", "top": "bottom", "bottom": "top"}
+		gray := func(p image.Point) uint8 {
+			return color.GrayModel.Convert(img.At(p.X, p.Y)).(color.Gray).Y
+		}
+		if dark, light := gray(points[tt.black]), gray(points[opposite[tt.black]]); dark > 0x40 || light < 0xC0 {
+			t.Errorf("orientation %d: %s is %d and %s is %d, want black on the %s", tt.orientation,
+				tt.black, dark, opposite[tt.black], light, tt.black)
+		}
+	}
+}
+
+// nothing of the upload but the pixels is kept
+func TestProcessDropsExif(t *testing.T) {
+	tiff := orientationTIFF(binary.BigEndian, 1)
+	// a GPS position would be in the metadata too, it only has to be recognisable
+	tiff = append(tiff, []byte("GPS 59.4370 24.7536")...)
+	data := withSegments(halfBlackJPEG(t, 40, 20), exifSegment(tiff), segment(0xFE, []byte("camera comment")))
+
+	variants, err := Process(bytes.NewReader(data), Avatar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(variants) != len(Avatar.Sizes) {
+		t.Errorf("got %d variants, want %d", len(variants), len(Avatar.Sizes))
+	}
+	for size, out := range variants {
+		for _, leak := range []string{"Exif", "GPS", "camera comment"} {
+			if bytes.Contains(out, []byte(leak)) {
+				t.Errorf("%s variant contains %q", size, leak)
+			}
+		}
+		// every segment before the image data
+		for i := 2; i+4 <= len(out) && out[i] == 0xFF && out[i+1] != 0xDA; i += 2 + int(binary.BigEndian.Uint16(out[i+2:])) {
+			if marker := out[i+1]; marker == 0xE1 || marker == 0xFE {
+				t.Errorf("%s variant has the marker %#x", size, marker)
+			}
+		}
+		if exifOrientation(out) != 1 {
+			t.Errorf("%s variant has an orientation", size)
+		}
+		config, format, err := image.DecodeConfig(bytes.NewReader(out))
+		if err != nil || format != "jpeg" {
+			t.Fatalf("%s variant is not a JPEG: %s, %v", size, format, err)
+		}
+		// square and not enlarged
+		if config.Width != 20 || config.Height != 20 {
+			t.Errorf("%s variant is %dx%d, want 20x20", size, config.Width, config.Height)
+		}
+	}
+}
+
+func TestProcessRejectsByMagicBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "text", data: []byte("hello")},
+		{name: "html", data: []byte("<!doctype html><script>alert(1)</script>")},
+		{name: "svg", data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)},
+		{name: "pdf", data: []byte("%PDF-1.7\n")},
+		{name: "zip", data: []byte("PK\x03\x04")},
+		{name: "bmp", data: []byte("BM\x00\x00\x00\x00")},
+		{name: "RIFF but not WEBP", data: []byte("RIFF\x00\x00\x00\x00WAVEfmt ")},
+		{name: "JPEG start of image only", data: []byte{0xFF, 0xD8}},
+		// the magic bytes pass, decoding does not
+		{name: "JPEG magic with nothing after", data: []byte{0xFF, 0xD8, 0xFF}},
+		{name: "PNG magic with nothing after", data: []byte("\x89PNG\r\n\x1a\n")},
+		{name: "GIF magic with nothing after", data: []byte("GIF89a")},
+		{name: "WEBP magic with nothing after", data: []byte("RIFF\x04\x00\x00\x00WEBP")},
+		{name: "JPEG cut off", data: halfBlackJPEG(t, 40, 20)[:100]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(bytes.NewReader(tt.data), Avatar); !errors.Is(err, ErrUnsupportedFormat) {
+				t.Errorf("Process = %v, want %v", err, ErrUnsupportedFormat)
+			}
+		})
+	}
+}
+
+// PNG with only the header of an image of w x h, enough for DecodeConfig
+func pngHeader(t *testing.T, w, h uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// signature, length, "IHDR", width, height, ... and the checksum of the chunk type and data
+	binary.BigEndian.PutUint32(data[16:], w)
+	binary.BigEndian.PutUint32(data[20:], h)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestProcessRefusesTooManyPixels(t *testing.T) {
+	for _, size := range [][2]uint32{{10_000, 10_000}, {maxPixels + 1, 1}, {1, maxPixels + 1}, {6325, 6325}} {
+		data := pngHeader(t, size[0], size[1])
+		if _, err := Process(bytes.NewReader(data), HousePicture); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("Process of %dx%d = %v, want %v", size[0], size[1], err, ErrTooLarge)
+		}
+	}
+
+	// the header is read correctly, an image that fits is decoded
+	data := pngHeader(t, 1, 1)
+	if _, err := Process(bytes.NewReader(data), HousePicture); err != nil {
+		t.Errorf("Process of 1x1 = %v", err)
+	}
+}
+
+func TestProcessRefusesTooLargeUpload(t *testing.T) {
+	data := make([]byte, MaxUploadSize+1)
+	copy(data, halfBlackJPEG(t, 8, 8))
+	if _, err := Process(bytes.NewReader(data), Avatar); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Process = %v, want %v", err, ErrTooLarge)
+	}
+}
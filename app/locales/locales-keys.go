@@ -12,23 +12,118 @@ type LKMessage struct {
 }
 
 const (
+	LKAccessTokensCreate                  LK = "access-tokens.create"
+	LKAccessTokensCreated                 LK = "access-tokens.created"
+	LKAccessTokensCreatedAt               LK = "access-tokens.created-at"
+	LKAccessTokensErrorNameLength         LK = "access-tokens.error-name-length"
+	LKAccessTokensErrorNoScopes           LK = "access-tokens.error-no-scopes"
+	LKAccessTokensInfo                    LK = "access-tokens.info"
+	LKAccessTokensLastUsed                LK = "access-tokens.last-used"
+	LKAccessTokensName                    LK = "access-tokens.name"
+	LKAccessTokensNeverUsed               LK = "access-tokens.never-used"
+	LKAccessTokensNone                    LK = "access-tokens.none"
+	LKAccessTokensRevoke                  LK = "access-tokens.revoke"
+	LKAccessTokensScopeReadHouses         LK = "access-tokens.scope.read-houses"
+	LKAccessTokensScopeReadMessages       LK = "access-tokens.scope.read-messages"
+	LKAccessTokensScopeReadNotes          LK = "access-tokens.scope.read-notes"
+	LKAccessTokensScopeReadPayments       LK = "access-tokens.scope.read-payments"
+	LKAccessTokensScopeReadProfile        LK = "access-tokens.scope.read-profile"
+	LKAccessTokensScopeWriteHouses        LK = "access-tokens.scope.write-houses"
+	LKAccessTokensScopeWriteMessages      LK = "access-tokens.scope.write-messages"
+	LKAccessTokensScopeWriteNotes         LK = "access-tokens.scope.write-notes"
+	LKAccessTokensScopeWritePayments      LK = "access-tokens.scope.write-payments"
+	LKAccessTokensScopes                  LK = "access-tokens.scopes"
+	LKAccessTokensTitle                   LK = "access-tokens.title"
+	LKAccountCancel                       LK = "account.cancel"
+	LKAccountDelete                       LK = "account.delete"
+	LKAccountDeleteConfirm                LK = "account.delete-confirm"
+	LKAccountDeleteEmail                  LK = "account.delete-email"
+	LKAccountDeleteInfo                   LK = "account.delete-info"
+	LKAccountDeleteInvalid                LK = "account.delete-invalid"
+	LKAccountDeletePassword               LK = "account.delete-password"
+	LKAccountDeleteSend                   LK = "account.delete-send"
+	LKAccountDeleteSent                   LK = "account.delete-sent"
+	LKAccountDeleteTitle                  LK = "account.delete-title"
+	LKAccountExport                       LK = "account.export"
+	LKAccountExportInfo                   LK = "account.export-info"
+	LKAccountExportTitle                  LK = "account.export-title"
+	LKAccountScheduled                    LK = "account.scheduled"
+	LKActivityMemberAdded                 LK = "activity.member-added"
+	LKActivityMemberDeleted               LK = "activity.member-deleted"
+	LKActivityMemberRemoved               LK = "activity.member-removed"
+	LKActivityMessageMentioned            LK = "activity.message-mentioned"
+	LKActivityNoteCreated                 LK = "activity.note-created"
+	LKActivityNoteDeleted                 LK = "activity.note-deleted"
+	LKActivityNoteEdited                  LK = "activity.note-edited"
+	LKActivityPaymentCreated              LK = "activity.payment-created"
+	LKActivityPaymentSettled              LK = "activity.payment-settled"
+	LKActivityReminderCompleted           LK = "activity.reminder-completed"
+	LKActivityReminderDue                 LK = "activity.reminder-due"
+	LKActivityShoppingAdded               LK = "activity.shopping-added"
+	LKActivityShoppingBought              LK = "activity.shopping-bought"
+	LKActivityShoppingSettled             LK = "activity.shopping-settled"
+	LKActivityUnknownUser                 LK = "activity.unknown-user"
 	LKAppTitle                            LK = "app.title"
+	LKCalendarFeedsAllHouses              LK = "calendar-feeds.all-houses"
+	LKCalendarFeedsCalendarName           LK = "calendar-feeds.calendar-name"
+	LKCalendarFeedsCreate                 LK = "calendar-feeds.create"
+	LKCalendarFeedsCreated                LK = "calendar-feeds.created"
+	LKCalendarFeedsHouse                  LK = "calendar-feeds.house"
+	LKCalendarFeedsInfo                   LK = "calendar-feeds.info"
+	LKCalendarFeedsNone                   LK = "calendar-feeds.none"
+	LKCalendarFeedsPayment                LK = "calendar-feeds.payment"
+	LKCalendarFeedsPaymentDescription     LK = "calendar-feeds.payment-description"
+	LKCalendarFeedsReminderDescription    LK = "calendar-feeds.reminder-description"
+	LKCalendarFeedsRevoke                 LK = "calendar-feeds.revoke"
+	LKCalendarFeedsTitle                  LK = "calendar-feeds.title"
+	LKChatCommandsCommands                LK = "chat-commands.commands"
+	LKChatCommandsErrorForbidden          LK = "chat-commands.error-forbidden"
+	LKChatCommandsErrorNoPayers           LK = "chat-commands.error-no-payers"
+	LKChatCommandsErrorNotResident        LK = "chat-commands.error-not-resident"
+	LKChatCommandsErrorNoteNotFound       LK = "chat-commands.error-note-not-found"
+	LKChatCommandsErrorReminderAmbiguous  LK = "chat-commands.error-reminder-ambiguous"
+	LKChatCommandsErrorReminderNotFound   LK = "chat-commands.error-reminder-not-found"
+	LKChatCommandsErrorUnknown            LK = "chat-commands.error-unknown"
+	LKChatCommandsNoteFound               LK = "chat-commands.note-found"
+	LKChatCommandsPaymentCreated          LK = "chat-commands.payment-created"
+	LKChatCommandsReminderCompleted       LK = "chat-commands.reminder-completed"
+	LKChatCommandsUsageChore              LK = "chat-commands.usage-chore"
+	LKChatCommandsUsageHelp               LK = "chat-commands.usage-help"
+	LKChatCommandsUsageNote               LK = "chat-commands.usage-note"
+	LKChatCommandsUsageOwe                LK = "chat-commands.usage-owe"
+	LKEmailAccountDeletionBody            LK = "email.account-deletion.body"
+	LKEmailAccountDeletionButton          LK = "email.account-deletion.button"
+	LKEmailAccountDeletionSubject         LK = "email.account-deletion.subject"
+	LKEmailOpen                           LK = "email.open"
+	LKEmailPasswordResetBody              LK = "email.password-reset.body"
+	LKEmailPasswordResetButton            LK = "email.password-reset.button"
+	LKEmailPasswordResetSubject           LK = "email.password-reset.subject"
+	LKEmailUnsubscribe                    LK = "email.unsubscribe"
+	LKEmailVerifyBody                     LK = "email.verify.body"
+	LKEmailVerifyButton                   LK = "email.verify.button"
+	LKEmailVerifySubject                  LK = "email.verify.subject"
 	LKFormsContentErrorEmpty              LK = "forms.content.error-empty"
 	LKFormsContentTitle                   LK = "forms.content.title"
 	LKFormsDelete                         LK = "forms.delete"
+	LKFormsDueDateErrorInvalid            LK = "forms.due-date.error-invalid"
+	LKFormsDueDateTitle                   LK = "forms.due-date.title"
 	LKFormsEdit                           LK = "forms.edit"
 	LKFormsEmailErrorGeneric              LK = "forms.email.error-generic"
 	LKFormsEmailTitle                     LK = "forms.email.title"
 	LKFormsErrorAlreadyExists             LK = "forms.error.already-exists"
 	LKFormsErrorInvalidCredential         LK = "forms.error.invalid-credential"
+	LKFormsErrorTooManyRequests           LK = "forms.error.too-many-requests"
 	LKFormsErrorsNoMultipleSpaces         LK = "forms.errors.no-multiple-spaces"
 	LKFormsErrorsOnlyLettersAndDigits     LK = "forms.errors.only-letters-and-digits"
+	LKFormsFullNameErrorLength            LK = "forms.full-name.error-length"
 	LKFormsFullNameInfo                   LK = "forms.full-name.info"
 	LKFormsFullNameMarkPublic             LK = "forms.full-name.mark-public"
 	LKFormsFullNameTitle                  LK = "forms.full-name.title"
 	LKFormsHouseAddUsers                  LK = "forms.house.add-users"
+	LKFormsHouseErrorSomeRoommatesBlocked LK = "forms.house.error-some-roommates-blocked"
 	LKFormsHouseErrorSomeRoommatesInvalid LK = "forms.house.error-some-roommates-invalid"
 	LKFormsHouseNameLabel                 LK = "forms.house.name-label"
+	LKFormsHouseSearchPlaceholder         LK = "forms.house.search-placeholder"
 	LKFormsHouseTitle                     LK = "forms.house.title"
 	LKFormsHouseTitleNew                  LK = "forms.house.title-new"
 	LKFormsNameErrorEmpty                 LK = "forms.name.error-empty"
@@ -36,32 +131,243 @@ const (
 	LKFormsNoteTitle                      LK = "forms.note.title"
 	LKFormsNoteTitleNew                   LK = "forms.note.title-new"
 	LKFormsPasswordConfirm                LK = "forms.password.confirm"
+	LKFormsPasswordCurrent                LK = "forms.password.current"
 	LKFormsPasswordErrorCase              LK = "forms.password.error-case"
+	LKFormsPasswordErrorCurrentEmpty      LK = "forms.password.error-current-empty"
+	LKFormsPasswordErrorCurrentWrong      LK = "forms.password.error-current-wrong"
 	LKFormsPasswordErrorLength            LK = "forms.password.error-length"
 	LKFormsPasswordErrorMustMatch         LK = "forms.password.error-must-match"
 	LKFormsPasswordErrorSymbol            LK = "forms.password.error-symbol"
+	LKFormsPasswordNew                    LK = "forms.password.new"
 	LKFormsPasswordTitle                  LK = "forms.password.title"
+	LKFormsPaymentAmount                  LK = "forms.payment.amount"
+	LKFormsPaymentErrorAmount             LK = "forms.payment.error-amount"
+	LKFormsPaymentErrorNoPayers           LK = "forms.payment.error-no-payers"
+	LKFormsPaymentPayers                  LK = "forms.payment.payers"
+	LKFormsPaymentTitleNew                LK = "forms.payment.title-new"
+	LKFormsReminderAssignee               LK = "forms.reminder.assignee"
+	LKFormsReminderAssigneeHouse          LK = "forms.reminder.assignee-house"
+	LKFormsReminderErrorRecurrence        LK = "forms.reminder.error-recurrence"
+	LKFormsReminderErrorRecurrenceNoDate  LK = "forms.reminder.error-recurrence-no-date"
+	LKFormsReminderRecurrence             LK = "forms.reminder.recurrence"
+	LKFormsReminderTitle                  LK = "forms.reminder.title"
 	LKFormsSubmit                         LK = "forms.submit"
 	LKFormsUpdate                         LK = "forms.update"
 	LKFormsUsernameErrorLength            LK = "forms.username.error-length"
 	LKFormsUsernameErrorSpaces            LK = "forms.username.error-spaces"
 	LKFormsUsernameInfo                   LK = "forms.username.info"
 	LKFormsUsernameTitle                  LK = "forms.username.title"
+	LKHouseActivity                       LK = "house.activity"
+	LKHouseLoadMore                       LK = "house.load-more"
+	LKHouseNewPayment                     LK = "house.new-payment"
+	LKHouseNoActivity                     LK = "house.no-activity"
+	LKHouseNoReminders                    LK = "house.no-reminders"
+	LKHouseReminders                      LK = "house.reminders"
+	LKHouseResidents                      LK = "house.residents"
 	LKHousesNoHouses                      LK = "houses.no-houses"
 	LKHousesResidentCount                 LK = "houses.resident-count"
 	LKHousesResidentCountOne              LK = "houses.resident-count.one"
 	LKHousesResidentCountOther            LK = "houses.resident-count.other"
 	LKHousesYourHouses                    LK = "houses.your-houses"
+	LKImagesAvatar                        LK = "images.avatar"
+	LKImagesErrorFormat                   LK = "images.error-format"
+	LKImagesErrorMissing                  LK = "images.error-missing"
+	LKImagesErrorTooLarge                 LK = "images.error-too-large"
+	LKImagesHousePicture                  LK = "images.house-picture"
+	LKImagesInfo                          LK = "images.info"
+	LKImagesRemove                        LK = "images.remove"
+	LKImagesUpload                        LK = "images.upload"
+	LKLoginForgotPassword                 LK = "login.forgot-password"
 	LKLoginNoAccount                      LK = "login.no-account"
 	LKLoginRegister                       LK = "login.register"
 	LKLoginTitle                          LK = "login.title"
 	LKNavbarHouses                        LK = "navbar.houses"
 	LKNavbarMessaging                     LK = "navbar.messaging"
 	LKNavbarNotes                         LK = "navbar.notes"
+	LKNavbarNotifications                 LK = "navbar.notifications"
 	LKNavbarPayments                      LK = "navbar.payments"
 	LKNavbarProfile                       LK = "navbar.profile"
 	LKNotesNew                            LK = "notes.new"
+	LKNotificationsKindChatMention        LK = "notifications.kind.chat-mention"
+	LKNotificationsKindHouseInvite        LK = "notifications.kind.house-invite"
+	LKNotificationsKindNoteChanged        LK = "notifications.kind.note-changed"
+	LKNotificationsKindPaymentAssigned    LK = "notifications.kind.payment-assigned"
+	LKNotificationsKindReminderDue        LK = "notifications.kind.reminder-due"
+	LKNotificationsMarkAllRead            LK = "notifications.mark-all-read"
+	LKNotificationsMessageChatMention     LK = "notifications.message.chat-mention"
+	LKNotificationsMessageHouseInvite     LK = "notifications.message.house-invite"
+	LKNotificationsMessageNoteChanged     LK = "notifications.message.note-changed"
+	LKNotificationsMessagePaymentAssigned LK = "notifications.message.payment-assigned"
+	LKNotificationsMessageReminderDue     LK = "notifications.message.reminder-due"
+	LKNotificationsNone                   LK = "notifications.none"
+	LKNotificationsPreferences            LK = "notifications.preferences"
+	LKNotificationsPreferencesSaved       LK = "notifications.preferences-saved"
+	LKNotificationsTitle                  LK = "notifications.title"
+	LKOidcConnect                         LK = "oidc.connect"
+	LKOidcConnected                       LK = "oidc.connected"
+	LKOidcContinueWith                    LK = "oidc.continue-with"
+	LKOidcDisconnect                      LK = "oidc.disconnect"
+	LKOidcErrorEmailNotVerified           LK = "oidc.error-email-not-verified"
+	LKOidcErrorFailed                     LK = "oidc.error-failed"
+	LKOidcErrorLinkUnverified             LK = "oidc.error-link-unverified"
+	LKOidcErrorState                      LK = "oidc.error-state"
+	LKOidcErrorTaken                      LK = "oidc.error-taken"
+	LKOidcInfo                            LK = "oidc.info"
+	LKOidcTitle                           LK = "oidc.title"
+	LKPasswordChangeChanged               LK = "password-change.changed"
+	LKPasswordChangeTitle                 LK = "password-change.title"
+	LKPasswordResetBackToLogin            LK = "password-reset.back-to-login"
+	LKPasswordResetInfo                   LK = "password-reset.info"
+	LKPasswordResetInvalid                LK = "password-reset.invalid"
+	LKPasswordResetSend                   LK = "password-reset.send"
+	LKPasswordResetSent                   LK = "password-reset.sent"
+	LKPasswordResetSubmit                 LK = "password-reset.submit"
+	LKPasswordResetSuccess                LK = "password-reset.success"
+	LKPasswordResetTitle                  LK = "password-reset.title"
+	LKPaymentsDue                         LK = "payments.due"
+	LKPaymentsNoPayments                  LK = "payments.no-payments"
+	LKPaymentsPending                     LK = "payments.pending"
+	LKPaymentsRequested                   LK = "payments.requested"
+	LKPaymentsSettle                      LK = "payments.settle"
+	LKPaymentsSettled                     LK = "payments.settled"
+	LKPaymentsTitle                       LK = "payments.title"
+	LKPrivacyBlock                        LK = "privacy.block"
+	LKPrivacyBlockConfirm                 LK = "privacy.block-confirm"
+	LKPrivacyBlockedInfo                  LK = "privacy.blocked-info"
+	LKPrivacyBlockedNone                  LK = "privacy.blocked-none"
+	LKPrivacyBlockedTitle                 LK = "privacy.blocked-title"
+	LKPrivacyDiscoverabilityEmail         LK = "privacy.discoverability-email"
+	LKPrivacyDiscoverabilityEveryone      LK = "privacy.discoverability-everyone"
+	LKPrivacyDiscoverabilityHousemates    LK = "privacy.discoverability-housemates"
+	LKPrivacyInfo                         LK = "privacy.info"
+	LKPrivacySaved                        LK = "privacy.saved"
+	LKPrivacyTitle                        LK = "privacy.title"
+	LKPrivacyUnblock                      LK = "privacy.unblock"
+	LKProfileContactInfo                  LK = "profile.contact-info"
+	LKProfileContactTitle                 LK = "profile.contact-title"
+	LKProfileErrorHandle                  LK = "profile.error-handle"
+	LKProfileErrorIban                    LK = "profile.error-iban"
+	LKProfileErrorPhone                   LK = "profile.error-phone"
+	LKProfileIban                         LK = "profile.iban"
+	LKProfileMemberSince                  LK = "profile.member-since"
+	LKProfileNoContacts                   LK = "profile.no-contacts"
+	LKProfilePhone                        LK = "profile.phone"
+	LKProfileSaved                        LK = "profile.saved"
+	LKProfileSignal                       LK = "profile.signal"
+	LKProfileTelegram                     LK = "profile.telegram"
+	LKProfileTitle                        LK = "profile.title"
+	LKProfileView                         LK = "profile.view"
+	LKProfileVisible                      LK = "profile.visible"
 	LKRegisterAlreadyHaveAccount          LK = "register.already-have-account"
 	LKRegisterTitle                       LK = "register.title"
+	LKRemindersAssignedTo                 LK = "reminders.assigned-to"
+	LKRemindersComplete                   LK = "reminders.complete"
+	LKRemindersDue                        LK = "reminders.due"
+	LKRemindersNew                        LK = "reminders.new"
+	LKRemindersRecurrenceDaily            LK = "reminders.recurrence.daily"
+	LKRemindersRecurrenceMonthly          LK = "reminders.recurrence.monthly"
+	LKRemindersRecurrenceNone             LK = "reminders.recurrence.none"
+	LKRemindersRecurrenceWeekly           LK = "reminders.recurrence.weekly"
 	LKSearchResultsFor                    LK = "search-results-for"
+	LKSessionsCreated                     LK = "sessions.created"
+	LKSessionsCurrent                     LK = "sessions.current"
+	LKSessionsLastSeen                    LK = "sessions.last-seen"
+	LKSessionsSignOut                     LK = "sessions.sign-out"
+	LKSessionsSignOutEverywhere           LK = "sessions.sign-out-everywhere"
+	LKSessionsTitle                       LK = "sessions.title"
+	LKSessionsUnknownDevice               LK = "sessions.unknown-device"
+	LKShoppingAdd                         LK = "shopping.add"
+	LKShoppingAddedBy                     LK = "shopping.added-by"
+	LKShoppingAsPayment                   LK = "shopping.as-payment"
+	LKShoppingBoughtBy                    LK = "shopping.bought-by"
+	LKShoppingBoughtThese                 LK = "shopping.bought-these"
+	LKShoppingCategories                  LK = "shopping.categories"
+	LKShoppingCategoriesBakery            LK = "shopping.categories.bakery"
+	LKShoppingCategoriesDairy             LK = "shopping.categories.dairy"
+	LKShoppingCategoriesDrinks            LK = "shopping.categories.drinks"
+	LKShoppingCategoriesFrozen            LK = "shopping.categories.frozen"
+	LKShoppingCategoriesHousehold         LK = "shopping.categories.household"
+	LKShoppingCategoriesMeat              LK = "shopping.categories.meat"
+	LKShoppingCategoriesOther             LK = "shopping.categories.other"
+	LKShoppingCategoriesPantry            LK = "shopping.categories.pantry"
+	LKShoppingCategoriesProduce           LK = "shopping.categories.produce"
+	LKShoppingCategory                    LK = "shopping.category"
+	LKShoppingDelete                      LK = "shopping.delete"
+	LKShoppingErrorCategory               LK = "shopping.error-category"
+	LKShoppingErrorNameLength             LK = "shopping.error-name-length"
+	LKShoppingErrorNoItems                LK = "shopping.error-no-items"
+	LKShoppingErrorQuantityLength         LK = "shopping.error-quantity-length"
+	LKShoppingName                        LK = "shopping.name"
+	LKShoppingNone                        LK = "shopping.none"
+	LKShoppingPaymentName                 LK = "shopping.payment-name"
+	LKShoppingPurchaseTitle               LK = "shopping.purchase-title"
+	LKShoppingQuantity                    LK = "shopping.quantity"
+	LKShoppingTitle                       LK = "shopping.title"
+	LKTwoFactorCode                       LK = "two-factor.code"
+	LKTwoFactorConfirm                    LK = "two-factor.confirm"
+	LKTwoFactorDisable                    LK = "two-factor.disable"
+	LKTwoFactorDisableCodeInfo            LK = "two-factor.disable-code-info"
+	LKTwoFactorDisableInfo                LK = "two-factor.disable-info"
+	LKTwoFactorDisabled                   LK = "two-factor.disabled"
+	LKTwoFactorEnable                     LK = "two-factor.enable"
+	LKTwoFactorEnabled                    LK = "two-factor.enabled"
+	LKTwoFactorErrorEmpty                 LK = "two-factor.error-empty"
+	LKTwoFactorInvalidCode                LK = "two-factor.invalid-code"
+	LKTwoFactorLoginInfo                  LK = "two-factor.login-info"
+	LKTwoFactorLoginTitle                 LK = "two-factor.login-title"
+	LKTwoFactorRecoveryCodes              LK = "two-factor.recovery-codes"
+	LKTwoFactorRecoveryCodesInfo          LK = "two-factor.recovery-codes-info"
+	LKTwoFactorRecoveryCodesLeft          LK = "two-factor.recovery-codes-left"
+	LKTwoFactorScan                       LK = "two-factor.scan"
+	LKTwoFactorTitle                      LK = "two-factor.title"
+	LKUnsubscribeAll                      LK = "unsubscribe.all"
+	LKUnsubscribeConfirm                  LK = "unsubscribe.confirm"
+	LKUnsubscribeDone                     LK = "unsubscribe.done"
+	LKUnsubscribeInvalid                  LK = "unsubscribe.invalid"
+	LKUnsubscribeTitle                    LK = "unsubscribe.title"
+	LKVerifyEmailBanner                   LK = "verify-email.banner"
+	LKVerifyEmailInvalid                  LK = "verify-email.invalid"
+	LKVerifyEmailRequired                 LK = "verify-email.required"
+	LKVerifyEmailResend                   LK = "verify-email.resend"
+	LKVerifyEmailSent                     LK = "verify-email.sent"
+	LKVerifyEmailSuccess                  LK = "verify-email.success"
+	LKVerifyEmailTitle                    LK = "verify-email.title"
+	LKVerifyEmailWait                     LK = "verify-email.wait"
+	LKWebhooksCreate                      LK = "webhooks.create"
+	LKWebhooksCreated                     LK = "webhooks.created"
+	LKWebhooksDelete                      LK = "webhooks.delete"
+	LKWebhooksDeliveries                  LK = "webhooks.deliveries"
+	LKWebhooksDeliveriesNone              LK = "webhooks.deliveries-none"
+	LKWebhooksDeliveryAttempt             LK = "webhooks.delivery-attempt"
+	LKWebhooksDeliveryNoResponse          LK = "webhooks.delivery-no-response"
+	LKWebhooksDisabled                    LK = "webhooks.disabled"
+	LKWebhooksEnable                      LK = "webhooks.enable"
+	LKWebhooksEnabled                     LK = "webhooks.enabled"
+	LKWebhooksErrorNoEvents               LK = "webhooks.error-no-events"
+	LKWebhooksErrorTooMany                LK = "webhooks.error-too-many"
+	LKWebhooksErrorUrlEmpty               LK = "webhooks.error-url-empty"
+	LKWebhooksErrorUrlHttps               LK = "webhooks.error-url-https"
+	LKWebhooksErrorUrlInvalid             LK = "webhooks.error-url-invalid"
+	LKWebhooksErrorUrlLength              LK = "webhooks.error-url-length"
+	LKWebhooksErrorUrlPrivate             LK = "webhooks.error-url-private"
+	LKWebhooksEventMemberAdded            LK = "webhooks.event.member-added"
+	LKWebhooksEventMemberDeleted          LK = "webhooks.event.member-deleted"
+	LKWebhooksEventMemberRemoved          LK = "webhooks.event.member-removed"
+	LKWebhooksEventMessageMentioned       LK = "webhooks.event.message-mentioned"
+	LKWebhooksEventNoteCreated            LK = "webhooks.event.note-created"
+	LKWebhooksEventNoteDeleted            LK = "webhooks.event.note-deleted"
+	LKWebhooksEventNoteEdited             LK = "webhooks.event.note-edited"
+	LKWebhooksEventPaymentCreated         LK = "webhooks.event.payment-created"
+	LKWebhooksEventPaymentSettled         LK = "webhooks.event.payment-settled"
+	LKWebhooksEventReminderCompleted      LK = "webhooks.event.reminder-completed"
+	LKWebhooksEventReminderDue            LK = "webhooks.event.reminder-due"
+	LKWebhooksEventShoppingAdded          LK = "webhooks.event.shopping-added"
+	LKWebhooksEventShoppingBought         LK = "webhooks.event.shopping-bought"
+	LKWebhooksEventShoppingSettled        LK = "webhooks.event.shopping-settled"
+	LKWebhooksEvents                      LK = "webhooks.events"
+	LKWebhooksFailures                    LK = "webhooks.failures"
+	LKWebhooksInfo                        LK = "webhooks.info"
+	LKWebhooksNone                        LK = "webhooks.none"
+	LKWebhooksTitle                       LK = "webhooks.title"
+	LKWebhooksUrl                         LK = "webhooks.url"
 )
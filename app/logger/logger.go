@@ -16,6 +16,15 @@ var Main = zerolog.New(os.Stdout).With().Timestamp().Logger()
 var MigrationLoggger = Main.With().Str("component", "migration").Logger()
 var ControllerLoggger = Main.With().Str("component", "controller").Logger()
 var RedisLoggger = Main.With().Str("component", "controller").Logger()
+var EventsLoggger = Main.With().Str("component", "events").Logger()
+var BrokerLoggger = Main.With().Str("component", "broker").Logger()
+var AuditLoggger = Main.With().Str("component", "audit").Logger()
+var MailerLoggger = Main.With().Str("component", "mailer").Logger()
+var OIDCLoggger = Main.With().Str("component", "oidc").Logger()
+var AccountsLoggger = Main.With().Str("component", "accounts").Logger()
+var ImagesLoggger = Main.With().Str("component", "images").Logger()
+var WebhooksLoggger = Main.With().Str("component", "webhooks").Logger()
+var RemindersLoggger = Main.With().Str("component", "reminders").Logger()
 
 // Initializes zerolog as the project logger
 // replaces standard log with zerolog
@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// writes every mail into its own .eml file, meant for development and tests
+//
+// the files can be opened with any mail client
+type FileBackend struct {
+	dir string
+}
+
+func NewFile(dir string) *FileBackend {
+	return &FileBackend{dir: dir}
+}
+
+func (b *FileBackend) Deliver(ctx context.Context, from string, to []string, message []byte) error {
+	if err := os.MkdirAll(b.dir, 0o755); err != nil {
+		return err
+	}
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + strings.Join(to, ",") + ".eml"
+	return os.WriteFile(filepath.Join(b.dir, filepath.Base(name)), message, 0o644)
+}
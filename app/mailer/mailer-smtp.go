@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+)
+
+type SMTPBackend struct {
+	addr string
+	auth smtp.Auth
+}
+
+// auth is skipped when username is empty
+func NewSMTP(addr, username, password string) *SMTPBackend {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPBackend{addr: addr, auth: auth}
+}
+
+// net/smtp has no context support, ctx is only checked before sending
+func (b *SMTPBackend) Deliver(ctx context.Context, from string, to []string, message []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(b.addr, b.auth, from, to, message)
+}
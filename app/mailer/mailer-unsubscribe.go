@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"roommates/notifications"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var b64 = base64.RawURLEncoding
+
+// token for the unsubscribe link, signed so it can not be made for someone else
+func (m *Mailer) UnsubscribeToken(userID pgtype.UUID, kind notifications.Kind) string {
+	payload := userID.String() + ":" + string(kind)
+	return b64.EncodeToString([]byte(payload)) + "." + b64.EncodeToString(m.sign(payload))
+}
+
+// ok is false when the token has been tampered with or has no kind
+func (m *Mailer) ParseUnsubscribeToken(token string) (userID pgtype.UUID, kind notifications.Kind, ok bool) {
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+	if !found {
+		return userID, "", false
+	}
+	payload, err := b64.DecodeString(encodedPayload)
+	if err != nil {
+		return userID, "", false
+	}
+	signature, err := b64.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, m.sign(string(payload))) {
+		return userID, "", false
+	}
+
+	id, rawKind, _ := strings.Cut(string(payload), ":")
+	if err = userID.Scan(id); err != nil || rawKind == "" {
+		return userID, "", false
+	}
+	return userID, notifications.Kind(rawKind), true
+}
+
+func (m *Mailer) sign(payload string) []byte {
+	h := hmac.New(sha256.New, m.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
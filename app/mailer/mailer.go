@@ -0,0 +1,165 @@
+// sending emails, templates are templ components rendered with the locale of the context
+//
+// SMTP is used when SMTP_ADDR is set, otherwise emails are written into MAIL_OUTBOX_DIR
+package mailer
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"roommates/components"
+	"roommates/globals"
+	"roommates/logger"
+	"roommates/notifications"
+	"roommates/utils"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var log = logger.MailerLoggger
+
+// delivers already built messages
+type Backend interface {
+	Deliver(ctx context.Context, from string, to []string, message []byte) error
+}
+
+type Mail struct {
+	To string
+	// recipient, used for the unsubscribe link
+	UserID pgtype.UUID
+	// notification kind the mail is about,
+	// account mails (verification, password reset) leave it empty
+	Kind    notifications.Kind
+	Subject string
+	// wrapped with components.EmailLayout
+	Body templ.Component
+}
+
+type Mailer struct {
+	backend Backend
+	from    string
+	// used to make absolute links, e.g. https://roommates.ee
+	appURL string
+	// signs unsubscribe tokens
+	secret []byte
+}
+
+// creates the mailer from environment
+//
+// without MAIL_FROM, APP_URL and MAIL_SECRET nothing is sent, emails are only written
+// into MAIL_OUTBOX_DIR. that way deployments made before emails existed still start
+func New() *Mailer {
+	from := utils.GetEnv("MAIL_FROM", "")
+	appURL := utils.GetEnv("APP_URL", "")
+	secret := utils.GetEnv("MAIL_SECRET", "")
+	configured := from != "" && appURL != "" && secret != ""
+	if !configured {
+		log.Warn().Msg("MAIL_FROM, APP_URL or MAIL_SECRET not set, emails are not sent and links point to localhost")
+		from = cmp.Or(from, "Roommates <no-reply@localhost>")
+		appURL = cmp.Or(appURL, "https://localhost"+utils.GetEnv("SERVER_ADDR", ""))
+		// unsubscribe links stop working after a restart
+		secret = cmp.Or(secret, rand.Text())
+	}
+
+	var backend Backend
+	if addr := utils.GetEnv("SMTP_ADDR", ""); addr != "" && configured {
+		backend = NewSMTP(addr, utils.GetEnv("SMTP_USERNAME", ""), utils.GetEnv("SMTP_PASSWORD", ""))
+	} else {
+		dir := utils.GetEnv("MAIL_OUTBOX_DIR", "./tmp/mail")
+		log.Warn().Str("dir", dir).Msg("emails are written into a directory")
+		backend = NewFile(dir)
+	}
+
+	return NewWithBackend(backend, from, appURL, []byte(secret))
+}
+
+func NewWithBackend(backend Backend, from, appURL string, secret []byte) *Mailer {
+	return &Mailer{
+		backend: backend,
+		from:    from,
+		appURL:  strings.TrimSuffix(appURL, "/"),
+		secret:  secret,
+	}
+}
+
+// absolute url of the path
+func (m *Mailer) URL(path string) string {
+	return m.appURL + path
+}
+
+// renders and sends the mail
+//
+// only notification mails can be unsubscribed from, account mails have to arrive
+func (m *Mailer) Send(ctx context.Context, msg Mail) error {
+	var unsubscribeURL string
+	if msg.Kind != "" {
+		unsubscribeURL = m.URL(utils.ReplaceParam(globals.RUnsubscribe, "token", m.UnsubscribeToken(msg.UserID, msg.Kind)))
+	}
+
+	var html bytes.Buffer
+	layoutCtx := templ.WithChildren(ctx, msg.Body)
+	if err := components.EmailLayout(msg.Subject, unsubscribeURL).Render(layoutCtx, &html); err != nil {
+		return err
+	}
+
+	message, err := m.build(msg, unsubscribeURL, html.Bytes())
+	if err != nil {
+		return err
+	}
+	if err = m.backend.Deliver(ctx, m.from, []string{msg.To}, message); err != nil {
+		return err
+	}
+	log.Debug().Str("kind", string(msg.Kind)).Str("subject", msg.Subject).Msg("mail sent")
+	return nil
+}
+
+func (m *Mailer) build(msg Mail, unsubscribeURL string, html []byte) ([]byte, error) {
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM: %w", err)
+	}
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", m.from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+randomID()+"@"+domain+">")
+	if unsubscribeURL != "" {
+		// https://datatracker.ietf.org/doc/html/rfc8058
+		header("List-Unsubscribe", "<"+unsubscribeURL+">")
+		header("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/html; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err = qp.Write(html); err != nil {
+		return nil, err
+	}
+	if err = qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
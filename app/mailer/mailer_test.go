@@ -0,0 +1,99 @@
+package mailer
+
+import (
+	"context"
+	"roommates/notifications"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// keeps the last delivered message
+type captureBackend struct {
+	message string
+}
+
+func (b *captureBackend) Deliver(ctx context.Context, from string, to []string, message []byte) error {
+	b.message = string(message)
+	return nil
+}
+
+func testUserID(t *testing.T) pgtype.UUID {
+	t.Helper()
+	var id pgtype.UUID
+	if err := id.Scan("0b8c2f3e-6a4d-4c1b-9b52-1f0c3b7c2a11"); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestSendUnsubscribeOnlyForNotifications(t *testing.T) {
+	backend := &captureBackend{}
+	m := NewWithBackend(backend, "Roommates <noreply@roommates.test>", "https://roommates.test", []byte("secret"))
+	mail := Mail{
+		To:      "user@roommates.test",
+		UserID:  testUserID(t),
+		Subject: "subject",
+		Body:    templ.Raw("<p>body</p>"),
+	}
+
+	if err := m.Send(context.Background(), mail); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if strings.Contains(backend.message, "List-Unsubscribe") || strings.Contains(backend.message, "/unsubscribe/") {
+		t.Error("account mail has an unsubscribe link")
+	}
+
+	mail.Kind = notifications.NoteChanged
+	if err := m.Send(context.Background(), mail); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.Contains(backend.message, "List-Unsubscribe: <https://roommates.test/") {
+		t.Error("notification mail has no List-Unsubscribe header")
+	}
+	if !strings.Contains(backend.message, "List-Unsubscribe-Post: List-Unsubscribe=One-Click") {
+		t.Error("notification mail has no List-Unsubscribe-Post header")
+	}
+}
+
+func TestUnsubscribeToken(t *testing.T) {
+	m := NewWithBackend(&captureBackend{}, "noreply@roommates.test", "https://roommates.test", []byte("secret"))
+	userID := testUserID(t)
+
+	token := m.UnsubscribeToken(userID, notifications.NoteChanged)
+	gotID, kind, ok := m.ParseUnsubscribeToken(token)
+	if !ok || gotID != userID || kind != notifications.NoteChanged {
+		t.Errorf("ParseUnsubscribeToken = %v, %q, %v", gotID, kind, ok)
+	}
+
+	if _, _, ok = m.ParseUnsubscribeToken(m.UnsubscribeToken(userID, "")); ok {
+		t.Error("token without a kind was accepted")
+	}
+	other := NewWithBackend(&captureBackend{}, "noreply@roommates.test", "https://roommates.test", []byte("other"))
+	if _, _, ok = other.ParseUnsubscribeToken(token); ok {
+		t.Error("token signed with another secret was accepted")
+	}
+}
+
+// deployments made before emails existed have none of the mail variables
+func TestNewWithoutMailEnv(t *testing.T) {
+	for _, key := range []string{"MAIL_FROM", "APP_URL", "MAIL_SECRET"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("SMTP_ADDR", "smtp.roommates.test:587")
+	t.Setenv("SERVER_ADDR", ":8080")
+	t.Setenv("MAIL_OUTBOX_DIR", t.TempDir())
+
+	m := New()
+	if _, ok := m.backend.(*FileBackend); !ok {
+		t.Errorf("backend is %T, want the file backend", m.backend)
+	}
+	if url := m.URL("/x"); url != "https://localhost:8080/x" {
+		t.Errorf("URL = %q", url)
+	}
+	if len(m.secret) == 0 {
+		t.Error("unsubscribe tokens are not signed")
+	}
+}
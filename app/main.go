@@ -2,12 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"roommates/accounts"
+	"roommates/broker"
+	"roommates/consumers"
 	"roommates/controller"
 	"roommates/db"
+	"roommates/db/dbqueries"
+	"roommates/events"
+	"roommates/images"
 	"roommates/logger"
+	"roommates/mailer"
+	"roommates/oidcauth"
 	"roommates/rdb"
+	"roommates/reminders"
 	"roommates/utils"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -34,7 +50,7 @@ var log = logger.Main
 //	@securityDefinitions.apikey  ApiKeyAuth
 //	@in                          header
 //	@name                        Authorization
-//	@description  Used for authentication of most of the access points
+//	@description  "Bearer " followed by the session token from sign-in or a personal access token from the profile page
 
 // @externalDocs.description  OpenAPI
 // @externalDocs.url          https://swagger.io/resources/open-api/
@@ -43,7 +59,10 @@ func main() {
 	serverAddr := utils.MustGetEnv("SERVER_ADDR")
 	dbURL := utils.GetDatabaseURL()
 
-	ctx := context.Background()
+	// canceled on shutdown, background workers stop with it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	dbpool, err := pgxpool.New(ctx, dbURL)
 	if err != nil {
 		panic(fmt.Errorf("unable to create connection pool: %w", err))
@@ -61,8 +80,54 @@ func main() {
 	db.MigrateToLatest(dbpool, migrationDir)
 
 	redisHandler := rdb.New()
-	controllers := controller.New(dbpool, redisHandler)
-
+	mail := mailer.New()
+	oidcProviders := oidcauth.New(ctx, nil, mail.URL(""), oidcauth.ConfigsFromEnv())
+	imageStore := images.New()
+	controllers := controller.New(dbpool, redisHandler, mail, oidcProviders, imageStore)
+	// also loads locales, which background workers need for emails
 	e := InitGinEngine(controllers)
-	e.RunTLS(serverAddr, "./certificates/server.pem", "./certificates/server.key")
+
+	eventBroker := broker.New()
+	defer eventBroker.Close()
+
+	var workers sync.WaitGroup
+	workers.Add(4)
+	go func() {
+		defer workers.Done()
+		events.NewRelay(dbpool, eventBroker).Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		events.NewRunner(eventBroker,
+			consumers.AuditLog(),
+			consumers.NotificationDispatch(dbqueries.New(dbpool), mail),
+			consumers.WebhookDispatch(dbqueries.New(dbpool)),
+		).Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		accounts.NewDeletionRunner(dbpool, redisHandler, imageStore).Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		reminders.NewDueRunner(dbpool).Run(ctx)
+	}()
+
+	server := &http.Server{Addr: serverAddr, Handler: e}
+	go func() {
+		err := server.ListenAndServeTLS("./certificates/server.pem", "./certificates/server.key")
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("server stopped unexpectedly")
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info().Msg("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("error shutting down server")
+	}
+	workers.Wait()
 }
@@ -2,22 +2,30 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"roommates/accesstokens"
 	"roommates/docs"
 	g "roommates/globals"
 	"roommates/rdb"
 	"roommates/utils"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/pkg/errors"
 )
 
+func isAPIRequest(ctx *gin.Context) bool {
+	return strings.HasPrefix(ctx.FullPath(), docs.SwaggerInfo.BasePath)
+}
+
 // will delete cookie, redirect to login (not on api endpoints) and abort future handlers
 func unauthorize(ctx *gin.Context) {
 	utils.DeleteCookie(ctx, g.CSessionToken)
 	// API requests should not redirect to login when unauthorized
-	if strings.HasPrefix(ctx.FullPath(), docs.SwaggerInfo.BasePath) {
+	if isAPIRequest(ctx) {
 		utils.ErrorResponse(ctx, http.StatusUnauthorized, errors.New("unauthorized access"))
 	} else {
 		ctx.Redirect(http.StatusSeeOther, "/login")
@@ -25,6 +33,77 @@ func unauthorize(ctx *gin.Context) {
 	ctx.Abort()
 }
 
+// sets the session cookie, also used to extend it when the session is used
+func SetSessionCookie(ctx *gin.Context, token string) {
+	ctx.SetCookie(
+		string(g.CSessionToken),
+		token,
+		int(rdb.EUserSession.Seconds()),
+		"/",
+		"localhost",
+		true,
+		true,
+	)
+}
+
+// keeps the session metadata up to date, only every rdb.SessionTouchInterval
+func touchSession(ctx *gin.Context, rh *rdb.RedisHandler, usv *rdb.UserSessionValue) {
+	if time.Since(usv.LastSeen) < rdb.SessionTouchInterval {
+		return
+	}
+	// failing to update the metadata is not a reason to fail the request
+	if err := rh.TouchUserSession(ctx, *usv, ctx.ClientIP(), ctx.Request.UserAgent()); err != nil {
+		return
+	}
+	if utils.GetAuthTokenFromCookie(ctx) == usv.Key {
+		SetSessionCookie(ctx, usv.Key)
+	}
+}
+
+// personal access tokens work only on the API and only from the Authorization header
+//
+// nil without error when the token does not exist
+func getAccessTokenUser(ctx *gin.Context, ah MiddlewareHandlers, token string) (*rdb.UserSessionValue, error) {
+	if !isAPIRequest(ctx) || utils.GetAuthTokenFromHeader(ctx) != token {
+		return nil, nil
+	}
+
+	db := ah.GetDB()
+	row, err := db.SelectAccessToken(ctx, accesstokens.Hash(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// same as sessions, last used is not updated on every request
+	if !row.LastUsedAt.Valid || time.Since(row.LastUsedAt.Time) >= rdb.SessionTouchInterval {
+		db.TouchAccessToken(ctx, row.ID)
+	}
+
+	return &rdb.UserSessionValue{
+		UserID:        row.UserID,
+		Username:      row.Username,
+		AccessTokenID: row.ID,
+		Scopes:        row.Scopes,
+	}, nil
+}
+
+// gets the user of a session key or a personal access token
+func getUser(ctx *gin.Context, ah MiddlewareHandlers, token string) (*rdb.UserSessionValue, error) {
+	if accesstokens.IsAccessToken(token) {
+		return getAccessTokenUser(ctx, ah, token)
+	}
+
+	rh := ah.GetRH()
+	usv, err := rh.GetUserSession(ctx, token)
+	if usv != nil {
+		touchSession(ctx, rh, usv)
+	}
+	return usv, err
+}
+
 // sets auth info into gin context
 func setAuthInfo(ctx *gin.Context, value *rdb.UserSessionValue) {
 	// ctx.Set(g.GAuth, value)
@@ -59,8 +138,7 @@ func NewAuthenticationMiddleware(ah MiddlewareHandlers, block bool) gin.HandlerF
 				return
 			}
 
-			rh := ah.GetRH()
-			usv, _ := rh.GetUserSession(ctx, token)
+			usv, _ := getUser(ctx, ah, token)
 			if usv == nil {
 				return
 			}
@@ -77,8 +155,7 @@ func NewAuthenticationMiddleware(ah MiddlewareHandlers, block bool) gin.HandlerF
 			return
 		}
 
-		rh := ah.GetRH()
-		usv, err := rh.GetUserSession(ctx, token)
+		usv, err := getUser(ctx, ah, token)
 		if usv == nil {
 			if err != nil {
 				utils.ServerErrorResponse(ctx, "unable to get session")
@@ -93,3 +170,16 @@ func NewAuthenticationMiddleware(ah MiddlewareHandlers, block bool) gin.HandlerF
 		ctx.Next()
 	}
 }
+
+// blocks personal access tokens without the scope, has to come after the authentication middleware
+func NewScopeMiddleware(scope accesstokens.Scope) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authInfo := GetAuthInfo(ctx)
+		if authInfo == nil || !authInfo.HasScope(string(scope)) {
+			utils.ErrorResponse(ctx, http.StatusForbidden, fmt.Errorf("token is missing the scope %s", scope))
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
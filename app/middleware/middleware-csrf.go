@@ -1,40 +1,71 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"roommates/globals"
 	"roommates/utils"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/csrf"
 	"github.com/pkg/errors"
 )
 
+// key to the gin context inside of the request context, gorilla/csrf only passes the request on
+type ginContextKey struct{}
+
+func ginContext(r *http.Request) *gin.Context {
+	return r.Context().Value(ginContextKey{}).(*gin.Context)
+}
+
 // takes auth key from env
-func NewCSRFMiddleware() gin.HandlerFunc {
+//
+// unsafe requests need the token either in the globals.Csrf form field or in the
+// globals.HXCSRFToken header, htmx sends the header set with hx-headers on <body>
+//
+//   - requests with a bearer token in Authorization header are not checked,
+//     browsers never add that header on their own
+//   - `exempt` are full paths (ctx.FullPath()) which are not checked, they have to be protected otherwise
+func NewCSRFMiddleware(exempt ...string) gin.HandlerFunc {
 	bAuthKey := []byte(utils.MustGetEnv("CSRF"))
 	if len(bAuthKey) != 32 {
 		panic("auth key should be 32 bytes")
 	}
 
-	// TODO: get this working, helpful links below
-	// - https://github.com/gorilla/csrf
-	// - https://stackoverflow.com/questions/69860669/how-set-middleware-gorillas-csrf-in-framework-gin-from-go
-	// - https://templ.guide/integrations/web-frameworks/#githubcomgorillacsrf
-	// - https://htmx.org/docs/#csrf-prevention
+	protect := csrf.Protect(
+		bAuthKey,
+		csrf.ErrorHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			ctx := ginContext(r)
+			reason := csrf.FailureReason(r)
+			if reason == nil {
+				reason = errors.New("CSRF token mismatch")
+			}
+			utils.ErrorResponse(ctx, http.StatusForbidden, reason)
+			ctx.Abort()
+		})),
+		csrf.FieldName(globals.Csrf),
+		csrf.RequestHeader(string(globals.HXCSRFToken)),
+		csrf.Path("/"),
+		csrf.SameSite(csrf.SameSiteLaxMode),
+	)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		ctx := ginContext(r)
+		// request now holds the token for the templates
+		ctx.Request = r
+		ctx.Next()
+	}))
 
 	return func(ctx *gin.Context) {
-		gorillaCSRF := csrf.Protect(
-			bAuthKey,
-			csrf.ErrorHandler(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
-				utils.ErrorResponse(ctx, http.StatusForbidden, errors.New("CSRF token mismatch"))
-				ctx.Abort()
-			})),
-			csrf.FieldName(globals.Csrf),
-		)
-
-		gorillaCSRF(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
-			ctx.Next()
-		})).ServeHTTP(ctx.Writer, ctx.Request)
+		r := ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), ginContextKey{}, ctx))
+		if utils.GetAuthTokenFromHeader(ctx) != "" || slices.Contains(exempt, ctx.FullPath()) {
+			r = csrf.UnsafeSkipCheck(r)
+		}
+		protect.ServeHTTP(ctx.Writer, r)
 	}
 }
+
+// masked token of the request, empty when the middleware was not used
+func CSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value("gorilla.csrf.Token").(string)
+	return token
+}
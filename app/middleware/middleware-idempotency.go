@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	g "roommates/globals"
+	"roommates/rdb"
+	"roommates/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// longest accepted Idempotency-Key, UUIDs and other random keys fit easily
+const maxIdempotencyKeyLength = 255
+
+// response headers replayed with the stored response
+var idempotentHeaders = []g.HttpHeader{g.HContentType, g.HETag}
+
+// keeps a copy of what the handler writes
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// same method, path and body give the same fingerprint
+func requestFingerprint(ctx *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(ctx.Request.Method + " " + ctx.Request.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replayResponse(ctx *gin.Context, stored *rdb.IdempotentResponse) {
+	for name, value := range stored.Header {
+		ctx.Header(name, value)
+	}
+	ctx.Header(string(g.HIdempotentReplayed), "true")
+	ctx.Status(stored.Status)
+	ctx.Writer.Write(stored.Body)
+	ctx.Abort()
+}
+
+// makes retries of a request with the same Idempotency-Key header safe,
+// the first response is stored and replayed to the retries for rdb.EIdempotency
+//
+//   - requests without the header are handled as usual
+//   - a key reused for a different request is rejected with 422
+//   - a retry while the first request is still being handled gets 409
+//   - server errors are not stored, the request can be retried with the same key
+//
+// has to come after the authentication middleware, keys are per user.
+// requests are let through when redis is not available
+func NewIdempotencyMiddleware(ah MiddlewareHandlers) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(string(g.HIdempotencyKey))
+		authInfo := GetAuthInfo(ctx)
+		if key == "" || authInfo == nil {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, g.ErrorInvalidIdempotencyKey)
+			ctx.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, err)
+			ctx.Abort()
+			return
+		}
+		// handler has to be able to read the body again
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		rh := ah.GetRH()
+		userID := authInfo.UserID.String()
+		fingerprint := requestFingerprint(ctx, body)
+		stored, err := rh.ClaimIdempotencyKey(ctx, userID, key, fingerprint)
+		if err != nil {
+			ctx.Next()
+			return
+		}
+		if stored != nil {
+			switch {
+			case stored.Fingerprint != fingerprint:
+				utils.ErrorResponse(ctx, http.StatusUnprocessableEntity, g.ErrorIdempotencyKeyReused)
+				ctx.Abort()
+			case stored.IsPending():
+				utils.ErrorResponse(ctx, http.StatusConflict, g.ErrorIdempotencyKeyInProgress)
+				ctx.Abort()
+			default:
+				replayResponse(ctx, stored)
+			}
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			rh.ReleaseIdempotencyKey(ctx, userID, key)
+			return
+		}
+		response := rdb.IdempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      status,
+			Header:      map[string]string{},
+			Body:        writer.body.Bytes(),
+		}
+		for _, name := range idempotentHeaders {
+			if value := writer.Header().Get(string(name)); value != "" {
+				response.Header[string(name)] = value
+			}
+		}
+		if err = rh.SaveIdempotentResponse(ctx, userID, key, response); err != nil {
+			rh.ReleaseIdempotencyKey(ctx, userID, key)
+		}
+	}
+}
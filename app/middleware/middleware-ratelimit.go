@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	g "roommates/globals"
+	"roommates/rdb"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// decides what the request is counted against, empty key skips the limit
+type RateLimitKey func(ctx *gin.Context) string
+
+// client ip
+func RLKeyIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// signed in user, falls back to the client ip
+func RLKeyUser(ctx *gin.Context) string {
+	if authInfo := GetAuthInfo(ctx); authInfo != nil {
+		return "user:" + authInfo.UserID.String()
+	}
+	return RLKeyIP(ctx)
+}
+
+// email of the account the request is about, taken from the form or JSON body
+func RLKeyAccount(ctx *gin.Context) string {
+	var email string
+	if ctx.ContentType() == binding.MIMEJSON {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			return ""
+		}
+		// handler has to be able to read the body again
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var req struct {
+			Email string `json:"email"`
+		}
+		json.Unmarshal(body, &req)
+		email = req.Email
+	} else {
+		email = ctx.PostForm("email")
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ""
+	}
+	return "account:" + email
+}
+
+// sets the Retry-After header in whole seconds
+func SetRetryAfter(ctx *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	ctx.Header(string(g.HRetryAfter), strconv.Itoa(seconds))
+}
+
+// stops requests over the limit, the response is left to MiddlewareHandlers.RateLimited
+//
+// requests are let through when redis is not available, it should not lock everyone out
+func NewRateLimitMiddleware(ah MiddlewareHandlers, limit rdb.RateLimit, key RateLimitKey) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		k := key(ctx)
+		if k == "" {
+			ctx.Next()
+			return
+		}
+
+		wait, err := ah.GetRH().Allow(ctx, limit, k)
+		if err != nil || wait == 0 {
+			ctx.Next()
+			return
+		}
+
+		SetRetryAfter(ctx, wait)
+		ah.RateLimited(ctx, wait)
+		ctx.Abort()
+	}
+}
@@ -3,9 +3,14 @@ package middleware
 import (
 	"roommates/db/dbqueries"
 	"roommates/rdb"
+	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 type MiddlewareHandlers interface {
 	GetDB() *dbqueries.Queries
 	GetRH() *rdb.RedisHandler
+	// responds to a request stopped by the rate limiter, Retry-After is already set
+	RateLimited(ctx *gin.Context, wait time.Duration)
 }
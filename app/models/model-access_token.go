@@ -0,0 +1,69 @@
+package models
+
+import (
+	"roommates/accesstokens"
+	l "roommates/locales"
+	"strings"
+	"unicode/utf8"
+)
+
+const AccessTokenNameMaxLength = 64
+
+// form for creating a personal access token
+type AccessToken struct {
+	ModelBase
+	Name   string   `form:"name"`
+	Scopes []string `form:"scopes[]"`
+}
+
+func (m *AccessToken) ValidateName() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	name := strings.TrimSpace(m.Name)
+	if name == "" {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsNameErrorEmpty})
+	} else if utf8.RuneCountInString(name) > AccessTokenNameMaxLength {
+		msgs = append(msgs, l.LKMessage{Key: l.LKAccessTokensErrorNameLength, Args: []any{AccessTokenNameMaxLength}})
+	}
+	return msgs
+}
+
+func (m *AccessToken) ValidateScopes() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if len(m.Scopes) == 0 {
+		msgs = append(msgs, l.LKMessage{Key: l.LKAccessTokensErrorNoScopes})
+		return msgs
+	}
+	for _, scope := range m.Scopes {
+		if !accesstokens.IsScope(scope) {
+			msgs = append(msgs, l.LKMessage{Key: l.LKAccessTokensErrorNoScopes})
+			break
+		}
+	}
+	return msgs
+}
+
+func (m *AccessToken) GetValidators() []Validator {
+	return []Validator{
+		m.ValidateName,
+		m.ValidateScopes,
+	}
+}
+
+func (m *AccessToken) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *AccessToken) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
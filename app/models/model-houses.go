@@ -3,6 +3,7 @@ package models
 import (
 	l "roommates/locales"
 	"roommates/utils"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -76,6 +77,38 @@ func (m *House) FilterNonValidUUID(ctx *gin.Context) (bool, []pgtype.UUID) {
 	return hasInvalidUUID, roomateIDs
 }
 
+// removes roommates who can not be added to the house, e.g. they have blocked the user adding them
+//
+// if some roommates were removed then error will be set using the i18n with request context
+//
+//	if bool == true -- some roommates were removed
+func (m *House) RemoveRoommates(ctx *gin.Context, ids []pgtype.UUID) bool {
+	if len(ids) == 0 {
+		return false
+	}
+	removed := false
+
+	i := 0
+	for i < len(m.RoommateKeys) {
+		if slices.ContainsFunc(ids, func(id pgtype.UUID) bool { return id.String() == m.RoommateKeys[i] }) {
+			removed = true
+			m.RoommateKeys = append(m.RoommateKeys[:i], m.RoommateKeys[i+1:]...)
+			m.RoommateLabels = append(m.RoommateLabels[:i], m.RoommateLabels[i+1:]...)
+			continue
+		}
+		i++
+	}
+
+	if removed {
+		m.Error = utils.T(
+			ctx.Request.Context(),
+			l.LKFormsHouseErrorSomeRoommatesBlocked,
+			"",
+		)
+	}
+	return removed
+}
+
 func (m *House) GetValidators() []Validator {
 	return []Validator{
 		m.ValidateName,
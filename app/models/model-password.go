@@ -0,0 +1,123 @@
+package models
+
+import (
+	l "roommates/locales"
+)
+
+func validatePasswordMatch(password, password2 string) (msgs []l.LKMessage) {
+	if password != password2 {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsPasswordErrorMustMatch})
+	}
+	return msgs
+}
+
+// asks for a password reset link, only the email is used
+type ForgotPassword struct {
+	Login
+	// link was asked for, shown regardless of the account existing
+	Sent bool
+}
+
+func (m *ForgotPassword) GetValidators() []Validator {
+	return []Validator{
+		m.ValidateEmail,
+	}
+}
+
+func (m *ForgotPassword) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *ForgotPassword) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
+
+// sets a new password with the token from the reset link
+type ResetPassword struct {
+	Login
+	Password2 string `form:"password_2"`
+	// non-visual
+	Token string
+}
+
+// validates if both passwords match
+func (m *ResetPassword) ValidatePasswordMatch() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+	return validatePasswordMatch(m.Password, m.Password2)
+}
+
+func (m *ResetPassword) GetValidators() []Validator {
+	return []Validator{
+		m.ValidatePassword,
+		m.ValidatePasswordMatch,
+	}
+}
+
+func (m *ResetPassword) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *ResetPassword) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
+
+// changes the password of a signed in user, Password is the new password
+type ChangePassword struct {
+	Login
+	CurrentPassword string `form:"current_password"`
+	Password2       string `form:"password_2"`
+	// password has been changed
+	Changed bool
+}
+
+func (m *ChangePassword) ValidateCurrentPassword() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if m.CurrentPassword == "" {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsPasswordErrorCurrentEmpty})
+	}
+	return msgs
+}
+
+// validates if both passwords match
+func (m *ChangePassword) ValidatePasswordMatch() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+	return validatePasswordMatch(m.Password, m.Password2)
+}
+
+func (m *ChangePassword) GetValidators() []Validator {
+	return []Validator{
+		m.ValidateCurrentPassword,
+		m.ValidatePassword,
+		m.ValidatePasswordMatch,
+	}
+}
+
+func (m *ChangePassword) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *ChangePassword) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
@@ -1 +1,112 @@
 package models
+
+import (
+	"regexp"
+	l "roommates/locales"
+	"roommates/utils"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// up to 2 decimals, both . and , are accepted as separator
+var rgxAmount = regexp.MustCompile(`^\d{1,10}([.,]\d{1,2})?$`)
+
+type Payment struct {
+	ModelBase
+	// not visual
+	HouseID   string `form:"house_id"`
+	HouseName string `form:"house_name"`
+
+	Name   string `form:"name"`
+	Amount string `form:"amount"`
+	// users who have to pay their share
+	PayerIDs []string `form:"payers[]"`
+	// optional, DateLayout
+	DueDate string `form:"due_date"`
+}
+
+func (m *Payment) ValidateName() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if m.Name == "" {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsNameErrorEmpty})
+		return msgs
+	}
+
+	charProblems := utils.ValidateString(m.Name, utils.RuneValidationRules{
+		LettersAllowed:       true,
+		DigitsAllowed:        true,
+		MaxConsecutiveSpaces: 1,
+	})
+	msgs = append(msgs, StringValidationMessages(charProblems)...)
+	return msgs
+}
+
+func (m *Payment) ValidateAmount() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if !rgxAmount.MatchString(m.Amount) {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsPaymentErrorAmount})
+	}
+	return msgs
+}
+
+func (m *Payment) ValidatePayers() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if len(m.PayerIDs) == 0 {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsPaymentErrorNoPayers})
+	}
+	return msgs
+}
+
+func (m *Payment) ValidateDueDate() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+	return ValidateDate(m.DueDate)
+}
+
+// amount in a form that postgres understands
+//
+//	NB: expects the amount to be valid
+func (m *Payment) GetAmount() pgtype.Numeric {
+	var amount pgtype.Numeric
+	amount.Scan(strings.Replace(m.Amount, ",", ".", 1))
+	return amount
+}
+
+//	NB: expects the due date to be valid
+func (m *Payment) GetDueDate() pgtype.Date {
+	date, _ := ParseDate(m.DueDate)
+	return date
+}
+
+func (m *Payment) GetValidators() []Validator {
+	return []Validator{
+		m.ValidateName,
+		m.ValidateAmount,
+		m.ValidatePayers,
+		m.ValidateDueDate,
+	}
+}
+
+func (m *Payment) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *Payment) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
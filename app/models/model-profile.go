@@ -0,0 +1,228 @@
+package models
+
+import (
+	"encoding/json"
+	l "roommates/locales"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	FullNameMaxLength      = 100
+	ContactHandleMaxLength = 64
+)
+
+func validateUsername(username string) (msgs []l.LKMessage) {
+	if username != strings.TrimSpace(username) {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsUsernameErrorSpaces})
+	}
+
+	const minLength = 3
+	if len(username) < minLength {
+		msgs = append(msgs, l.LKMessage{
+			Key:  l.LKFormsUsernameErrorLength,
+			Args: []any{minLength},
+		})
+	}
+	return msgs
+}
+
+// ways housemates can reach the user, stored as JSONB in user_contact_information
+//
+// every method has its own switch for being visible to housemates, nothing is shown to others
+type ContactInformation struct {
+	Phone             string `form:"phone" json:"phone,omitempty"`
+	IsPhoneVisible    bool   `form:"is_phone_visible" json:"is_phone_visible,omitempty"`
+	IBAN              string `form:"iban" json:"iban,omitempty"`
+	IsIBANVisible     bool   `form:"is_iban_visible" json:"is_iban_visible,omitempty"`
+	Telegram          string `form:"telegram" json:"telegram,omitempty"`
+	IsTelegramVisible bool   `form:"is_telegram_visible" json:"is_telegram_visible,omitempty"`
+	Signal            string `form:"signal" json:"signal,omitempty"`
+	IsSignalVisible   bool   `form:"is_signal_visible" json:"is_signal_visible,omitempty"`
+}
+
+// empty when `data` is not valid, the column can be NULL
+func ParseContactInformation(data []byte) ContactInformation {
+	var ci ContactInformation
+	if len(data) > 0 {
+		json.Unmarshal(data, &ci)
+	}
+	return ci
+}
+
+// only the methods the user has made visible to housemates
+func (ci ContactInformation) Visible() ContactInformation {
+	var visible ContactInformation
+	if ci.IsPhoneVisible {
+		visible.Phone, visible.IsPhoneVisible = ci.Phone, true
+	}
+	if ci.IsIBANVisible {
+		visible.IBAN, visible.IsIBANVisible = ci.IBAN, true
+	}
+	if ci.IsTelegramVisible {
+		visible.Telegram, visible.IsTelegramVisible = ci.Telegram, true
+	}
+	if ci.IsSignalVisible {
+		visible.Signal, visible.IsSignalVisible = ci.Signal, true
+	}
+	return visible
+}
+
+func (ci ContactInformation) IsEmpty() bool {
+	return ci.Phone == "" && ci.IBAN == "" && ci.Telegram == "" && ci.Signal == ""
+}
+
+// trims the values and writes IBAN in the electronic format, "EE38 2200..." -> "EE382200..."
+func (ci ContactInformation) Normalized() ContactInformation {
+	ci.Phone = strings.TrimSpace(ci.Phone)
+	ci.IBAN = normalizeIBAN(ci.IBAN)
+	ci.Telegram = strings.TrimPrefix(strings.TrimSpace(ci.Telegram), "@")
+	ci.Signal = strings.TrimSpace(ci.Signal)
+	return ci
+}
+
+func normalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// https://en.wikipedia.org/wiki/International_Bank_Account_Number#Validating_the_IBAN
+func isValidIBAN(iban string) bool {
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	for i, r := range iban {
+		switch {
+		case i < 2 && (r < 'A' || r > 'Z'):
+			return false
+		case i >= 2 && i < 4 && (r < '0' || r > '9'):
+			return false
+		case (r < 'A' || r > 'Z') && (r < '0' || r > '9'):
+			return false
+		}
+	}
+
+	// first four characters are moved to the end, letters become numbers A=10..Z=35
+	remainder := 0
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' {
+			remainder = (remainder*100 + int(r-'A'+10)) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+	return remainder == 1
+}
+
+// allows the usual ways of writing a number, "+372 5555 5555", "(555) 555-5555"
+func isValidPhone(phone string) bool {
+	digits := 0
+	for _, r := range phone {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case r == '+' || r == ' ' || r == '-' || r == '(' || r == ')':
+		default:
+			return false
+		}
+	}
+	return digits >= 5 && digits <= 15
+}
+
+// edits the profile of a signed in user, username is validated the same way as in Register
+type Profile struct {
+	ModelBase
+	Username         string `form:"username"`
+	FullName         string `form:"full_name"`
+	IsFullNamePublic bool   `form:"is_full_name_public"`
+	ContactInformation
+	// profile has been saved
+	Saved bool
+}
+
+func (m *Profile) ValidateUsername() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+	return validateUsername(m.Username)
+}
+
+func (m *Profile) ValidateFullName() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if utf8.RuneCountInString(strings.TrimSpace(m.FullName)) > FullNameMaxLength {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsFullNameErrorLength, Args: []any{FullNameMaxLength}})
+	}
+	return msgs
+}
+
+func (m *Profile) ValidatePhone() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	phone := strings.TrimSpace(m.Phone)
+	if phone != "" && !isValidPhone(phone) {
+		msgs = append(msgs, l.LKMessage{Key: l.LKProfileErrorPhone})
+	}
+	return msgs
+}
+
+func (m *Profile) ValidateIBAN() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	iban := normalizeIBAN(m.IBAN)
+	if iban != "" && !isValidIBAN(iban) {
+		msgs = append(msgs, l.LKMessage{Key: l.LKProfileErrorIban})
+	}
+	return msgs
+}
+
+func (m *Profile) ValidateTelegram() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+	return validateContactHandle(strings.TrimSpace(m.Telegram))
+}
+
+func (m *Profile) ValidateSignal() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+	return validateContactHandle(strings.TrimSpace(m.Signal))
+}
+
+func validateContactHandle(handle string) (msgs []l.LKMessage) {
+	if strings.ContainsFunc(handle, unicode.IsSpace) || utf8.RuneCountInString(handle) > ContactHandleMaxLength {
+		msgs = append(msgs, l.LKMessage{Key: l.LKProfileErrorHandle, Args: []any{ContactHandleMaxLength}})
+	}
+	return msgs
+}
+
+func (m *Profile) GetValidators() []Validator {
+	return []Validator{
+		m.ValidateUsername,
+		m.ValidateFullName,
+		m.ValidatePhone,
+		m.ValidateIBAN,
+		m.ValidateTelegram,
+		m.ValidateSignal,
+	}
+}
+
+func (m *Profile) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *Profile) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
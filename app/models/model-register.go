@@ -2,7 +2,6 @@ package models
 
 import (
 	l "roommates/locales"
-	"strings"
 )
 
 type Register struct {
@@ -17,20 +16,7 @@ func (m *Register) ValidateUsername() (msgs []l.LKMessage) {
 	if m.Initial {
 		return
 	}
-
-	if m.Username != strings.TrimSpace(m.Username) {
-		msgs = append(msgs, l.LKMessage{Key: l.LKFormsUsernameErrorSpaces})
-	}
-
-	const minLength = 3
-	if len(m.Username) < minLength {
-		msgs = append(msgs, l.LKMessage{
-			Key:  l.LKFormsUsernameErrorLength,
-			Args: []any{minLength},
-		})
-	}
-
-	return msgs
+	return validateUsername(m.Username)
 }
 
 // validates if both passwords match
@@ -39,11 +25,7 @@ func (m *Register) ValidatePasswordMatch() (msgs []l.LKMessage) {
 		return
 	}
 
-	if m.Password != m.Password2 {
-		msgs = append(msgs, l.LKMessage{Key: l.LKFormsPasswordErrorMustMatch})
-	}
-
-	return msgs
+	return validatePasswordMatch(m.Password, m.Password2)
 }
 
 func (m *Register) GetValidators() []Validator {
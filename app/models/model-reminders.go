@@ -0,0 +1,118 @@
+package models
+
+import (
+	"encoding/json"
+	"roommates/db/dbqueries"
+	l "roommates/locales"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// in the order they are shown
+var ReminderRecurrences = []dbqueries.ReminderRecurrence{
+	dbqueries.ReminderRecurrenceNone,
+	dbqueries.ReminderRecurrenceDaily,
+	dbqueries.ReminderRecurrenceWeekly,
+	dbqueries.ReminderRecurrenceMonthly,
+}
+
+// what is stored in house_reminders.content
+type ReminderContent struct {
+	Title string `json:"title"`
+}
+
+type Reminder struct {
+	ModelBase
+	// not visual
+	HouseID string `form:"house_id"`
+
+	Title string `form:"title"`
+	// optional, DateLayout
+	DueDate string `form:"due_date"`
+	// empty is the same as none
+	Recurrence string `form:"recurrence"`
+	// resident the reminder is for, empty when it is for the whole house
+	AssigneeID string `form:"assignee_id"`
+}
+
+func (m *Reminder) ValidateTitle() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if m.Title == "" {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsNameErrorEmpty})
+	}
+	return msgs
+}
+
+func (m *Reminder) ValidateDueDate() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+	return ValidateDate(m.DueDate)
+}
+
+func (m *Reminder) ValidateRecurrence() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	recurrence := m.GetRecurrence()
+	if !slices.Contains(ReminderRecurrences, recurrence) {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsReminderErrorRecurrence})
+	} else if recurrence != dbqueries.ReminderRecurrenceNone && m.DueDate == "" {
+		// the next due date is counted from the previous one
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsReminderErrorRecurrenceNoDate})
+	}
+	return msgs
+}
+
+//	NB: expects the due date to be valid
+func (m *Reminder) GetDueDate() pgtype.Date {
+	date, _ := ParseDate(m.DueDate)
+	return date
+}
+
+func (m *Reminder) GetRecurrence() dbqueries.ReminderRecurrence {
+	if m.Recurrence == "" {
+		return dbqueries.ReminderRecurrenceNone
+	}
+	return dbqueries.ReminderRecurrence(m.Recurrence)
+}
+
+// content to be stored in the database
+func (m *Reminder) GetContent() []byte {
+	// marshalling a struct of strings can not fail
+	content, _ := json.Marshal(ReminderContent{Title: m.Title})
+	return content
+}
+
+func (m *Reminder) GetValidators() []Validator {
+	return []Validator{
+		m.ValidateTitle,
+		m.ValidateDueDate,
+		m.ValidateRecurrence,
+	}
+}
+
+func (m *Reminder) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *Reminder) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
+
+// will ignore the error, invalid content results in empty reminder
+func ParseReminderContent(data []byte) ReminderContent {
+	var content ReminderContent
+	json.Unmarshal(data, &content)
+	return content
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	l "roommates/locales"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ShoppingItemNameMaxLength = 100
+	ShoppingQuantityMaxLength = 30
+	ShoppingCategoryOther     = "other"
+)
+
+// in the order they are shown, items are grouped by them
+var ShoppingCategories = []string{
+	"produce",
+	"dairy",
+	"meat",
+	"bakery",
+	"pantry",
+	"frozen",
+	"drinks",
+	"household",
+	ShoppingCategoryOther,
+}
+
+// form for adding an item to the shopping list of a house
+type ShoppingItem struct {
+	ModelBase
+	Name string `form:"name"`
+	// free text, "2 kg"
+	Quantity string `form:"quantity"`
+	// empty is the same as other
+	Category string `form:"category"`
+}
+
+func (m *ShoppingItem) ValidateName() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	value := strings.TrimSpace(m.Name)
+	if value == "" {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsNameErrorEmpty})
+	} else if utf8.RuneCountInString(value) > ShoppingItemNameMaxLength {
+		msgs = append(msgs, l.LKMessage{Key: l.LKShoppingErrorNameLength, Args: []any{ShoppingItemNameMaxLength}})
+	}
+	return msgs
+}
+
+func (m *ShoppingItem) ValidateQuantity() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if utf8.RuneCountInString(strings.TrimSpace(m.Quantity)) > ShoppingQuantityMaxLength {
+		msgs = append(msgs, l.LKMessage{Key: l.LKShoppingErrorQuantityLength, Args: []any{ShoppingQuantityMaxLength}})
+	}
+	return msgs
+}
+
+func (m *ShoppingItem) ValidateCategory() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if !slices.Contains(ShoppingCategories, m.GetCategory()) {
+		msgs = append(msgs, l.LKMessage{Key: l.LKShoppingErrorCategory})
+	}
+	return msgs
+}
+
+func (m *ShoppingItem) GetCategory() string {
+	if m.Category == "" {
+		return ShoppingCategoryOther
+	}
+	return m.Category
+}
+
+func (m *ShoppingItem) GetValidators() []Validator {
+	return []Validator{
+		m.ValidateName,
+		m.ValidateQuantity,
+		m.ValidateCategory,
+	}
+}
+
+func (m *ShoppingItem) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *ShoppingItem) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
+
+// form of "I bought these", the items the user checked off leave the list
+// and can be added as a payment of the house with the user as the requester
+type ShoppingPurchase struct {
+	Payment
+	ItemIDs []string `form:"items[]"`
+	// the payment is made only when this is set, the fields of it are ignored otherwise
+	AsPayment bool `form:"as_payment"`
+}
+
+func (m *ShoppingPurchase) ValidateItems() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if len(m.GetItemIDs()) == 0 {
+		msgs = append(msgs, l.LKMessage{Key: l.LKShoppingErrorNoItems})
+	}
+	return msgs
+}
+
+// ids of the items, the ones which are not numbers are left out
+func (m *ShoppingPurchase) GetItemIDs() []int64 {
+	var ids []int64
+	for _, value := range m.ItemIDs {
+		if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (m *ShoppingPurchase) GetValidators() []Validator {
+	validators := []Validator{m.ValidateItems}
+	if m.AsPayment {
+		validators = append(validators, m.Payment.GetValidators()...)
+	}
+	return validators
+}
+
+func (m *ShoppingPurchase) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *ShoppingPurchase) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
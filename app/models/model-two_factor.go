@@ -0,0 +1,42 @@
+package models
+
+import (
+	l "roommates/locales"
+	"strings"
+)
+
+// code from the authenticator app or a recovery code
+type TwoFactorCode struct {
+	ModelBase
+	Code string `form:"code"`
+}
+
+func (m *TwoFactorCode) ValidateCode() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if strings.TrimSpace(m.Code) == "" {
+		msgs = append(msgs, l.LKMessage{Key: l.LKTwoFactorErrorEmpty})
+	}
+	return msgs
+}
+
+func (m *TwoFactorCode) GetValidators() []Validator {
+	return []Validator{
+		m.ValidateCode,
+	}
+}
+
+func (m *TwoFactorCode) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *TwoFactorCode) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"net/url"
+	l "roommates/locales"
+	"roommates/webhooks"
+	"strings"
+)
+
+const WebhookURLMaxLength = 2048
+
+// form for registering a house webhook
+type Webhook struct {
+	ModelBase
+	URL        string   `form:"url"`
+	EventTypes []string `form:"event_types[]"`
+}
+
+func (m *Webhook) ValidateURL() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	value := strings.TrimSpace(m.URL)
+	if value == "" {
+		msgs = append(msgs, l.LKMessage{Key: l.LKWebhooksErrorUrlEmpty})
+		return msgs
+	}
+	if len(value) > WebhookURLMaxLength {
+		msgs = append(msgs, l.LKMessage{Key: l.LKWebhooksErrorUrlLength, Args: []any{WebhookURLMaxLength}})
+		return msgs
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		msgs = append(msgs, l.LKMessage{Key: l.LKWebhooksErrorUrlInvalid})
+		return msgs
+	}
+	switch webhooks.CheckURL(u) {
+	case webhooks.ErrorInsecureURL:
+		msgs = append(msgs, l.LKMessage{Key: l.LKWebhooksErrorUrlHttps})
+	case webhooks.ErrorPrivateAddress:
+		msgs = append(msgs, l.LKMessage{Key: l.LKWebhooksErrorUrlPrivate})
+	}
+	return msgs
+}
+
+func (m *Webhook) ValidateEventTypes() (msgs []l.LKMessage) {
+	if m.Initial {
+		return
+	}
+
+	if len(m.EventTypes) == 0 {
+		msgs = append(msgs, l.LKMessage{Key: l.LKWebhooksErrorNoEvents})
+		return msgs
+	}
+	for _, t := range m.EventTypes {
+		if !webhooks.IsEventType(t) {
+			msgs = append(msgs, l.LKMessage{Key: l.LKWebhooksErrorNoEvents})
+			break
+		}
+	}
+	return msgs
+}
+
+func (m *Webhook) GetValidators() []Validator {
+	return []Validator{
+		m.ValidateURL,
+		m.ValidateEventTypes,
+	}
+}
+
+func (m *Webhook) Validate() []l.LKMessage {
+	if m.Initial {
+		return nil
+	}
+	return ValidateModel(m)
+}
+
+// checks if the form is valid and sets the Initial to false
+func (m *Webhook) IsValid() (bool, []l.LKMessage) {
+	m.Initial = false
+	return IsModelValid(m)
+}
@@ -3,6 +3,9 @@ package models
 import (
 	l "roommates/locales"
 	"roommates/utils"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type ModelBase struct {
@@ -52,3 +55,33 @@ func StringValidationMessages(validationMap map[utils.StringValidatorProblems]bo
 	}
 	return msgs
 }
+
+// dates in forms, the same as the value of <input type="date">
+const DateLayout = "2006-01-02"
+
+// empty value is no date
+func ParseDate(value string) (pgtype.Date, error) {
+	if value == "" {
+		return pgtype.Date{}, nil
+	}
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return pgtype.Date{}, err
+	}
+	return pgtype.Date{Time: t, Valid: true}, nil
+}
+
+// date in the form of DateLayout, "" when there is no date
+func FormatDate(date pgtype.Date) string {
+	if !date.Valid {
+		return ""
+	}
+	return date.Time.Format(DateLayout)
+}
+
+func ValidateDate(value string) (msgs []l.LKMessage) {
+	if _, err := ParseDate(value); err != nil {
+		msgs = append(msgs, l.LKMessage{Key: l.LKFormsDueDateErrorInvalid})
+	}
+	return msgs
+}
@@ -0,0 +1,85 @@
+// in-app notifications, other features call Notify when a user should know about something
+package notifications
+
+import (
+	"context"
+	"roommates/db/dbqueries"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// kind of the notification, users can turn off kinds they do not care about
+type Kind string
+
+const (
+	HouseInvite     Kind = "house-invite"
+	PaymentAssigned Kind = "payment-assigned"
+	ReminderDue     Kind = "reminder-due"
+	NoteChanged     Kind = "note-changed"
+	ChatMention     Kind = "chat-mention"
+)
+
+// in the order they are shown in the preferences, only kinds that are sent
+var Kinds = []Kind{
+	HouseInvite,
+	PaymentAssigned,
+	ReminderDue,
+	NoteChanged,
+	ChatMention,
+}
+
+type Notification struct {
+	// recipient
+	UserID pgtype.UUID
+	Kind   Kind
+	// name of the thing the notification is about
+	Subject string
+	// where the user is taken when opening the notification
+	Link    string
+	HouseID pgtype.UUID
+	// user who caused the notification
+	ActorID pgtype.UUID
+	// house event the notification was made from, 0 when there is none
+	//
+	// used to avoid duplicates when the same event is handled more than once
+	EventID int64
+}
+
+// stores the notification, unless the recipient has turned off the kind
+//
+// returns false when the notification was not stored,
+// either because of the preferences or because it already exists
+func Notify(ctx context.Context, q *dbqueries.Queries, n Notification) (bool, error) {
+	var eventID *int64
+	if n.EventID != 0 {
+		eventID = &n.EventID
+	}
+
+	stored, err := q.InsertNotification(ctx, dbqueries.InsertNotificationParams{
+		UserID:  n.UserID,
+		Kind:    string(n.Kind),
+		Subject: n.Subject,
+		Link:    n.Link,
+		HouseID: n.HouseID,
+		ActorID: n.ActorID,
+		EventID: eventID,
+	})
+	return stored > 0, err
+}
+
+// every kind with whether it is enabled, kinds without a stored preference are enabled
+func Preferences(ctx context.Context, q *dbqueries.Queries, userID pgtype.UUID) (map[Kind]bool, error) {
+	rows, err := q.SelectNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := map[Kind]bool{}
+	for _, kind := range Kinds {
+		preferences[kind] = true
+	}
+	for _, row := range rows {
+		preferences[Kind(row.Kind)] = row.Enabled
+	}
+	return preferences, nil
+}
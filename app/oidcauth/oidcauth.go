@@ -0,0 +1,200 @@
+// sign-in with OpenID Connect providers, using discovery, PKCE and the state/nonce checks
+//
+// providers are configured with environment variables:
+//
+//	OIDC_PROVIDERS=google,gitlab
+//	OIDC_GOOGLE_ISSUER=https://accounts.google.com
+//	OIDC_GOOGLE_CLIENT_ID=...
+//	OIDC_GOOGLE_CLIENT_SECRET=...
+//	OIDC_GOOGLE_DISPLAY_NAME=Google (optional)
+package oidcauth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	g "roommates/globals"
+	"roommates/logger"
+	"roommates/utils"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var log = logger.OIDCLoggger
+
+var (
+	ErrorNoIDToken     = errors.New("no id_token in the token response")
+	ErrorNonceMismatch = errors.New("nonce does not match")
+)
+
+type ProviderConfig struct {
+	// used in urls and stored with the identities, should never change
+	Name         string
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+}
+
+// reads the providers from the environment, see the package description
+func ConfigsFromEnv() []ProviderConfig {
+	var configs []ProviderConfig
+	for name := range strings.SplitSeq(utils.GetEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		configs = append(configs, ProviderConfig{
+			Name:         name,
+			DisplayName:  utils.GetEnv(prefix+"DISPLAY_NAME", name),
+			Issuer:       utils.MustGetEnv(prefix + "ISSUER"),
+			ClientID:     utils.MustGetEnv(prefix + "CLIENT_ID"),
+			ClientSecret: utils.GetEnv(prefix+"CLIENT_SECRET", ""),
+		})
+	}
+	return configs
+}
+
+type Provider struct {
+	Name        string
+	DisplayName string
+
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	client   *http.Client
+}
+
+// identity as told by the provider
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	// suggestion for the username when a new user is made
+	Username string
+}
+
+// things to remember between sending the user to the provider and the callback,
+// the state is kept by the caller
+type AuthRequest struct {
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+func NewAuthRequest() AuthRequest {
+	return AuthRequest{
+		Nonce:    rand.Text(),
+		Verifier: oauth2.GenerateVerifier(),
+	}
+}
+
+// url the user is sent to for signing in, the provider sends `state` back to the callback
+func (p *Provider) AuthURL(state string, req AuthRequest) string {
+	return p.oauth.AuthCodeURL(state,
+		oidc.Nonce(req.Nonce),
+		oauth2.S256ChallengeOption(req.Verifier),
+	)
+}
+
+// exchanges the code from the callback and verifies the id token, the state has to be checked before
+func (p *Provider) Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error) {
+	ctx = oidc.ClientContext(ctx, p.client)
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(req.Verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, ErrorNoIDToken
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if idToken.Nonce != req.Nonce {
+		return nil, ErrorNonceMismatch
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+	if err = idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	username := claims.PreferredUsername
+	if username == "" {
+		username = claims.Name
+	}
+	return &Identity{
+		Provider:      p.Name,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Username:      username,
+	}, nil
+}
+
+// configured providers, nil has none
+type Providers struct {
+	list []*Provider
+}
+
+// discovers the providers, ones that fail are left out
+//
+//	client -- used for discovery and talking to the providers, http.DefaultClient when nil
+//	appURL -- redirect urls are made from it
+func New(ctx context.Context, client *http.Client, appURL string, configs []ProviderConfig) *Providers {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx = oidc.ClientContext(ctx, client)
+
+	providers := &Providers{}
+	for _, config := range configs {
+		provider, err := oidc.NewProvider(ctx, config.Issuer)
+		if err != nil {
+			log.Error().Err(err).Str("provider", config.Name).Msg("OIDC discovery failed")
+			continue
+		}
+		providers.list = append(providers.list, &Provider{
+			Name:        config.Name,
+			DisplayName: config.DisplayName,
+			oauth: oauth2.Config{
+				ClientID:     config.ClientID,
+				ClientSecret: config.ClientSecret,
+				Endpoint:     provider.Endpoint(),
+				RedirectURL:  strings.TrimSuffix(appURL, "/") + utils.ReplaceParam(g.RLoginOIDCCallback, "provider", config.Name),
+				Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+			},
+			verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+			client:   client,
+		})
+	}
+	return providers
+}
+
+func (p *Providers) List() []*Provider {
+	if p == nil {
+		return nil
+	}
+	return p.list
+}
+
+// nil when there is no such provider
+func (p *Providers) Get(name string) *Provider {
+	for _, provider := range p.List() {
+		if provider.Name == name {
+			return provider
+		}
+	}
+	return nil
+}
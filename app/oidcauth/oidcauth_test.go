@@ -0,0 +1,90 @@
+package oidcauth_test
+
+import (
+	"context"
+	"roommates/oidcauth"
+	"roommates/oidcauth/oidctest"
+	"testing"
+)
+
+const appURL = "https://roommates.test"
+
+func newProvider(t *testing.T) (*oidctest.Provider, *oidcauth.Provider) {
+	t.Helper()
+	mock := oidctest.NewProvider(t)
+	providers := oidcauth.New(context.Background(), mock.Client(), appURL, []oidcauth.ProviderConfig{mock.Config("mock")})
+	provider := providers.Get("mock")
+	if provider == nil {
+		t.Fatal("discovery failed")
+	}
+	return mock, provider
+}
+
+var user = oidctest.User{
+	Subject:           "subject-1",
+	Email:             "user@roommates.test",
+	EmailVerified:     true,
+	PreferredUsername: "user",
+}
+
+func TestExchange(t *testing.T) {
+	mock, provider := newProvider(t)
+	req := oidcauth.NewAuthRequest()
+	code := mock.Authorize(t, provider.AuthURL("state", req), user)
+
+	identity, err := provider.Exchange(context.Background(), code, req)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := oidcauth.Identity{
+		Provider:      "mock",
+		Subject:       user.Subject,
+		Email:         user.Email,
+		EmailVerified: true,
+		Username:      user.PreferredUsername,
+	}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+
+	if _, err = provider.Exchange(context.Background(), code, req); err == nil {
+		t.Error("code was accepted twice")
+	}
+}
+
+func TestExchangeNonceMismatch(t *testing.T) {
+	mock, provider := newProvider(t)
+	req := oidcauth.NewAuthRequest()
+	code := mock.Authorize(t, provider.AuthURL("state", req), user)
+
+	// id token made for another sign-in
+	other := req
+	other.Nonce = oidcauth.NewAuthRequest().Nonce
+	if _, err := provider.Exchange(context.Background(), code, other); err != oidcauth.ErrorNonceMismatch {
+		t.Errorf("Exchange = %v, want ErrorNonceMismatch", err)
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	mock, provider := newProvider(t)
+	req := oidcauth.NewAuthRequest()
+	code := mock.Authorize(t, provider.AuthURL("state", req), user)
+
+	// stolen code without the verifier of the browser that started the sign-in
+	other := req
+	other.Verifier = oidcauth.NewAuthRequest().Verifier
+	if _, err := provider.Exchange(context.Background(), code, other); err == nil {
+		t.Error("code was exchanged with the wrong PKCE verifier")
+	}
+}
+
+func TestNewSkipsFailedDiscovery(t *testing.T) {
+	mock := oidctest.NewProvider(t)
+	broken := mock.Config("broken")
+	broken.Issuer = mock.URL + "/missing"
+
+	providers := oidcauth.New(context.Background(), mock.Client(), appURL, []oidcauth.ProviderConfig{mock.Config("mock"), broken})
+	if list := providers.List(); len(list) != 1 || list[0].Name != "mock" {
+		t.Errorf("providers = %v, want only mock", list)
+	}
+}
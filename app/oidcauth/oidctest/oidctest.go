@@ -0,0 +1,218 @@
+// OpenID Connect provider for tests, serves discovery, the keys and the token endpoint
+//
+// the user signing in at the provider is done with Authorize, the code it returns is
+// exchanged like the one from a real callback
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"roommates/oidcauth"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	ClientID     = "test-client"
+	ClientSecret = "test-secret"
+	keyID        = "test-key"
+)
+
+// user signing in at the provider
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+}
+
+// what the provider remembers about a code until it is exchanged
+type grant struct {
+	user          User
+	nonce         string
+	challenge     string
+	redirectURI   string
+	challengeType string
+}
+
+type Provider struct {
+	*httptest.Server
+
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+// provider which is closed after the test
+func NewProvider(t testing.TB) *Provider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+
+	p := &Provider{key: key, grants: map[string]grant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /keys", p.keys)
+	mux.HandleFunc("POST /token", p.token)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// config for oidcauth.New
+func (p *Provider) Config(name string) oidcauth.ProviderConfig {
+	return oidcauth.ProviderConfig{
+		Name:         name,
+		DisplayName:  name,
+		Issuer:       p.URL,
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+	}
+}
+
+// user signs in at the auth url, returns the code the provider would send to the callback
+func (p *Provider) Authorize(t testing.TB, authURL string, user User) string {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid auth url: %v", err)
+	}
+	query := u.Query()
+	if query.Get("client_id") != ClientID {
+		t.Fatalf("auth url has client_id %q", query.Get("client_id"))
+	}
+	if query.Get("state") == "" {
+		t.Fatal("auth url has no state")
+	}
+
+	code := rand.Text()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.grants[code] = grant{
+		user:          user,
+		nonce:         query.Get("nonce"),
+		challenge:     query.Get("code_challenge"),
+		challengeType: query.Get("code_challenge_method"),
+		redirectURI:   query.Get("redirect_uri"),
+	}
+	return code
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/keys",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) keys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// codes can be used once, the verifier has to match the challenge of the auth url
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != ClientID || clientSecret != ClientSecret {
+		tokenError(w, "invalid_client")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	grant, found := p.grants[code]
+	delete(p.grants, code)
+	p.mu.Unlock()
+	if !found || r.PostForm.Get("redirect_uri") != grant.redirectURI {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if grant.challengeType != "S256" || base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	idToken, err := p.sign(map[string]any{
+		"iss":                p.URL,
+		"aud":                ClientID,
+		"sub":                grant.user.Subject,
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Hour).Unix(),
+		"nonce":              grant.nonce,
+		"email":              grant.user.Email,
+		"email_verified":     grant.user.EmailVerified,
+		"preferred_username": grant.user.PreferredUsername,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// RS256 signed JWT
+func (p *Provider) sign(claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
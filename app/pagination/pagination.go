@@ -0,0 +1,198 @@
+// cursor pagination, sorting and filtering of lists shared by the API and htmx fragments
+//
+// cursors are opaque to clients, they hold the sort of the list and the position
+// after the last item of the previous page, so pages do not shift when items are added
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20
+	// bigger limits are lowered to this
+	MaxLimit = 100
+)
+
+// query parameters
+const (
+	QLimit  = "limit"
+	QCursor = "cursor"
+	QSort   = "sort"
+	// filters are given as filter[name]=value
+	QFilter = "filter"
+)
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+// what a list can be sorted and filtered by
+type Resource struct {
+	// fields which can be sorted by, a "-" in front of the field in the query sorts in descending order
+	Sorts       []string
+	DefaultSort Sort
+	Filters     []string
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// "-created_at" -> created_at in descending order
+func ParseSort(s string) Sort {
+	field, desc := strings.CutPrefix(s, "-")
+	return Sort{Field: field, Desc: desc}
+}
+
+// position after the last item of a page
+type Cursor struct {
+	Sort string `json:"s"`
+	// value of the sorted field of the last item as text, time is in time.RFC3339Nano
+	Value string `json:"v"`
+	// id of the last item, orders the items with the same value
+	ID string `json:"i"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	var cursor Cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err = json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+type Page struct {
+	Limit   int
+	Sort    Sort
+	Filters map[string]string
+	// nil on the first page
+	After *Cursor
+}
+
+// reads limit, cursor, sort and filters from the query
+//
+// sort and filters are checked against what the resource allows,
+// cursor of a list sorted differently is not accepted
+func Parse(query url.Values, r Resource) (Page, error) {
+	page := Page{
+		Limit:   DefaultLimit,
+		Sort:    r.DefaultSort,
+		Filters: map[string]string{},
+	}
+
+	if qLimit := query.Get(QLimit); qLimit != "" {
+		limit, err := strconv.Atoi(qLimit)
+		if err != nil || limit < 1 {
+			return page, ErrInvalidLimit
+		}
+		page.Limit = min(limit, MaxLimit)
+	}
+
+	if qSort := query.Get(QSort); qSort != "" {
+		page.Sort = ParseSort(qSort)
+		if !slices.Contains(r.Sorts, page.Sort.Field) {
+			return page, ErrInvalidSort
+		}
+	}
+
+	if qCursor := query.Get(QCursor); qCursor != "" {
+		cursor, err := DecodeCursor(qCursor)
+		if err != nil {
+			return page, err
+		}
+		if query.Get(QSort) == "" && slices.Contains(r.Sorts, ParseSort(cursor.Sort).Field) {
+			page.Sort = ParseSort(cursor.Sort)
+		}
+		if cursor.Sort != page.Sort.String() {
+			return page, ErrInvalidCursor
+		}
+		page.After = &cursor
+	}
+
+	for key, values := range query {
+		name, ok := strings.CutPrefix(key, QFilter+"[")
+		if !ok {
+			continue
+		}
+		name, ok = strings.CutSuffix(name, "]")
+		if !ok || !slices.Contains(r.Filters, name) || len(values) != 1 || values[0] == "" {
+			return page, ErrInvalidFilter
+		}
+		page.Filters[name] = values[0]
+	}
+
+	return page, nil
+}
+
+// escapes the wildcards of LIKE for a query using ESCAPE '\',
+// "50%" then finds names with "50%" in them and not every name with "50"
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// how many items to fetch, one more than the limit tells if there is a next page
+func (p Page) FetchLimit() int32 {
+	return int32(p.Limit + 1)
+}
+
+// cursor after the item, `value` is the sorted field of the item as text
+func (p Page) CursorAfter(value, id string) string {
+	return Cursor{Sort: p.Sort.String(), Value: value, ID: id}.Encode()
+}
+
+// cuts the fetched items to the limit
+//
+//	bool == true -- there is a next page
+func Trim[T any](p Page, items []T) ([]T, bool) {
+	if len(items) <= p.Limit {
+		return items, false
+	}
+	return items[:p.Limit], true
+}
+
+// url of the next page, the same as `u` with the cursor replaced
+func NextURL(u *url.URL, cursor string) string {
+	next := *u
+	query := next.Query()
+	query.Set(QCursor, cursor)
+	next.RawQuery = query.Encode()
+	return next.RequestURI()
+}
+
+// sets the Link header (RFC 8288) pointing to the next page, nothing is set without a cursor
+func SetLinkHeader(h http.Header, u *url.URL, cursor string) {
+	if cursor == "" {
+		return
+	}
+	h.Set("Link", "<"+NextURL(u, cursor)+`>; rel="next"`)
+}
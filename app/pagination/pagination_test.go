@@ -0,0 +1,164 @@
+package pagination
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+var notes = Resource{
+	Sorts:       []string{"created_at", "title"},
+	DefaultSort: Sort{Field: "created_at", Desc: true},
+	Filters:     []string{"title", "maker_id"},
+}
+
+func cursor(sort string) string {
+	return Cursor{Sort: sort, Value: "2025-01-31T12:00:00Z", ID: "3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f"}.Encode()
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantErr   error
+		wantLimit int
+		wantSort  string
+		// cursor sort, "" when there is no cursor
+		wantAfter   string
+		wantFilters map[string]string
+	}{
+		{name: "defaults", query: "", wantLimit: DefaultLimit, wantSort: "-created_at"},
+		{name: "limit", query: "limit=5", wantLimit: 5, wantSort: "-created_at"},
+		{name: "limit capped", query: "limit=1000", wantLimit: MaxLimit, wantSort: "-created_at"},
+		{name: "limit at the cap", query: "limit=100", wantLimit: MaxLimit, wantSort: "-created_at"},
+		{name: "zero limit", query: "limit=0", wantErr: ErrInvalidLimit},
+		{name: "negative limit", query: "limit=-1", wantErr: ErrInvalidLimit},
+		{name: "limit not a number", query: "limit=ten", wantErr: ErrInvalidLimit},
+		{name: "ascending sort", query: "sort=title", wantLimit: DefaultLimit, wantSort: "title"},
+		{name: "descending sort", query: "sort=-title", wantLimit: DefaultLimit, wantSort: "-title"},
+		{name: "unknown sort", query: "sort=password", wantErr: ErrInvalidSort},
+		{name: "cursor", query: "cursor=" + cursor("-created_at"), wantLimit: DefaultLimit, wantSort: "-created_at", wantAfter: "-created_at"},
+		// the next page is sorted like the first one without repeating the sort
+		{name: "cursor keeps its sort", query: "cursor=" + cursor("title"), wantLimit: DefaultLimit, wantSort: "title", wantAfter: "title"},
+		{name: "cursor with the same sort", query: "sort=title&cursor=" + cursor("title"), wantLimit: DefaultLimit, wantSort: "title", wantAfter: "title"},
+		{name: "cursor of another sort", query: "sort=-title&cursor=" + cursor("title"), wantErr: ErrInvalidCursor},
+		{name: "cursor of a sort the list does not have", query: "cursor=" + cursor("password"), wantErr: ErrInvalidCursor},
+		{name: "cursor not base64", query: "cursor=%25%25%25", wantErr: ErrInvalidCursor},
+		{name: "cursor not json", query: "cursor=" + "bm90IGpzb24", wantErr: ErrInvalidCursor},
+		{name: "cursor without an id", query: "cursor=" + Cursor{Sort: "-created_at", Value: "x"}.Encode(), wantErr: ErrInvalidCursor},
+		{name: "filter", query: "filter[title]=wifi", wantLimit: DefaultLimit, wantSort: "-created_at", wantFilters: map[string]string{"title": "wifi"}},
+		{name: "filters", query: "filter[title]=wifi&filter[maker_id]=1", wantLimit: DefaultLimit, wantSort: "-created_at", wantFilters: map[string]string{"title": "wifi", "maker_id": "1"}},
+		{name: "filter not allowed", query: "filter[content]=wifi", wantErr: ErrInvalidFilter},
+		{name: "empty filter", query: "filter[title]=", wantErr: ErrInvalidFilter},
+		{name: "filter given twice", query: "filter[title]=a&filter[title]=b", wantErr: ErrInvalidFilter},
+		{name: "filter without the bracket", query: "filter[title=wifi", wantErr: ErrInvalidFilter},
+		{name: "other parameters are ignored", query: "page=2&filter=title", wantLimit: DefaultLimit, wantSort: "-created_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, err := Parse(query, notes)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.query, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if page.Limit != tt.wantLimit {
+				t.Errorf("limit = %d, want %d", page.Limit, tt.wantLimit)
+			}
+			if page.Sort.String() != tt.wantSort {
+				t.Errorf("sort = %q, want %q", page.Sort, tt.wantSort)
+			}
+			switch {
+			case tt.wantAfter == "" && page.After != nil:
+				t.Errorf("after = %+v, want none", page.After)
+			case tt.wantAfter != "" && (page.After == nil || page.After.Sort != tt.wantAfter):
+				t.Errorf("after = %+v, want a cursor sorted by %q", page.After, tt.wantAfter)
+			}
+			if len(page.Filters) != len(tt.wantFilters) {
+				t.Errorf("filters = %v, want %v", page.Filters, tt.wantFilters)
+			}
+			for name, value := range tt.wantFilters {
+				if page.Filters[name] != value {
+					t.Errorf("filter %s = %q, want %q", name, page.Filters[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	page := Page{Limit: 2, Sort: Sort{Field: "title"}}
+	encoded := page.CursorAfter("wifi", "3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f")
+	if strings.ContainsAny(encoded, "+/=") {
+		t.Errorf("cursor %q is not safe in a url", encoded)
+	}
+	got, err := DecodeCursor(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Sort != "title" || got.Value != "wifi" || got.ID != "3c4d5e6f-7a8b-4c9d-8e0f-1a2b3c4d5e6f" {
+		t.Errorf("DecodeCursor = %+v", got)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	page := Page{Limit: 2}
+	tests := []struct {
+		items    []int
+		wantLen  int
+		wantMore bool
+	}{
+		{nil, 0, false},
+		{[]int{1, 2}, 2, false},
+		{[]int{1, 2, 3}, 2, true},
+	}
+	for _, tt := range tests {
+		items, more := Trim(page, tt.items)
+		if len(items) != tt.wantLen || more != tt.wantMore {
+			t.Errorf("Trim(%v) = %v, %v, want %d items, %v", tt.items, items, more, tt.wantLen, tt.wantMore)
+		}
+	}
+	if page.FetchLimit() != 3 {
+		t.Errorf("FetchLimit = %d, want one more than the limit", page.FetchLimit())
+	}
+}
+
+func TestSetLinkHeader(t *testing.T) {
+	u, _ := url.Parse("/api/v1/houses?limit=5&cursor=old&filter%5Bname%5D=home")
+	h := http.Header{}
+	SetLinkHeader(h, u, "")
+	if h.Get("Link") != "" {
+		t.Errorf("Link without a next page = %q", h.Get("Link"))
+	}
+
+	SetLinkHeader(h, u, "new")
+	want := `</api/v1/houses?cursor=new&filter%5Bname%5D=home&limit=5>; rel="next"`
+	if h.Get("Link") != want {
+		t.Errorf("Link = %q, want %q", h.Get("Link"), want)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"wifi", "wifi"},
+		{"50%", `50\%`},
+		{"a_b", `a\_b`},
+		{`C:\temp`, `C:\\temp`},
+		{`\%_`, `\\\%\_`},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := EscapeLike(tt.in); got != tt.want {
+			t.Errorf("EscapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis key start for idempotency keys, followed by the user id and the key
+const KIdempotency = "idempotency:"
+
+// responses are replayed for this long
+const EIdempotency = 24 * time.Hour
+
+// claim of a key while its request is handled, a crashed request frees the key after this
+const EIdempotencyPending = time.Minute
+
+// response stored for an idempotency key
+type IdempotentResponse struct {
+	// hash of the request the key was first used with
+	Fingerprint string `json:"fingerprint"`
+	// 0 while the first request is still being handled
+	Status int               `json:"status"`
+	Header map[string]string `json:"header"`
+	Body   []byte            `json:"body"`
+}
+
+func (r *IdempotentResponse) IsPending() bool {
+	return r.Status == 0
+}
+
+func idempotencyKey(userID, key string) string {
+	return KIdempotency + userID + ":" + key
+}
+
+// claims the key for the request with the fingerprint
+//
+// returns nil when the key was claimed, otherwise what is stored for the key,
+// which can still be pending
+func (r *RedisHandler) ClaimIdempotencyKey(ctx context.Context, userID, key, fingerprint string) (*IdempotentResponse, error) {
+	k := idempotencyKey(userID, key)
+	pending := IdempotentResponse{Fingerprint: fingerprint}
+
+	// second try when the stored value expires between SETNX and GET
+	for range 2 {
+		claimed, err := r.redis.SetNX(ctx, k, Marshal(pending), EIdempotencyPending).Result()
+		if err != nil {
+			log.Error().Err(err).Caller().Msg("error during ClaimIdempotencyKey")
+			return nil, err
+		}
+		if claimed {
+			return nil, nil
+		}
+
+		data, err := r.redis.Get(ctx, k).Bytes()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			log.Error().Err(err).Caller().Msg("error during ClaimIdempotencyKey")
+			return nil, err
+		}
+		var stored IdempotentResponse
+		Unmarshal(data, &stored)
+		return &stored, nil
+	}
+	return nil, errors.New("idempotency key could not be claimed")
+}
+
+// stores the response of a claimed key for EIdempotency
+func (r *RedisHandler) SaveIdempotentResponse(ctx context.Context, userID, key string, response IdempotentResponse) error {
+	err := r.redis.Set(ctx, idempotencyKey(userID, key), Marshal(response), EIdempotency).Err()
+	if err != nil {
+		log.Error().Err(err).Caller().Msg("error during SaveIdempotentResponse")
+	}
+	return err
+}
+
+// frees a claimed key, the request can be retried with it
+func (r *RedisHandler) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	err := r.redis.Del(ctx, idempotencyKey(userID, key)).Err()
+	if err != nil {
+		log.Error().Err(err).Caller().Msg("error during ReleaseIdempotencyKey")
+	}
+	return err
+}
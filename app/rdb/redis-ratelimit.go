@@ -0,0 +1,130 @@
+package rdb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis key start for rate limit windows, followed by the limit name and key
+const KRateLimit = "rate-limit:"
+
+// Redis key start for failed attempts and lockouts, followed by the key
+const (
+	KFailures = "failures:"
+	KLockout  = "lockout:"
+)
+
+// at most Limit requests per sliding Window
+type RateLimit struct {
+	// keeps the windows of different limits apart
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// sliding window log, each request is a member of a sorted set scored by its time
+//
+// returns 0 when the request is allowed, otherwise milliseconds until it would be
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now - window)
+if redis.call("ZCARD", key) >= limit then
+	local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+	return math.max(tonumber(oldest[2]) + window - now, 1)
+end
+redis.call("ZADD", key, now, ARGV[4])
+redis.call("PEXPIRE", key, window)
+return 0
+`)
+
+// counts the request against the limit for the key
+//
+// returns how long to wait when the limit has been reached, 0 when the request is allowed
+func (r *RedisHandler) Allow(ctx context.Context, limit RateLimit, key string) (time.Duration, error) {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	wait, err := slidingWindowScript.Run(ctx, r.redis,
+		[]string{KRateLimit + limit.Name + ":" + key},
+		time.Now().UnixMilli(),
+		limit.Window.Milliseconds(),
+		limit.Limit,
+		hex.EncodeToString(b),
+	).Int64()
+	if err != nil {
+		log.Error().Err(err).Str("limit", limit.Name).Caller().Msg("error during Allow")
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// lockout after repeated failures, every failure past Threshold doubles it
+type Lockout struct {
+	// failures allowed before the first lockout
+	Threshold int64
+	// lockout after reaching the threshold
+	Base time.Duration
+	Max  time.Duration
+	// failures are forgotten after this long without a new one
+	Window time.Duration
+}
+
+// records a failed attempt for the key
+//
+// returns for how long the key is now locked out, 0 when it is not
+func (r *RedisHandler) RecordFailure(ctx context.Context, lockout Lockout, key string) (time.Duration, error) {
+	failuresKey := KFailures + key
+	pipe := r.redis.TxPipeline()
+	incr := pipe.Incr(ctx, failuresKey)
+	pipe.Expire(ctx, failuresKey, lockout.Window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error().Err(err).Caller().Msg("error during RecordFailure")
+		return 0, err
+	}
+
+	failures := incr.Val()
+	if failures < lockout.Threshold {
+		return 0, nil
+	}
+
+	d := lockout.Base
+	for i := lockout.Threshold; i < failures && d < lockout.Max; i++ {
+		d *= 2
+	}
+	d = min(d, lockout.Max)
+
+	if err := r.redis.Set(ctx, KLockout+key, failures, d).Err(); err != nil {
+		log.Error().Err(err).Caller().Msg("error during RecordFailure")
+		return 0, err
+	}
+	return d, nil
+}
+
+// returns for how long the key is still locked out, 0 when it is not
+func (r *RedisHandler) LockedOut(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.redis.PTTL(ctx, KLockout+key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Error().Err(err).Caller().Msg("error during LockedOut")
+		return 0, err
+	}
+	// negative when the key does not exist
+	return max(ttl, 0), nil
+}
+
+// forgets the failures of the key, called after a successful attempt
+func (r *RedisHandler) ResetFailures(ctx context.Context, key string) error {
+	if err := r.redis.Del(ctx, KFailures+key, KLockout+key).Err(); err != nil {
+		log.Error().Err(err).Caller().Msg("error during ResetFailures")
+		return err
+	}
+	return nil
+}
@@ -2,7 +2,10 @@ package rdb
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,21 +15,75 @@ import (
 
 // Redis key start for user sessions
 const KSession = "session:"
+
+// Redis key start for the set of session keys of a user, followed by the user id
+const KUserSessions = "user-sessions:"
+
+// sessions expire after not being used for this long
 const EUserSession = 48 * time.Hour
 
+// last seen (and the cookie) is updated at most this often, not on every request
+const SessionTouchInterval = 5 * time.Minute
+
 type UserSessionValue struct {
 	UserID   pgtype.UUID `redis:"user_id" json:"user_id"`
 	Username string      `redis:"username" json:"username"`
+	// shown in the navigation bar, empty when the user has no avatar
+	AvatarKey string `redis:"avatar_key" json:"avatar_key"`
+
+	// metadata, shown to the user in the list of their sessions
+	CreatedAt time.Time `redis:"created_at" json:"created_at"`
+	LastSeen  time.Time `redis:"last_seen" json:"last_seen"`
+	UserAgent string    `redis:"user_agent" json:"user_agent"`
+	IP        string    `redis:"ip" json:"ip"`
+
+	// key of the session, set when the session is read and never stored
+	Key string `redis:"-" json:"-"`
+
+	// set instead of Key when authenticated with a personal access token
+	AccessTokenID int64    `redis:"-" json:"-"`
+	Scopes        []string `redis:"-" json:"-"`
+}
+
+// sessions are allowed everything, access tokens only what they were given
+func (v *UserSessionValue) HasScope(scope string) bool {
+	if v.AccessTokenID == 0 {
+		return true
+	}
+	return slices.Contains(v.Scopes, scope)
+}
+
+// session as listed to the user
+type UserSession struct {
+	UserSessionValue
+	// the key itself is as good as a password, this is safe to put into html
+	ID string
+}
+
+// public id of the session key
+func SessionID(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:8])
 }
 
 // can panic
 func (r *RedisHandler) CreateUserSession(ctx context.Context, sessionValue UserSessionValue) uuid.UUID {
 	key := uuid.New() // NewRandom generates V4
 	rKey := KSession + key.String()
+
+	now := time.Now()
+	sessionValue.CreatedAt = now
+	sessionValue.LastSeen = now
 	rValue := Marshal(sessionValue)
 
-	cmd := r.redis.SetNX(ctx, rKey, rValue, EUserSession)
-	err := cmd.Err()
+	// index of the sessions lives as long as the newest session
+	indexKey := KUserSessions + sessionValue.UserID.String()
+	_, err := r.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, rKey, rValue, EUserSession)
+		pipe.SAdd(ctx, indexKey, key.String())
+		pipe.Expire(ctx, indexKey, EUserSession)
+		return nil
+	})
 	if err != nil {
 		log.Error().Err(err).Str("key", rKey).Caller().Msg("error during CreateUserSession")
 		panic(err)
@@ -34,15 +91,14 @@ func (r *RedisHandler) CreateUserSession(ctx context.Context, sessionValue UserS
 	return key
 }
 
-// Key is just string form of UUID
+// Key is just string form of UUID, every read resets the expiry
 //
 // No need to add redis "topic"/"key start" (KSession) in front of `key`
 func (r *RedisHandler) GetUserSession(ctx context.Context, key string) (*UserSessionValue, error) {
 	rKey := KSession + key
 	value := &UserSessionValue{}
-	// TODO: reset expiry after use
 
-	cmd := r.redis.Get(ctx, rKey)
+	cmd := r.redis.GetEx(ctx, rKey, EUserSession)
 	err := cmd.Err()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
@@ -53,21 +109,171 @@ func (r *RedisHandler) GetUserSession(ctx context.Context, key string) (*UserSes
 	}
 
 	Unmarshal([]byte(cmd.Val()), value)
+	value.Key = key
 	return value, nil
 }
 
+// updates last seen and where the session was used from
+//
+// meant to be called when LastSeen is older than SessionTouchInterval
+func (r *RedisHandler) TouchUserSession(ctx context.Context, value UserSessionValue, ip, userAgent string) error {
+	value.LastSeen = time.Now()
+	value.IP = ip
+	value.UserAgent = userAgent
+
+	indexKey := KUserSessions + value.UserID.String()
+	_, err := r.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// XX so a session deleted in the meantime is not brought back
+		pipe.SetArgs(ctx, KSession+value.Key, Marshal(value), redis.SetArgs{Mode: "XX", KeepTTL: true})
+		pipe.Expire(ctx, indexKey, EUserSession)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Error().Err(err).Caller().Msg("error during TouchUserSession")
+		return err
+	}
+	return nil
+}
+
 // Key is just string form of UUID
 //
 // No need to add redis "topic"/"key start" (KSession) in front of `key`
 func (r *RedisHandler) DeleteUserSession(ctx context.Context, key string) error {
 	rKey := KSession + key
 
-	// .Result() is the same as getting .Val() and .Err()
-	cmd := r.redis.Del(ctx, rKey)
-	err := cmd.Err()
+	data, err := r.redis.GetDel(ctx, rKey).Bytes()
 	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		log.Error().Err(err).Caller().Msg("error during DeleteUserSession")
+		return err
+	}
+
+	var value UserSessionValue
+	Unmarshal(data, &value)
+	if err = r.redis.SRem(ctx, KUserSessions+value.UserID.String(), key).Err(); err != nil {
 		log.Error().Err(err).Caller().Msg("error during DeleteUserSession")
 		return err
 	}
 	return nil
 }
+
+// deletes every session of the user, used after the password has changed
+func (r *RedisHandler) DeleteUserSessions(ctx context.Context, userID pgtype.UUID) error {
+	indexKey := KUserSessions + userID.String()
+	keys, err := r.redis.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		log.Error().Err(err).Caller().Msg("error during DeleteUserSessions")
+		return err
+	}
+
+	// expired sessions are still in the index, deleting them does nothing
+	rKeys := []string{indexKey}
+	for _, key := range keys {
+		rKeys = append(rKeys, KSession+key)
+	}
+	if err = r.redis.Del(ctx, rKeys...).Err(); err != nil {
+		log.Error().Err(err).Caller().Msg("error during DeleteUserSessions")
+		return err
+	}
+	return nil
+}
+
+// active sessions of the user, most recently used first
+//
+// expired sessions are removed from the index along the way
+func (r *RedisHandler) UserSessions(ctx context.Context, userID pgtype.UUID) ([]UserSession, error) {
+	indexKey := KUserSessions + userID.String()
+	keys, err := r.redis.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		log.Error().Err(err).Caller().Msg("error during UserSessions")
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	rKeys := make([]string, len(keys))
+	for i, key := range keys {
+		rKeys[i] = KSession + key
+	}
+	values, err := r.redis.MGet(ctx, rKeys...).Result()
+	if err != nil {
+		log.Error().Err(err).Caller().Msg("error during UserSessions")
+		return nil, err
+	}
+
+	var sessions []UserSession
+	var expired []any
+	for i, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			expired = append(expired, keys[i])
+			continue
+		}
+		session := UserSession{ID: SessionID(keys[i])}
+		Unmarshal([]byte(data), &session.UserSessionValue)
+		session.Key = keys[i]
+		sessions = append(sessions, session)
+	}
+	if len(expired) > 0 {
+		if err = r.redis.SRem(ctx, indexKey, expired...).Err(); err != nil {
+			log.Error().Err(err).Caller().Msg("error during UserSessions")
+		}
+	}
+
+	slices.SortFunc(sessions, func(a, b UserSession) int {
+		return b.LastSeen.Compare(a.LastSeen)
+	})
+	return sessions, nil
+}
+
+// deletes the session of the user by its public id (SessionID)
+//
+// returns false when the user has no such session
+func (r *RedisHandler) DeleteUserSessionByID(ctx context.Context, userID pgtype.UUID, id string) (bool, error) {
+	keys, err := r.redis.SMembers(ctx, KUserSessions+userID.String()).Result()
+	if err != nil {
+		log.Error().Err(err).Caller().Msg("error during DeleteUserSessionByID")
+		return false, err
+	}
+
+	for _, key := range keys {
+		if SessionID(key) == id {
+			return true, r.DeleteUserSession(ctx, key)
+		}
+	}
+	return false, nil
+}
+
+// changes every session of the user, used when something shown from the session has changed
+func (r *RedisHandler) UpdateUserSessions(ctx context.Context, userID pgtype.UUID, update func(*UserSessionValue)) error {
+	keys, err := r.redis.SMembers(ctx, KUserSessions+userID.String()).Result()
+	if err != nil {
+		log.Error().Err(err).Caller().Msg("error during UpdateUserSessions")
+		return err
+	}
+
+	for _, key := range keys {
+		data, err := r.redis.Get(ctx, KSession+key).Bytes()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			log.Error().Err(err).Caller().Msg("error during UpdateUserSessions")
+			return err
+		}
+
+		var value UserSessionValue
+		Unmarshal(data, &value)
+		update(&value)
+		// XX so a session deleted in the meantime is not brought back
+		err = r.redis.SetArgs(ctx, KSession+key, Marshal(value), redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			log.Error().Err(err).Caller().Msg("error during UpdateUserSessions")
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package rdb
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis key start for one-time tokens, followed by the purpose
+const KToken = "token:"
+
+// Redis key start for cooldowns
+const KCooldown = "cooldown:"
+
+// what the token is used for, tokens of one purpose can not be used for another
+type TokenPurpose string
+
+const (
+	TPEmailVerification TokenPurpose = "email-verification"
+	TPPasswordReset     TokenPurpose = "password-reset"
+	// password was right, waiting for the two-factor code
+	TPTwoFactor TokenPurpose = "two-factor"
+	// state of a sign-in with an OpenID Connect provider
+	TPOIDC TokenPurpose = "oidc"
+	// confirms deleting an account which has no password
+	TPAccountDeletion TokenPurpose = "account-deletion"
+)
+
+const EEmailVerification = 24 * time.Hour
+const EPasswordReset = 30 * time.Minute
+const ETwoFactor = 5 * time.Minute
+const EOIDC = 10 * time.Minute
+const EAccountDeletion = 30 * time.Minute
+
+// only the hash of the token is stored, leaked redis data can not be used to make links
+func tokenKey(purpose TokenPurpose, token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return KToken + string(purpose) + ":" + hex.EncodeToString(hash[:])
+}
+
+// creates a random single-use token which expires after `expiry`
+//
+// value is stored with the token and given back when it is consumed
+func (r *RedisHandler) CreateToken(ctx context.Context, purpose TokenPurpose, value any, expiry time.Duration) (string, error) {
+	b := make([]byte, 32)
+	rand.Read(b)
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	err := r.redis.Set(ctx, tokenKey(purpose, token), Marshal(value), expiry).Err()
+	if err != nil {
+		log.Error().Err(err).Str("purpose", string(purpose)).Caller().Msg("error during CreateToken")
+		return "", err
+	}
+	return token, nil
+}
+
+// deletes the token and unmarshals its value into `value`
+//
+// returns false when the token does not exist, it has expired or has already been used
+func (r *RedisHandler) ConsumeToken(ctx context.Context, purpose TokenPurpose, token string, value any) (bool, error) {
+	data, err := r.redis.GetDel(ctx, tokenKey(purpose, token)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		log.Error().Err(err).Str("purpose", string(purpose)).Caller().Msg("error during ConsumeToken")
+		return false, err
+	}
+
+	Unmarshal(data, value)
+	return true, nil
+}
+
+// same as ConsumeToken but the token stays usable
+func (r *RedisHandler) PeekToken(ctx context.Context, purpose TokenPurpose, token string, value any) (bool, error) {
+	data, err := r.redis.Get(ctx, tokenKey(purpose, token)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		log.Error().Err(err).Str("purpose", string(purpose)).Caller().Msg("error during PeekToken")
+		return false, err
+	}
+
+	Unmarshal(data, value)
+	return true, nil
+}
+
+// returns true at most once per `d` for the same key
+func (r *RedisHandler) Cooldown(ctx context.Context, key string, d time.Duration) (bool, error) {
+	ok, err := r.redis.SetNX(ctx, KCooldown+key, 1, d).Result()
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Caller().Msg("error during Cooldown")
+		return false, err
+	}
+	return ok, nil
+}
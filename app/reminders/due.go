@@ -0,0 +1,92 @@
+// reminders whose due date has come, the houses get an event about them
+package reminders
+
+import (
+	"context"
+	"roommates/db/dbqueries"
+	"roommates/events"
+	"roommates/logger"
+	"roommates/models"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var log = logger.RemindersLoggger
+
+const (
+	dueBatchSize = 100
+	dueInterval  = 15 * time.Minute
+)
+
+// records events.ReminderDue once per due date of a reminder
+type DueRunner struct {
+	pool *pgxpool.Pool
+	db   *dbqueries.Queries
+}
+
+func NewDueRunner(pool *pgxpool.Pool) *DueRunner {
+	return &DueRunner{
+		pool: pool,
+		db:   dbqueries.New(pool),
+	}
+}
+
+// blocks until ctx is done
+func (r *DueRunner) Run(ctx context.Context) {
+	log.Info().Msg("due reminders started")
+	ticker := time.NewTicker(dueInterval)
+	defer ticker.Stop()
+
+	for {
+		marked, err := r.MarkDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("error marking due reminders")
+		}
+		if marked == dueBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("due reminders stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// records the event for a single batch of due reminders, returns the amount of reminders
+//
+// the rows are locked (SKIP LOCKED), multiple app instances can run it at the same time
+func (r *DueRunner) MarkDue(ctx context.Context) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.db.WithTx(tx)
+
+	reminders, err := qtx.MarkRemindersDue(ctx, dueBatchSize)
+	if err != nil || len(reminders) == 0 {
+		return 0, err
+	}
+	for _, reminder := range reminders {
+		err = events.Record(ctx, qtx, events.Event{
+			Type:    events.ReminderDue,
+			HouseID: reminder.HouseID,
+			Payload: events.Payload{
+				SubjectID: strconv.Itoa(int(reminder.ID)),
+				Subject:   models.ParseReminderContent(reminder.Content).Title,
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(reminders), nil
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"roommates/accesstokens"
 	"roommates/controller"
 	"roommates/docs"
 	"roommates/gintemplrenderer"
 	g "roommates/globals"
 	"roommates/locales"
 	"roommates/middleware"
+	"roommates/rdb"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/invopop/ctxi18n"
@@ -17,7 +20,13 @@ import (
 func InitGinEngine(c *controller.Controller) *gin.Engine {
 	e := gin.Default()
 	e.SetTrustedProxies(nil)
-	// e.Use(middleware.NewCSRFMiddleware())
+	e.Use(middleware.NewCSRFMiddleware(
+		// one-click unsubscribe is posted by mail clients, the token in the path protects it
+		g.RUnsubscribe,
+		// used by API clients to get the bearer token
+		docs.SwaggerInfo.BasePath+"/auth/sign-in",
+		docs.SwaggerInfo.BasePath+"/auth/sign-in/two-factor",
+	))
 	ginHtmlRenderer := e.HTMLRender
 	e.HTMLRender = &gintemplrenderer.HTMLTemplRenderer{FallbackHtmlRenderer: ginHtmlRenderer}
 
@@ -32,11 +41,17 @@ func InitGinEngine(c *controller.Controller) *gin.Engine {
 
 func InitRoutes(r *gin.Engine, c *controller.Controller) {
 	// TODO(low prio, effort not worth benefit): read the paths, add to constants and change @Route of swagger API doc in associated controllers
-	// TODO: rate limit, if not for all then auth endpoints for sure
 	authMw := middleware.NewAuthenticationMiddleware(c, true)
 	authInfoMwUnblocking := middleware.NewAuthenticationMiddleware(c, false)
 	i18nMw := middleware.NewLanguageMiddleware()
 
+	// generic limits, per user when signed in
+	publicLimitMw := middleware.NewRateLimitMiddleware(c, rdb.RateLimit{Name: "public", Limit: 120, Window: time.Minute}, middleware.RLKeyIP)
+	protectedLimitMw := middleware.NewRateLimitMiddleware(c, rdb.RateLimit{Name: "protected", Limit: 300, Window: time.Minute}, middleware.RLKeyUser)
+	// sign-in, sign-up and password reset attempts, failed sign-ins also lock the account out
+	authIPLimitMw := middleware.NewRateLimitMiddleware(c, rdb.RateLimit{Name: "auth-ip", Limit: 30, Window: 10 * time.Minute}, middleware.RLKeyIP)
+	authAccountLimitMw := middleware.NewRateLimitMiddleware(c, rdb.RateLimit{Name: "auth-account", Limit: 10, Window: 10 * time.Minute}, middleware.RLKeyAccount)
+
 	// API endpoints
 	var v1 = r.Group(docs.SwaggerInfo.BasePath)
 	{
@@ -45,10 +60,77 @@ func InitRoutes(r *gin.Engine, c *controller.Controller) {
 
 		authentication := v1.Group("/auth")
 		{
-			authentication.POST("/sign-in", c.SignIn)
+			authentication.POST("/sign-in", authIPLimitMw, authAccountLimitMw, c.SignIn)
+			authentication.POST("/sign-in/two-factor", authIPLimitMw, c.SignInTwoFactor)
 			authentication.GET("/sign-out", c.SignOut)
 		}
 
+		users := v1.Group("/users")
+		{
+			users.Use(authMw)
+			users.Use(protectedLimitMw)
+			users.GET("/me", middleware.NewScopeMiddleware(accesstokens.ReadProfile), c.CurrentUser)
+		}
+
+		readHousesMw := middleware.NewScopeMiddleware(accesstokens.ReadHouses)
+		writeHousesMw := middleware.NewScopeMiddleware(accesstokens.WriteHouses)
+		readNotesMw := middleware.NewScopeMiddleware(accesstokens.ReadNotes)
+		writeNotesMw := middleware.NewScopeMiddleware(accesstokens.WriteNotes)
+		readPaymentsMw := middleware.NewScopeMiddleware(accesstokens.ReadPayments)
+		writePaymentsMw := middleware.NewScopeMiddleware(accesstokens.WritePayments)
+		// retries of requests making something new do not make it twice
+		idempotencyMw := middleware.NewIdempotencyMiddleware(c)
+		readMessagesMw := middleware.NewScopeMiddleware(accesstokens.ReadMessages)
+		writeMessagesMw := middleware.NewScopeMiddleware(accesstokens.WriteMessages)
+
+		houses := v1.Group("/houses")
+		{
+			houses.Use(authMw)
+			houses.Use(protectedLimitMw)
+			// validation messages are translated
+			houses.Use(i18nMw)
+			houses.GET("", readHousesMw, c.APIListHouses)
+			houses.POST("", writeHousesMw, c.RequireVerifiedEmail, idempotencyMw, c.APICreateHouse)
+			houses.GET("/:id", readHousesMw, c.APIGetHouse)
+			houses.PUT("/:id", writeHousesMw, c.APIUpdateHouse)
+			houses.DELETE("/:id", writeHousesMw, c.APIDeleteHouse)
+			houses.GET("/:id/members", readHousesMw, c.APIListHouseMembers)
+			houses.POST("/:id/members", writeHousesMw, c.RequireVerifiedEmail, c.APIAddHouseMember)
+			houses.DELETE("/:id/members/:user_id", writeHousesMw, c.APIRemoveHouseMember)
+			houses.GET("/:id/notes", readNotesMw, c.APIListHouseNotes)
+			houses.POST("/:id/notes", writeNotesMw, idempotencyMw, c.APICreateNote)
+			houses.POST("/:id/payments", writePaymentsMw, c.RequireVerifiedEmail, idempotencyMw, c.APICreatePayment)
+		}
+
+		notes := v1.Group("/notes")
+		{
+			notes.Use(authMw)
+			notes.Use(protectedLimitMw)
+			notes.Use(i18nMw)
+			notes.GET("/:id", readNotesMw, c.APIGetNote)
+			notes.PUT("/:id", writeNotesMw, c.APIUpdateNote)
+			notes.DELETE("/:id", writeNotesMw, c.APIDeleteNote)
+		}
+
+		payments := v1.Group("/payments")
+		{
+			payments.Use(authMw)
+			payments.Use(protectedLimitMw)
+			payments.GET("", readPaymentsMw, c.APIListPayments)
+			payments.GET("/:id", readPaymentsMw, c.APIGetPayment)
+		}
+
+		conversations := v1.Group("/conversations")
+		{
+			conversations.Use(authMw)
+			conversations.Use(protectedLimitMw)
+			// replies of commands are translated
+			conversations.Use(i18nMw)
+			conversations.GET("/:id/messages", readMessagesMw, c.APIListConversationMessages)
+			// scopes of chat commands in the message are checked by each command
+			conversations.POST("/:id/messages", writeMessagesMw, idempotencyMw, c.APISendConversationMessage)
+		}
+
 		// TODO: API point for websocket -- https://github.com/gin-gonic/examples/blob/master/websocket/server/server.go#L16
 	}
 
@@ -59,12 +141,30 @@ func InitRoutes(r *gin.Engine, c *controller.Controller) {
 	{
 		public.Use(i18nMw)
 		public.Use(authInfoMwUnblocking)
+		public.Use(publicLimitMw)
 
 		public.GET(g.RLogin, c.PageLogin)
-		public.POST(g.RLogin, c.PageLogin)
+		public.POST(g.RLogin, authIPLimitMw, authAccountLimitMw, c.PageLogin)
+		public.GET(g.RLoginTwoFactor, c.PageLoginTwoFactor)
+		public.POST(g.RLoginTwoFactor, authIPLimitMw, c.PageLoginTwoFactor)
+		public.GET(g.RLoginOIDC, authIPLimitMw, c.PageLoginOIDC)
+		public.GET(g.RLoginOIDCCallback, authIPLimitMw, c.OIDCCallback)
 
 		public.GET(g.RRegister, c.PageRegister)
-		public.POST(g.RRegister, c.PageRegister)
+		public.POST(g.RRegister, authIPLimitMw, authAccountLimitMw, c.PageRegister)
+
+		public.GET(g.RUnsubscribe, c.PageUnsubscribe)
+		public.POST(g.RUnsubscribe, c.Unsubscribe)
+
+		public.GET(g.RVerifyEmailToken, c.VerifyEmail)
+
+		// calendar apps fetch the feed without a session, the token in the path protects it
+		public.GET(g.RCalendarFeed, c.CalendarFeed)
+
+		public.GET(g.RForgotPassword, c.PageForgotPassword)
+		public.POST(g.RForgotPassword, authIPLimitMw, authAccountLimitMw, c.PageForgotPassword)
+		public.GET(g.RResetPassword, c.PageResetPassword)
+		public.POST(g.RResetPassword, c.PageResetPassword)
 	}
 
 	// protected endpoints
@@ -72,31 +172,99 @@ func InitRoutes(r *gin.Engine, c *controller.Controller) {
 	{
 		p.Use(i18nMw)
 		p.Use(authMw)
+		p.Use(protectedLimitMw)
 
 		p.GET("/", c.PageMain)
 		p.GET(g.RProfile, c.PageProfile)
+		p.POST(g.RHxProfileDetails, c.PostHxProfileDetails)
+		p.POST(g.RHxProfileAvatar, c.PostHxAvatar)
+		p.DELETE(g.RHxProfileAvatar, c.DeleteHxAvatar)
+		p.POST(g.RHxProfilePassword, c.PostHxChangePassword)
+		p.POST(g.RHxTwoFactor, c.PostHxTwoFactor)
+		p.POST(g.RHxTwoFactorConfirm, c.PostHxTwoFactorConfirm)
+		p.POST(g.RHxTwoFactorDisable, c.PostHxTwoFactorDisable)
+		p.POST(g.RHxAccessTokens, c.PostHxAccessToken)
+		p.DELETE(g.RHxAccessTokenID, c.DeleteHxAccessToken)
+		p.POST(g.RHxCalendarFeeds, c.PostHxCalendarFeed)
+		p.DELETE(g.RHxCalendarFeedID, c.DeleteHxCalendarFeed)
+		p.GET(g.RIdentityConnect, c.ConnectIdentity)
+		p.DELETE(g.RHxIdentity, c.DeleteHxIdentity)
+		p.GET(g.RProfileExport, c.ExportAccountData)
+		p.POST(g.RHxAccountDeletion, c.PostHxAccountDeletion)
+		p.DELETE(g.RHxAccountDeletion, c.DeleteHxAccountDeletion)
+		p.GET(g.RAccountDeletion, c.PageAccountDeletion)
+		p.POST(g.RAccountDeletion, c.PageAccountDeletion)
+		p.POST(g.RHxProfilePrivacy, c.PostHxPrivacy)
+		p.DELETE(g.RHxProfileBlockID, c.DeleteHxProfileBlock)
+		p.DELETE(g.RHxProfileSessions, c.DeleteHxSessions)
+		p.DELETE(g.RHxProfileSessionID, c.DeleteHxSession)
 		p.GET(g.RPayments, c.PagePayments)
+		p.GET(g.RHxPaymentsList, c.HxPaymentsList)
 		p.GET(g.RNotes, c.PageNotes)
 		p.GET(g.RMessaging, c.PageMessaging)
+		p.GET(g.RNotifications, c.PageNotifications)
+
+		p.GET(g.RUserID, c.PageUser)
+		p.POST(g.RHxUserBlock, c.PostHxUserBlock)
+		p.DELETE(g.RHxUserBlock, c.DeleteHxUserBlock)
+		p.GET(g.RImage, c.Image)
 
 		p.GET(g.RHouses, c.PageHouses)
+		p.GET(g.RHxHousesList, c.HxHousesList)
 		p.GET(g.RHouseID, c.PageHouse)
 
 		p.GET(g.RHxRoomateSearch, c.HxRoomateSearch)
 		p.POST(g.RHxRoomateSearch, c.HxRoomateSearch)
 
-		p.GET(g.RHxHouseForm, c.GetHxHouseModal)
-		p.POST(g.RHxHouseForm, c.PostHxHouseForm)
-		p.PUT(g.RHxHouseForm, c.PutHxHouseForm)
+		// adding roommates into a house is the closest thing to an invite
+		p.GET(g.RHxHouseForm, c.RequireVerifiedEmail, c.GetHxHouseModal)
+		p.POST(g.RHxHouseForm, c.RequireVerifiedEmail, c.PostHxHouseForm)
+		p.PUT(g.RHxHouseForm, c.RequireVerifiedEmail, c.PutHxHouseForm)
 		p.DELETE(g.RHxHouseForm, c.DeleteHouse)
+		p.POST(g.RHxHousePicture, c.PostHxHousePicture)
+		p.DELETE(g.RHxHousePicture, c.DeleteHxHousePicture)
+
+		// only for the maker of the house
+		p.GET(g.RHxHouseWebhooks, c.HxHouseWebhooks)
+		p.POST(g.RHxHouseWebhooks, c.RequireVerifiedEmail, c.PostHxHouseWebhook)
+		p.DELETE(g.RHxHouseWebhookID, c.DeleteHxHouseWebhook)
+		p.POST(g.RHxHouseWebhookEnable, c.PostHxHouseWebhookEnable)
+		p.GET(g.RHxHouseWebhookDeliveries, c.HxHouseWebhookDeliveries)
+
+		p.POST(g.RHxConversationImage, c.PostHxConversationImage)
+		p.DELETE(g.RHxConversationImage, c.DeleteHxConversationImage)
 
 		p.GET(g.RHxHouseResidentsBadge, c.HxHouseCardResidentsBadge)
-		p.GET(g.RHxNoteInHouseAccordion, c.HxNoteInHouseAccordion)
+		p.GET(g.RHxHouseActivity, c.HxHouseActivity)
+		p.GET(g.RHxHouseNotes, c.HxHouseNotes)
 
 		p.GET(g.RHxNoteForm, c.GetHxNoteModal)
 		p.POST(g.RHxNoteForm, c.PostHxNote)
 		p.PUT(g.RNoteID, c.PutHxNote)
 		p.DELETE(g.RNoteID, c.DeleteNote)
+
+		p.GET(g.RHxPaymentForm, c.RequireVerifiedEmail, c.GetHxPaymentModal)
+		p.POST(g.RHxPaymentForm, c.RequireVerifiedEmail, c.PostHxPayment)
+		p.POST(g.RHxPaymentSettle, c.SettlePayment)
+
+		p.POST(g.RHxReminderForm, c.PostHxReminder)
+		p.POST(g.RHxReminderComplete, c.CompleteReminder)
+
+		// the list is polled by every resident who has the house open
+		p.GET(g.RHxShopping, c.HxShoppingList)
+		p.POST(g.RHxShopping, c.PostHxShoppingItem)
+		p.DELETE(g.RHxShoppingItemID, c.DeleteHxShoppingItem)
+		p.POST(g.RHxShoppingItemBought, c.PostHxShoppingItemBought)
+		p.GET(g.RHxShoppingPurchase, c.GetHxShoppingPurchaseModal)
+		p.POST(g.RHxShoppingPurchase, c.PostHxShoppingPurchase)
+
+		p.GET(g.RHxNotificationsBell, c.HxNotificationBell)
+		p.POST(g.RHxNotificationsReadAll, c.ReadAllNotifications)
+		p.POST(g.RHxNotificationsPreferences, c.PostHxNotificationPreferences)
+		p.POST(g.RHxNotificationRead, c.ReadNotification)
+
+		p.GET(g.RHxVerifyEmailBanner, c.HxVerifyEmailBanner)
+		p.POST(g.RHxVerifyEmailResend, c.ResendVerificationEmail)
 	}
 
 	r.Static("/assets", "./assets/public")
@@ -0,0 +1,120 @@
+// time-based one-time passwords (RFC 6238) and recovery codes for two-factor authentication
+package twofactor
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"image/png"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	period = 30
+	// steps before and after the current one that are accepted, clocks drift
+	skew = 1
+
+	RecoveryCodeCount = 10
+)
+
+var validateOpts = totp.ValidateOpts{
+	Period:    period,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// new secret for the account, issuer and account are shown in the authenticator app
+func Generate(issuer, account string) (*otp.Key, error) {
+	return totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+		Period:      period,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+}
+
+// key of an already generated secret, used to show it again when enrollment failed
+func KeyFromSecret(issuer, account, secret string) (*otp.Key, error) {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("period", strconv.Itoa(period))
+	v.Set("digits", otp.DigitsSix.String())
+	v.Set("algorithm", otp.AlgorithmSHA1.String())
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+	return otp.NewKeyFromURL(u.String())
+}
+
+// QR code of the key as a data URL, meant for <img src>
+func QRCode(key *otp.Key) (string, error) {
+	img, err := key.Image(240, 240)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// checks the code against the secret
+//
+// returns the time step of the code, it has to be greater than the last used one
+// for the code to be accepted, otherwise the same code could be used twice
+func Validate(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != int(otp.DigitsSix) {
+		return 0, false
+	}
+
+	step := now.Unix() / period
+	for i := int64(-skew); i <= skew; i++ {
+		t := time.Unix((step+i)*period, 0)
+		expected, err := totp.GenerateCodeCustom(secret, t, validateOpts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step + i, true
+		}
+	}
+	return 0, false
+}
+
+// single-use codes for when the authenticator is not available
+//
+// only the hashes should be stored, the codes are shown to the user once
+func NewRecoveryCodes() (codes []string, hashes []string) {
+	for range RecoveryCodeCount {
+		// base32, 50 bits per code
+		b := strings.ToLower(rand.Text()[:10])
+		code := b[:5] + "-" + b[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes
+}
+
+// case and dashes are ignored, codes are typed by hand
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
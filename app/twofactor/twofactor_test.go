@@ -0,0 +1,153 @@
+package twofactor
+
+import (
+	"encoding/base32"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+// shared secret of the test vectors in RFC 6238 appendix B, SHA1 variant
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+// RFC 6238 appendix B, the codes there have 8 digits, ours are their last 6
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestValidateRFC6238(t *testing.T) {
+	for _, v := range rfcVectors {
+		now := time.Unix(v.unix, 0)
+		step, ok := Validate(rfcSecret, v.code, now)
+		if !ok {
+			t.Errorf("Validate(%s) at %d = false, want true", v.code, v.unix)
+			continue
+		}
+		if step != v.unix/period {
+			t.Errorf("Validate(%s) at %d step = %d, want %d", v.code, v.unix, step, v.unix/period)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// code of the step 1111111111 / 30 = 37037037
+	const code = "050471"
+	const step = 37037037
+	at := func(unix int64) time.Time { return time.Unix(unix, 0) }
+
+	tests := []struct {
+		name     string
+		code     string
+		now      time.Time
+		wantStep int64
+		wantOk   bool
+	}{
+		{name: "current step", code: code, now: at(step * period), wantStep: step, wantOk: true},
+		{name: "end of the step", code: code, now: at(step*period + 29), wantStep: step, wantOk: true},
+		{name: "clock one step ahead", code: code, now: at((step + 1) * period), wantStep: step, wantOk: true},
+		{name: "clock one step behind", code: code, now: at((step - 1) * period), wantStep: step, wantOk: true},
+		{name: "two steps late", code: code, now: at((step + 2) * period), wantOk: false},
+		{name: "two steps early", code: code, now: at((step - 2) * period), wantOk: false},
+		{name: "spaces around", code: " " + code + "\n", now: at(step * period), wantStep: step, wantOk: true},
+		{name: "wrong code", code: "050472", now: at(step * period), wantOk: false},
+		{name: "too short", code: "05047", now: at(step * period), wantOk: false},
+		{name: "8 digit code", code: "14050471", now: at(step * period), wantOk: false},
+		{name: "empty", code: "", now: at(step * period), wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := Validate(rfcSecret, tt.code, tt.now)
+			if ok != tt.wantOk || gotStep != tt.wantStep {
+				t.Errorf("Validate(%q) = %d, %v, want %d, %v", tt.code, gotStep, ok, tt.wantStep, tt.wantOk)
+			}
+		})
+	}
+
+	if _, ok := Validate("not base32!", code, at(step*period)); ok {
+		t.Error("Validate with a broken secret = true")
+	}
+}
+
+// the same code is accepted for 3 steps because of the skew, it always gives
+// the same step so that the last used step rejects it after the first time
+func TestValidateSameCodeSameStep(t *testing.T) {
+	const code = "050471"
+	var steps []int64
+	for _, unix := range []int64{1111111080, 1111111111, 1111111140} {
+		step, ok := Validate(rfcSecret, code, time.Unix(unix, 0))
+		if !ok {
+			t.Fatalf("Validate at %d = false", unix)
+		}
+		steps = append(steps, step)
+	}
+	if steps[0] != steps[1] || steps[1] != steps[2] {
+		t.Errorf("steps of the same code = %v, want the same step", steps)
+	}
+
+	// code of the step before, still valid but rejected after the newer one was used
+	older, ok := Validate(rfcSecret, "081804", time.Unix(1111111111, 0))
+	if !ok || older >= steps[0] {
+		t.Errorf("step of the older code = %d, %v, want less than %d", older, ok, steps[0])
+	}
+}
+
+func TestGenerateAndKeyFromSecret(t *testing.T) {
+	key, err := Generate("Roommates", "anna@roommates.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := KeyFromSecret("Roommates", "anna@roommates.test", key.Secret())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Secret() != key.Secret() || again.Issuer() != "Roommates" || again.AccountName() != "anna@roommates.test" {
+		t.Errorf("KeyFromSecret = %s, want the same key as %s", again, key)
+	}
+	if again.Period() != period || again.Digits().Length() != 6 {
+		t.Errorf("KeyFromSecret period %d digits %d, want %d and 6", again.Period(), again.Digits().Length(), period)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes := NewRecoveryCodes()
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), RecoveryCodeCount)
+	}
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q is not like xxxxx-xxxxx", code)
+		}
+		if hashes[i] != HashRecoveryCode(code) {
+			t.Errorf("hash of code %d does not match HashRecoveryCode", i)
+		}
+		if hashes[i] == code {
+			t.Errorf("code %d is stored as is", i)
+		}
+	}
+	if len(slices.Compact(slices.Sorted(slices.Values(codes)))) != len(codes) {
+		t.Errorf("codes repeat: %v", codes)
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	// sha256 of "abcdefghij"
+	const want = "72399361da6a7754fec986dca5b7cbaf1c810a28ded4abaf56b2106d06cb78b0"
+	for _, code := range []string{"abcde-fghij", "ABCDE-FGHIJ", "abcdefghij", " abcde fghij ", "ab-cde-fg-hij"} {
+		if got := HashRecoveryCode(code); got != want {
+			t.Errorf("HashRecoveryCode(%q) = %s, want %s", code, got, want)
+		}
+	}
+	if HashRecoveryCode("abcde-fghik") == want {
+		t.Error("another code has the same hash")
+	}
+}
@@ -0,0 +1,79 @@
+package webhooks
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"roommates/utils"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrorInsecureURL    = errors.New("webhook url has to use https")
+	ErrorPrivateAddress = errors.New("webhook url points to a private address")
+)
+
+// shared address space of carrier-grade NAT and the "this network" block, not covered by netip
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// plain http urls are accepted only when WEBHOOKS_ALLOW_HTTP is true, meant for development
+func allowHTTP() bool {
+	allow, _ := strconv.ParseBool(utils.GetEnv("WEBHOOKS_ALLOW_HTTP", "false"))
+	return allow
+}
+
+// checks the url a webhook is registered with
+//
+// host names are not resolved here, what they resolve to can change later,
+// the addresses are checked again when a delivery connects
+func CheckURL(u *url.URL) error {
+	if u.Scheme != "https" && !(u.Scheme == "http" && allowHTTP()) {
+		return ErrorInsecureURL
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrorPrivateAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublic(addr) {
+		return ErrorPrivateAddress
+	}
+	return nil
+}
+
+// loopback, private, link-local, unspecified and multicast addresses are not public
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// net.Dialer.Control of the deliveries, runs after DNS resolution for every address dialed
+func dialPublicOnly(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublic(addr) {
+		return ErrorPrivateAddress
+	}
+	return nil
+}
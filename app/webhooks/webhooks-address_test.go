@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestCheckURL(t *testing.T) {
+	for rawURL, want := range map[string]error{
+		"https://hooks.example.com/roommates":  nil,
+		"https://93.184.215.14/hook":           nil,
+		"https://[2606:4700::1111]/hook":       nil,
+		"http://hooks.example.com/roommates":   ErrorInsecureURL,
+		"ftp://hooks.example.com/roommates":    ErrorInsecureURL,
+		"https://localhost/hook":               ErrorPrivateAddress,
+		"https://api.localhost./hook":          ErrorPrivateAddress,
+		"https://127.0.0.1:8080/hook":          ErrorPrivateAddress,
+		"https://10.1.2.3/hook":                ErrorPrivateAddress,
+		"https://192.168.0.10/hook":            ErrorPrivateAddress,
+		"https://169.254.169.254/latest":       ErrorPrivateAddress,
+		"https://0.0.0.0/hook":                 ErrorPrivateAddress,
+		"https://100.64.0.1/hook":              ErrorPrivateAddress,
+		"https://[::1]/hook":                   ErrorPrivateAddress,
+		"https://[fe80::1]/hook":               ErrorPrivateAddress,
+		"https://[fd00::1]/hook":               ErrorPrivateAddress,
+		"https://[::ffff:127.0.0.1]/hook":      ErrorPrivateAddress,
+		"https://[::ffff:169.254.169.254]/xyz": ErrorPrivateAddress,
+	} {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = CheckURL(u); err != want {
+			t.Errorf("CheckURL(%s) = %v, want %v", rawURL, err, want)
+		}
+	}
+}
+
+func TestCheckURLAllowHTTP(t *testing.T) {
+	t.Setenv("WEBHOOKS_ALLOW_HTTP", "true")
+	u, _ := url.Parse("http://hooks.example.com/roommates")
+	if err := CheckURL(u); err != nil {
+		t.Errorf("CheckURL = %v with WEBHOOKS_ALLOW_HTTP", err)
+	}
+	// the setting is for http only
+	u, _ = url.Parse("http://127.0.0.1/hook")
+	if err := CheckURL(u); err != ErrorPrivateAddress {
+		t.Errorf("CheckURL = %v, want ErrorPrivateAddress", err)
+	}
+}
+
+// names are resolved when connecting, the dialer sees the address
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the private address")
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	for _, target := range []string{server.URL, "http://localhost:" + serverURL.Port()} {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, target, nil)
+		_, err := newClient(dialPublicOnly).Do(req)
+		if !errors.Is(err, ErrorPrivateAddress) {
+			t.Errorf("POST %s = %v, want ErrorPrivateAddress", target, err)
+		}
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"1.1.1.1":     true,
+		"100.63.0.1":  true,
+		"100.128.0.1": true,
+		"172.15.0.1":  true,
+		"172.16.0.1":  false,
+		"224.0.0.1":   false,
+		"ff02::1":     false,
+	} {
+		if got := isPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublic(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
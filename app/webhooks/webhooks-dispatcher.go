@@ -0,0 +1,211 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"roommates/db/dbqueries"
+	"roommates/events"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// longest error stored in the delivery log
+const maxErrorLength = 255
+
+// delivers events to the webhooks subscribed to them
+type Dispatcher struct {
+	db     *dbqueries.Queries
+	client *http.Client
+	// checks the url before every delivery, CheckURL
+	checkURL func(u *url.URL) error
+
+	// attempts per event before the delivery counts as failed
+	MaxAttempts int
+	// wait before the first retry, doubled on every following retry
+	Backoff time.Duration
+	// failed deliveries in a row after which the webhook is disabled
+	DisableAfter int32
+	// deliveries kept in the log per webhook
+	KeepDeliveries int32
+}
+
+func NewDispatcher(db *dbqueries.Queries) *Dispatcher {
+	return &Dispatcher{
+		db:             db,
+		client:         newClient(dialPublicOnly),
+		checkURL:       CheckURL,
+		MaxAttempts:    4,
+		Backoff:        time.Second,
+		DisableAfter:   10,
+		KeepDeliveries: 50,
+	}
+}
+
+// client of the deliveries
+//
+//	control -- net.Dialer.Control, checks the addresses connected to
+func newClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: control,
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			// no proxy, the dialer has to see the address of the receiver
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// a redirect is answered like any other non 2xx response
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// posts the event to every enabled webhook of the house subscribed to it
+//
+// failed deliveries are retried here with their own backoff, so a failing webhook
+// does not get the event sent again to the others. only an error reading the webhooks is returned
+func (d *Dispatcher) Deliver(ctx context.Context, message events.Message) error {
+	hooks, err := d.db.SelectEventWebhooks(ctx, dbqueries.SelectEventWebhooksParams{
+		HouseID:   message.HouseID,
+		EventType: string(message.Type),
+	})
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, hook := range hooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, hook, message, body)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, hook dbqueries.SelectEventWebhooksRow, message events.Message, body []byte) {
+	backoff := d.Backoff
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		start := time.Now()
+		status, err := d.post(ctx, hook, message, body)
+		d.record(ctx, hook, message, attempt, status, err, time.Since(start))
+
+		if err == nil {
+			if err = d.db.ResetWebhookFailures(ctx, hook.ID); err != nil {
+				log.Error().Err(err).Str("webhook_id", hook.ID.String()).Msg("error resetting webhook failures")
+			}
+			return
+		}
+		if attempt == d.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			// shutting down, not the fault of the receiver
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	disabled, err := d.db.IncrementWebhookFailures(ctx, dbqueries.IncrementWebhookFailuresParams{
+		DisableAfter: d.DisableAfter,
+		ID:           hook.ID,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("webhook_id", hook.ID.String()).Msg("error counting webhook failure")
+		return
+	}
+	if disabled {
+		log.Warn().Str("webhook_id", hook.ID.String()).Str("url", hook.Url).Msg("webhook disabled after repeated failures")
+	}
+}
+
+// sends the signed request
+//
+//	int == 0 -- there was no response
+func (d *Dispatcher) post(ctx context.Context, hook dbqueries.SelectEventWebhooksRow, message events.Message, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	// urls registered before the checks, or http urls after WEBHOOKS_ALLOW_HTTP was turned off
+	if err = d.checkURL(req.URL); err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Roommates-Webhooks")
+	req.Header.Set(HEvent, string(message.Type))
+	req.Header.Set(HEventID, strconv.FormatInt(message.ID, 10))
+	req.Header.Set(HTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HSignature, Sign(hook.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// read a bit of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// stores the attempt into the delivery log and drops the oldest entries
+func (d *Dispatcher) record(ctx context.Context, hook dbqueries.SelectEventWebhooksRow, message events.Message, attempt, status int, deliveryErr error, duration time.Duration) {
+	params := dbqueries.InsertWebhookDeliveryParams{
+		WebhookID:  hook.ID,
+		EventID:    message.ID,
+		EventType:  string(message.Type),
+		Attempt:    int32(attempt),
+		DurationMs: int32(duration.Milliseconds()),
+	}
+	if status != 0 {
+		code := int32(status)
+		params.StatusCode = &code
+	}
+	if deliveryErr != nil {
+		params.Error = deliveryErr.Error()
+		if len(params.Error) > maxErrorLength {
+			params.Error = params.Error[:maxErrorLength]
+		}
+	}
+
+	if err := d.db.InsertWebhookDelivery(ctx, params); err != nil {
+		log.Error().Err(err).Str("webhook_id", hook.ID.String()).Msg("error logging webhook delivery")
+		return
+	}
+	err := d.db.DeleteOldWebhookDeliveries(ctx, dbqueries.DeleteOldWebhookDeliveriesParams{
+		WebhookID: hook.ID,
+		Keep:      d.KeepDeliveries,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("webhook_id", hook.ID.String()).Msg("error dropping old webhook deliveries")
+	}
+}
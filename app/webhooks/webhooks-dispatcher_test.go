@@ -0,0 +1,244 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"roommates/db/dbqueries"
+	"roommates/db/dbtest"
+	"roommates/events"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// request the receiver got
+type received struct {
+	at     time.Time
+	header http.Header
+	body   []byte
+}
+
+// receiver answering with the statuses in order, the last one is repeated
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []received
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		status := r.statuses[min(len(r.requests), len(r.statuses)-1)]
+		r.requests = append(r.requests, received{at: time.Now(), header: req.Header, body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []received {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]received(nil), r.requests...)
+}
+
+type testHook struct {
+	id      pgtype.UUID
+	houseID pgtype.UUID
+	secret  string
+}
+
+// dispatcher delivering to the receiver, which listens on loopback over http
+func newTestDispatcher(t *testing.T, r *receiver) (*Dispatcher, testHook) {
+	t.Helper()
+	ctx := context.Background()
+	db := dbqueries.New(dbtest.Pool(t))
+
+	userID, err := db.InsertUser(ctx, dbqueries.InsertUserParams{Email: "maker@roommates.test", Username: "maker", Password: "not-a-hash"})
+	if err != nil {
+		t.Fatalf("could not insert user: %v", err)
+	}
+	houseID, err := db.InsertHouse(ctx, dbqueries.InsertHouseParams{Name: "house", MakerID: userID})
+	if err != nil {
+		t.Fatalf("could not insert house: %v", err)
+	}
+	hook := testHook{houseID: houseID, secret: NewSecret()}
+	hook.id, err = db.InsertHouseWebhook(ctx, dbqueries.InsertHouseWebhookParams{
+		HouseID:    houseID,
+		Url:        r.URL + "/hook",
+		Secret:     hook.secret,
+		EventTypes: []string{string(events.NoteCreated)},
+	})
+	if err != nil {
+		t.Fatalf("could not insert webhook: %v", err)
+	}
+
+	d := NewDispatcher(db)
+	d.client = newClient(nil)
+	d.checkURL = func(u *url.URL) error { return nil }
+	d.Backoff = time.Millisecond
+	return d, hook
+}
+
+func deliver(t *testing.T, d *Dispatcher, hook testHook, id int64) {
+	t.Helper()
+	err := d.Deliver(context.Background(), events.Message{ID: id, Type: events.NoteCreated, HouseID: hook.houseID})
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+}
+
+func deliveries(t *testing.T, d *Dispatcher, hook testHook) []dbqueries.SelectWebhookDeliveriesRow {
+	t.Helper()
+	rows, err := d.db.SelectWebhookDeliveries(context.Background(), dbqueries.SelectWebhookDeliveriesParams{
+		WebhookID: hook.id,
+		HouseID:   hook.houseID,
+		Limit:     100,
+	})
+	if err != nil {
+		t.Fatalf("could not get deliveries: %v", err)
+	}
+	return rows
+}
+
+func webhookState(t *testing.T, d *Dispatcher, hook testHook) dbqueries.SelectHouseWebhooksRow {
+	t.Helper()
+	hooks, err := d.db.SelectHouseWebhooks(context.Background(), hook.houseID)
+	if err != nil || len(hooks) != 1 {
+		t.Fatalf("could not get webhook: %v", err)
+	}
+	return hooks[0]
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	r := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK)
+	d, hook := newTestDispatcher(t, r)
+	d.Backoff = 20 * time.Millisecond
+	deliver(t, d, hook, 42)
+
+	requests := r.received()
+	if len(requests) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(requests))
+	}
+	if gap := requests[1].at.Sub(requests[0].at); gap < d.Backoff {
+		t.Errorf("first retry after %v, want at least %v", gap, d.Backoff)
+	}
+	if gap := requests[2].at.Sub(requests[1].at); gap < 2*d.Backoff {
+		t.Errorf("second retry after %v, want at least %v", gap, 2*d.Backoff)
+	}
+	for i, req := range requests {
+		if req.header.Get(HEventID) != "42" || req.header.Get(HEvent) != string(events.NoteCreated) {
+			t.Errorf("request %d has event %q %q", i, req.header.Get(HEvent), req.header.Get(HEventID))
+		}
+		timestamp, _ := strconv.ParseInt(req.header.Get(HTimestamp), 10, 64)
+		if !Verify(hook.secret, timestamp, req.body, req.header.Get(HSignature)) {
+			t.Errorf("request %d has an invalid signature", i)
+		}
+	}
+
+	// newest first
+	rows := deliveries(t, d, hook)
+	if len(rows) != 3 {
+		t.Fatalf("%d deliveries logged, want 3", len(rows))
+	}
+	for i, want := range []int32{http.StatusOK, http.StatusServiceUnavailable, http.StatusInternalServerError} {
+		row := rows[i]
+		if row.Attempt != int32(3-i) || row.EventID != 42 || row.StatusCode == nil || *row.StatusCode != want {
+			t.Errorf("delivery %d = attempt %d, event %d, status %v, want attempt %d, status %d", i, row.Attempt, row.EventID, row.StatusCode, 3-i, want)
+		}
+		if failed := want != http.StatusOK; failed != (row.Error != "") {
+			t.Errorf("delivery %d has error %q", i, row.Error)
+		}
+	}
+	if state := webhookState(t, d, hook); state.FailureCount != 0 {
+		t.Errorf("failure count = %d after a successful retry, want 0", state.FailureCount)
+	}
+}
+
+func TestDispatcherDisablesAfterFailures(t *testing.T) {
+	r := newReceiver(t, http.StatusInternalServerError)
+	d, hook := newTestDispatcher(t, r)
+	d.MaxAttempts = 2
+	d.DisableAfter = 3
+
+	for id := range int64(2) {
+		deliver(t, d, hook, id)
+	}
+	state := webhookState(t, d, hook)
+	if state.FailureCount != 2 || state.DisabledAt.Valid {
+		t.Fatalf("after 2 failed events: failures %d, disabled %v", state.FailureCount, state.DisabledAt.Valid)
+	}
+
+	deliver(t, d, hook, 3)
+	state = webhookState(t, d, hook)
+	if state.FailureCount != 3 || !state.DisabledAt.Valid {
+		t.Fatalf("after 3 failed events: failures %d, disabled %v", state.FailureCount, state.DisabledAt.Valid)
+	}
+	if requests := len(r.received()); requests != 6 {
+		t.Errorf("receiver got %d requests, want 6", requests)
+	}
+
+	// disabled webhooks get no events
+	deliver(t, d, hook, 4)
+	if requests := len(r.received()); requests != 6 {
+		t.Errorf("disabled webhook got %d more requests", requests-6)
+	}
+}
+
+func TestDispatcherSuccessResetsFailures(t *testing.T) {
+	r := newReceiver(t, http.StatusBadGateway, http.StatusNoContent)
+	d, hook := newTestDispatcher(t, r)
+	d.MaxAttempts = 1
+
+	deliver(t, d, hook, 1)
+	if state := webhookState(t, d, hook); state.FailureCount != 1 {
+		t.Fatalf("failure count = %d, want 1", state.FailureCount)
+	}
+	deliver(t, d, hook, 2)
+	if state := webhookState(t, d, hook); state.FailureCount != 0 {
+		t.Errorf("failure count = %d after a delivery, want 0", state.FailureCount)
+	}
+}
+
+func TestDispatcherDropsOldDeliveries(t *testing.T) {
+	r := newReceiver(t, http.StatusOK)
+	d, hook := newTestDispatcher(t, r)
+	d.KeepDeliveries = 2
+
+	for id := range int64(4) {
+		deliver(t, d, hook, id)
+	}
+	rows := deliveries(t, d, hook)
+	if len(rows) != 2 || rows[0].EventID != 3 || rows[1].EventID != 2 {
+		t.Errorf("kept deliveries %v, want the events 3 and 2", rows)
+	}
+}
+
+// the dispatcher of NewDispatcher does not reach the receiver on loopback
+func TestDispatcherRefusesPrivateReceiver(t *testing.T) {
+	r := newReceiver(t, http.StatusOK)
+	d, hook := newTestDispatcher(t, r)
+	d.client = newClient(dialPublicOnly)
+	d.checkURL = CheckURL
+	d.MaxAttempts = 1
+	deliver(t, d, hook, 1)
+
+	if requests := len(r.received()); requests != 0 {
+		t.Errorf("receiver got %d requests", requests)
+	}
+	rows := deliveries(t, d, hook)
+	if len(rows) != 1 || rows[0].StatusCode != nil || rows[0].Error == "" {
+		t.Errorf("deliveries = %+v, want one without a response", rows)
+	}
+}
@@ -0,0 +1,58 @@
+// outbound webhooks, house events are posted as JSON to the urls house makers register
+//
+// every request is signed with the secret of the webhook, receivers can check
+// the signature to know the request came from us and was not changed
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"roommates/events"
+	"roommates/logger"
+	"slices"
+	"strconv"
+)
+
+var log = logger.WebhooksLoggger
+
+// tells webhook secrets apart from other secrets
+const SecretPrefix = "whsec_"
+
+// headers of the delivery requests
+const (
+	// type of the event, "payment-created"
+	HEvent = "X-Roommates-Event"
+	// id of the event, retries of the same event have the same id
+	HEventID = "X-Roommates-Event-Id"
+	// unix time in seconds of when the request was signed, old requests can be rejected with it
+	HTimestamp = "X-Roommates-Timestamp"
+	// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>"
+	HSignature = "X-Roommates-Signature"
+)
+
+// event types a webhook can subscribe to, in the order they are shown
+var EventTypes = events.AllTypes
+
+func IsEventType(s string) bool {
+	return slices.Contains(EventTypes, events.Type(s))
+}
+
+// signing secret of a new webhook
+func NewSecret() string {
+	return SecretPrefix + rand.Text()
+}
+
+// value of the HSignature header
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// checks the value of the HSignature header, meant for receivers written in Go
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,35 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := NewSecret()
+	body := []byte(`{"id":1,"type":"note-created"}`)
+	signature := Sign(secret, 1700000000, body)
+
+	// the format described at HSignature, receivers in other languages compute it themselves
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("1700000000." + string(body)))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+
+	if !Verify(secret, 1700000000, body, signature) {
+		t.Error("signature does not verify")
+	}
+	for name, ok := range map[string]bool{
+		"other body":      Verify(secret, 1700000000, []byte(`{"id":2,"type":"note-created"}`), signature),
+		"other timestamp": Verify(secret, 1700000001, body, signature),
+		"other secret":    Verify(NewSecret(), 1700000000, body, signature),
+		"no signature":    Verify(secret, 1700000000, body, ""),
+	} {
+		if ok {
+			t.Errorf("signature verifies with %s", name)
+		}
+	}
+}